@@ -2,8 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
 
-	"github.com/minamijoyo/tfedit/filter"
+	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/filter/awsv4upgrade"
+	"github.com/minamijoyo/tfedit/migration"
+	"github.com/minamijoyo/tfedit/tfeditor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,8 +20,10 @@ func init() {
 	flags := filterCmd.PersistentFlags()
 	flags.StringP("file", "f", "-", "A path to input Terraform configuration file")
 	flags.BoolP("update", "u", false, "Update files in-place")
+	flags.Bool("backup", false, "Keep a copy of original files with a "+tfeditor.BackupSuffix+" suffix when updating files in-place, which can be rolled back with tfedit restore")
 	_ = viper.BindPFlag("filter.file", flags.Lookup("file"))
 	_ = viper.BindPFlag("filter.update", flags.Lookup("update"))
+	_ = viper.BindPFlag("filter.backup", flags.Lookup("backup"))
 
 	RootCmd.AddCommand(filterCmd)
 }
@@ -37,30 +46,202 @@ func newFilterCmd() *cobra.Command {
 
 func newFilterAwsv4upgradeCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "awsv4upgrade",
+		Use:   "awsv4upgrade [PATH...]",
 		Short: "Apply a built-in filter for awsv4upgrade",
 		Long: `Apply a built-in filter for awsv4upgrade
 
 Upgrade configurations to AWS provider v4.
+If paths to files or directories are given as arguments, all Terraform
+configuration files (*.tf) in them are updated in-place with -u. The updates
+are atomic across files: nothing is written unless all files are filtered
+successfully, and the changed files are written to temporary files first and
+renamed all together. With --backup, the original files are kept, which can
+be rolled back with tfedit restore. The --backup flag requires -u.
+With --migration-out, also generate a migration file in tfmigrate HCL format
+which imports the split resources. It's written in the same batch as the
+configuration files, so it's also recorded in the backup and removed by
+tfedit restore. Import IDs are derived from literal values,
+so that we don't need to run terraform plan in advance. Resources whose import
+ID cannot be derived statically are reported as warnings, which needs a plan
+and tfedit migration fromplan instead.
+The owner of aws_s3_bucket_acl split from grant arguments cannot be known
+statically, so a placeholder is set by default. With --canonical-user-id, a
+given literal ID is set instead. With --canonical-user-id-data-source, it
+refers to an aws_canonical_user_id data source, which is inserted once per
+module (directory) unless an existing one is found.
+With --ownership-controls, also generate aws_s3_bucket_ownership_controls for
+each aws_s3_bucket_acl, which is required for buckets created after April 2023
+because ACLs are disabled by default.
+If a split resource for a bucket already exists, such as a partially upgraded
+module, the arguments are left in aws_s3_bucket and reported as warnings.
+Split resources are named with --split-name-template, which is a Go template
+with the fields .Name (the name of aws_s3_bucket), .Type (the type of the split
+resource) and .Suffix (the type without the aws_s3_bucket_ prefix). If the
+name is already used by another resource of the same type in the module, a
+numbered suffix such as _2 is appended.
 `,
 		RunE: runFilterAwsv4upgradeCmd,
 	}
 
+	flags := cmd.Flags()
+	flags.String("migration-out", "", "Write a migration file which imports split resources to a given path")
+	flags.String("migration-dir", "", "Set a dir attribute in a migration file")
+	_ = viper.BindPFlag("filter.awsv4upgrade.migration-out", flags.Lookup("migration-out"))
+	flags.String("canonical-user-id", "", "Set a given canonical user ID to the owner of aws_s3_bucket_acl")
+	flags.Bool("canonical-user-id-data-source", false, "Set a reference to an aws_canonical_user_id data source to the owner of aws_s3_bucket_acl")
+	flags.Bool("ownership-controls", false, "Generate aws_s3_bucket_ownership_controls which aws_s3_bucket_acl depends on")
+	flags.String("split-name-template", awsv4upgrade.DefaultSplitNameTemplate, "A naming template for split resources (e.g. {{.Name}}_{{.Suffix}})")
+	_ = viper.BindPFlag("filter.awsv4upgrade.migration-dir", flags.Lookup("migration-dir"))
+	_ = viper.BindPFlag("filter.awsv4upgrade.canonical-user-id", flags.Lookup("canonical-user-id"))
+	_ = viper.BindPFlag("filter.awsv4upgrade.canonical-user-id-data-source", flags.Lookup("canonical-user-id-data-source"))
+	_ = viper.BindPFlag("filter.awsv4upgrade.ownership-controls", flags.Lookup("ownership-controls"))
+	_ = viper.BindPFlag("filter.awsv4upgrade.split-name-template", flags.Lookup("split-name-template"))
+
 	return cmd
 }
 
 func runFilterAwsv4upgradeCmd(cmd *cobra.Command, args []string) error {
-	if len(args) != 0 {
-		return fmt.Errorf("expected 0 argument, but got %d arguments", len(args))
-	}
-
 	file := viper.GetString("filter.file")
 	update := viper.GetBool("filter.update")
-	filter, err := filter.NewFilterByType("awsv4upgrade")
+	migrationFile := viper.GetString("filter.awsv4upgrade.migration-out")
+	migrationDir := viper.GetString("filter.awsv4upgrade.migration-dir")
+	canonicalUserID := viper.GetString("filter.awsv4upgrade.canonical-user-id")
+	canonicalUserIDDataSource := viper.GetBool("filter.awsv4upgrade.canonical-user-id-data-source")
+	ownershipControls := viper.GetBool("filter.awsv4upgrade.ownership-controls")
+	splitNameTemplate := viper.GetString("filter.awsv4upgrade.split-name-template")
+
+	if viper.GetBool("filter.backup") && !update {
+		return fmt.Errorf("the --backup flag can only be used with the --update flag")
+	}
+
+	if canonicalUserID != "" && canonicalUserIDDataSource {
+		return fmt.Errorf("the --canonical-user-id flag cannot be used with the --canonical-user-id-data-source flag")
+	}
+
+	tmpl, err := awsv4upgrade.ParseSplitNameTemplate(splitNameTemplate)
 	if err != nil {
 		return err
 	}
 
-	c := newDefaultClient(cmd)
-	return c.Edit(file, update, filter)
+	var files []string
+	if len(args) > 0 {
+		if cmd.Flags().Changed("file") {
+			return fmt.Errorf("the --file flag cannot be used with arguments")
+		}
+		if !update {
+			return fmt.Errorf("arguments can only be used with the --update flag")
+		}
+
+		var err error
+		files, err = findConfigFiles(args)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Buffer all writes in a run, so that the filtered files and the migration
+	// file are written in the same batch, which is atomic and recorded in the
+	// backup manifest together.
+	fs := tfeditor.NewBufferedFileSystem(newFilterFileSystem())
+	c := newDefaultClient(cmd, fs)
+	edit := func(filter editor.Filter) error {
+		if files != nil {
+			return c.EditFiles(files, filter)
+		}
+		return c.Edit(file, update, filter)
+	}
+
+	// The recorder also records warnings for arguments which cannot be split,
+	// so we always use it even if a migration file is not needed.
+	recorder := awsv4upgrade.NewSplitRecorder()
+	o := &awsv4upgrade.Option{
+		Recorder:                  recorder,
+		CanonicalUserID:           canonicalUserID,
+		CanonicalUserIDDataSource: canonicalUserIDDataSource,
+		OwnershipControls:         ownershipControls,
+		SplitNameTemplate:         tmpl,
+	}
+
+	if err := edit(awsv4upgrade.NewAllFilterWithOption(o)); err != nil {
+		return err
+	}
+
+	if migrationFile == "" {
+		for _, w := range recorder.Warnings() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+		}
+		return fs.Flush()
+	}
+
+	m := recorder.Migration(migration.NewDefaultDictionary(), migrationDir)
+	for _, w := range m.Warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+	}
+
+	output, err := m.Render()
+	if err != nil {
+		return err
+	}
+
+	// Suppress creating a migration file when no action.
+	if len(output) != 0 {
+		// In general, a migration file is expected to commit to git and it does
+		// not contain any credentials, so it's written with the default
+		// permissions 0644.
+		if err := fs.WriteFile(migrationFile, output); err != nil {
+			return err
+		}
+	}
+
+	return fs.Flush()
+}
+
+// newFilterFileSystem returns a new FileSystem for filter commands.
+// If the --backup flag is set, the original files are kept when updating
+// files in-place.
+func newFilterFileSystem() tfeditor.FileSystem {
+	if viper.GetBool("filter.backup") {
+		return tfeditor.NewOSFileSystemWithBackup(tfeditor.DefaultBackupManifest)
+	}
+	return tfeditor.NewOSFileSystem()
+}
+
+// findConfigFiles returns a sorted list of Terraform configuration files
+// (*.tf) in given paths. If a path is a directory, it's searched recursively
+// except for hidden directories such as .terraform.
+func findConfigFiles(paths []string) ([]string, error) {
+	seen := map[string]bool{}
+	files := []string{}
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if path != p && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// A file given explicitly is always included.
+			if path != p && filepath.Ext(path) != ".tf" {
+				return nil
+			}
+
+			if !seen[path] {
+				files = append(files, path)
+				seen[path] = true
+			}
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to find files: %s", err)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
 }
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 
 	"github.com/minamijoyo/tfedit/migration"
+	"github.com/minamijoyo/tfedit/migration/schema"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -25,6 +32,8 @@ func newMigrationCmd() *cobra.Command {
 
 	cmd.AddCommand(
 		newMigrationFromplanCmd(),
+		newMigrationFromconfigCmd(),
+		newMigrationVerifyCmd(),
 	)
 
 	return cmd
@@ -38,7 +47,13 @@ func newMigrationFromplanCmd() *cobra.Command {
 
 Read a Terraform plan file in JSON format and
 generate a migration file in tfmigrate HCL format.
-Currently, only import actions required by awsv4upgrade are supported.
+With --mode=config, generate Terraform import, moved and removed
+blocks instead, which can be applied by terraform apply without tfmigrate.
+Currently, only import actions are supported. Import IDs are built-in
+for the split resources of awsv4upgrade and some resource types of the AWS,
+Google and AzureRM providers, and can be extended with --schema.
+In config mode, the blocks are appended to an existing file, and blocks
+whose address already exists in the file are skipped.
 `,
 		RunE: runMigrationFromplanCmd,
 	}
@@ -47,9 +62,17 @@ Currently, only import actions required by awsv4upgrade are supported.
 	flags.StringP("file", "f", "-", "A path to input Terraform JSON plan file")
 	flags.StringP("out", "o", "-", "Write a migration file to a given path")
 	flags.StringP("dir", "d", "", "Set a dir attribute in a migration file")
+	flags.String("schema", "", "A path to a schema file which defines import IDs for additional resource types")
+	flags.String("sensitive", "error", "How to handle import IDs derived from sensitive values: error, redact or warn")
+	flags.String("mode", "migration", "Output format: migration (tfmigrate) or config (import/moved/removed blocks written to migrations.tf by default)")
+	flags.Bool("group-comments", false, "Add comments which group actions by the original resource such as aws_s3_bucket")
 	_ = viper.BindPFlag("migration.fromplan.file", flags.Lookup("file"))
 	_ = viper.BindPFlag("migration.fromplan.out", flags.Lookup("out"))
 	_ = viper.BindPFlag("migration.fromplan.dir", flags.Lookup("dir"))
+	_ = viper.BindPFlag("migration.fromplan.schema", flags.Lookup("schema"))
+	_ = viper.BindPFlag("migration.fromplan.sensitive", flags.Lookup("sensitive"))
+	_ = viper.BindPFlag("migration.fromplan.mode", flags.Lookup("mode"))
+	_ = viper.BindPFlag("migration.fromplan.group-comments", flags.Lookup("group-comments"))
 
 	return cmd
 }
@@ -62,9 +85,21 @@ func runMigrationFromplanCmd(cmd *cobra.Command, args []string) error {
 	planFile := viper.GetString("migration.fromplan.file")
 	migrationFile := viper.GetString("migration.fromplan.out")
 	migrationDir := viper.GetString("migration.fromplan.dir")
+	schemaFile := viper.GetString("migration.fromplan.schema")
+	sensitive, err := migration.NewSensitiveMode(viper.GetString("migration.fromplan.sensitive"))
+	if err != nil {
+		return err
+	}
+	mode := migration.GenerateMode(viper.GetString("migration.fromplan.mode"))
+	groupComments := viper.GetBool("migration.fromplan.group-comments")
+
+	// In config mode, write blocks to migrations.tf in the working directory
+	// unless an output path is given explicitly.
+	if mode == migration.GenerateModeConfig && !cmd.Flags().Changed("out") {
+		migrationFile = filepath.Join(migrationDir, defaultMigrationConfigFile)
+	}
 
 	var planJSON []byte
-	var err error
 	if planFile == "-" {
 		planJSON, err = io.ReadAll(cmd.InOrStdin())
 		if err != nil {
@@ -77,7 +112,19 @@ func runMigrationFromplanCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	output, err := migration.GenerateFromPlan(planJSON, migrationDir)
+	dictionary, err := newMigrationDictionary(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	o := &migration.GenerateOption{
+		Dir:           migrationDir,
+		Dictionary:    dictionary,
+		Sensitive:     sensitive,
+		Mode:          mode,
+		GroupComments: groupComments,
+	}
+	output, err := migration.GenerateFromPlanWithOption(planJSON, o)
 	if err != nil {
 		return err
 	}
@@ -91,6 +138,232 @@ func runMigrationFromplanCmd(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if migrationFile == "-" {
+		fmt.Fprint(cmd.OutOrStdout(), string(output))
+	} else {
+		if mode == migration.GenerateModeConfig {
+			output, err = appendConfigFile(migrationFile, output)
+			if err != nil {
+				return err
+			}
+			// All blocks already exist in the file, such as running it twice.
+			if output == nil {
+				return nil
+			}
+		}
+
+		// nolint: gosec
+		// G306: Expect WriteFile permissions to be 0600 or less
+		// In general, a migration file is expected to commit to git and it does
+		// not contain any credentials unless --sensitive=warn, so there is no problem.
+		if err := os.WriteFile(migrationFile, output, 0644); err != nil {
+			return fmt.Errorf("failed to write file: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// defaultMigrationConfigFile is a default file name for writing configuration
+// blocks in config mode.
+const defaultMigrationConfigFile = "migrations.tf"
+
+// appendConfigFile returns bytes of a given configuration file with given
+// blocks appended. If the file doesn't exist, it returns the blocks as is.
+// Blocks whose address already exists in the file, such as ones generated by
+// a previous run, are skipped. It returns nil if there is nothing to append.
+func appendConfigFile(filename string, blocks []byte) ([]byte, error) {
+	current, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return blocks, nil
+		}
+		return nil, fmt.Errorf("failed to read file: %s", err)
+	}
+
+	currentFile, diags := hclwrite.ParseConfig(current, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse file: %s", diags)
+	}
+
+	newFile, diags := hclwrite.ParseConfig(blocks, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse generated blocks: %s", diags)
+	}
+
+	existing := map[string]bool{}
+	for _, b := range currentFile.Body().Blocks() {
+		if key := configBlockKey(b); key != "" {
+			existing[key] = true
+		}
+	}
+
+	newBlocks := newFile.Body().Blocks()
+	appended := [][]byte{}
+	for _, b := range newBlocks {
+		if existing[configBlockKey(b)] {
+			continue
+		}
+		// The tokens of a block contain comments written above it.
+		appended = append(appended, b.BuildTokens(nil).Bytes())
+	}
+	if len(appended) == 0 {
+		return nil, nil
+	}
+
+	// Warnings are written as comments before the first block.
+	var header []byte
+	tokens := newFile.Body().BuildTokens(nil)
+	first := newBlocks[0].BuildTokens(nil)[0]
+	for _, t := range tokens {
+		if t == first {
+			break
+		}
+		header = append(header, t.Bytes...)
+	}
+
+	merged := append(bytes.TrimRight(current, "\n"), []byte("\n\n")...)
+	merged = append(merged, header...)
+	merged = append(merged, bytes.Join(appended, []byte("\n"))...)
+	return hclwrite.Format(merged), nil
+}
+
+// configBlockKey returns a key for detecting a duplicate block of import,
+// moved and removed. It returns an empty string for other blocks.
+// import => to, moved and removed => from
+func configBlockKey(b *hclwrite.Block) string {
+	var name string
+	switch b.Type() {
+	case "import":
+		name = "to"
+	case "moved", "removed":
+		name = "from"
+	default:
+		return ""
+	}
+
+	attr := b.Body().GetAttribute(name)
+	if attr == nil {
+		return ""
+	}
+	expr := strings.TrimSpace(string(attr.Expr().BuildTokens(nil).Bytes()))
+	return b.Type() + " " + expr
+}
+
+// newMigrationDictionary returns a dictionary which contains the built-in
+// schema and additional definitions loaded from a given schema file.
+// If the schemaFile is empty, it returns the built-in dictionary.
+// Definitions in the schema file take precedence over the built-in ones.
+func newMigrationDictionary(schemaFile string) (*schema.Dictionary, error) {
+	d := migration.NewDefaultDictionary()
+	if schemaFile == "" {
+		return d, nil
+	}
+
+	src, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %s", err)
+	}
+
+	importIDFuncMap, err := schema.ParseImportIDConfig(src, schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	d.RegisterImportIDFuncMap(importIDFuncMap)
+
+	return d, nil
+}
+
+func newMigrationFromconfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fromconfig",
+		Short: "Generate a migration file from Terraform configuration diff",
+		Long: `Generate a migration file from Terraform configuration diff
+
+Compare Terraform configurations in two directories before and after
+refactoring without running terraform plan, and generate a migration file
+in tfmigrate HCL format. It detects renamed resources, resources moved into
+local modules, resources split from an existing resource, and resources whose
+type changed. Import IDs are derived from statically known values.
+Anything that can't be derived statically is reported as a warning.
+A resource renamed with changes to its arguments is only reported as a
+warning unless --guess-renames is set.
+With --mode=config, generate Terraform import, moved and removed
+blocks instead.
+`,
+		RunE: runMigrationFromconfigCmd,
+	}
+
+	flags := cmd.Flags()
+	flags.String("before", "", "A path to a directory of Terraform configuration before refactoring")
+	flags.String("after", "", "A path to a directory of Terraform configuration after refactoring")
+	flags.StringP("out", "o", "-", "Write a migration file to a given path")
+	flags.StringP("dir", "d", "", "Set a dir attribute in a migration file")
+	flags.String("schema", "", "A path to a schema file which defines import IDs for additional resource types")
+	flags.String("mode", "migration", "Output format: migration (tfmigrate) or config (import/moved/removed blocks)")
+	flags.Bool("group-comments", false, "Add comments which group actions by the original resource such as aws_s3_bucket")
+	flags.Bool("guess-renames", false, "Move the only removed and added resources of the same type as renamed even if their arguments differ")
+	_ = viper.BindPFlag("migration.fromconfig.before", flags.Lookup("before"))
+	_ = viper.BindPFlag("migration.fromconfig.after", flags.Lookup("after"))
+	_ = viper.BindPFlag("migration.fromconfig.out", flags.Lookup("out"))
+	_ = viper.BindPFlag("migration.fromconfig.dir", flags.Lookup("dir"))
+	_ = viper.BindPFlag("migration.fromconfig.schema", flags.Lookup("schema"))
+	_ = viper.BindPFlag("migration.fromconfig.mode", flags.Lookup("mode"))
+	_ = viper.BindPFlag("migration.fromconfig.group-comments", flags.Lookup("group-comments"))
+	_ = viper.BindPFlag("migration.fromconfig.guess-renames", flags.Lookup("guess-renames"))
+
+	return cmd
+}
+
+func runMigrationFromconfigCmd(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected 0 argument, but got %d arguments", len(args))
+	}
+
+	beforeDir := viper.GetString("migration.fromconfig.before")
+	afterDir := viper.GetString("migration.fromconfig.after")
+	migrationFile := viper.GetString("migration.fromconfig.out")
+	migrationDir := viper.GetString("migration.fromconfig.dir")
+	schemaFile := viper.GetString("migration.fromconfig.schema")
+	mode := migration.GenerateMode(viper.GetString("migration.fromconfig.mode"))
+	groupComments := viper.GetBool("migration.fromconfig.group-comments")
+	guessRenames := viper.GetBool("migration.fromconfig.guess-renames")
+
+	if beforeDir == "" {
+		return fmt.Errorf("the --before flag is required")
+	}
+	if afterDir == "" {
+		return fmt.Errorf("the --after flag is required")
+	}
+
+	dictionary, err := newMigrationDictionary(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	o := &migration.GenerateOption{
+		Dir:           migrationDir,
+		Dictionary:    dictionary,
+		Mode:          mode,
+		GroupComments: groupComments,
+		GuessRenames:  guessRenames,
+	}
+	output, warnings, err := migration.GenerateFromConfig(beforeDir, afterDir, o)
+	if err != nil {
+		return err
+	}
+
+	// The warnings are also written in the migration file, but nothing is
+	// written when no action. Print them so that we don't miss them.
+	for _, w := range warnings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+	}
+
+	// Suppress creating a migration file when no action.
+	if len(output) == 0 {
+		return nil
+	}
+
 	if migrationFile == "-" {
 		fmt.Fprint(cmd.OutOrStdout(), string(output))
 	} else {
@@ -105,3 +378,90 @@ func runMigrationFromplanCmd(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func newMigrationVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a migration file offline",
+		Long: `Verify a migration file offline
+
+Simulate import, mv and rm actions in a migration file against
+a state snapshot, and report planned creates, updates and deletes
+which remain after migration. It also verifies that import IDs match
+the expected attributes in the plan.
+It doesn't call Terraform, so it's an approximation of running
+tfmigrate plan, but useful for a fast pre-flight check in CI.
+`,
+		RunE: runMigrationVerifyCmd,
+	}
+
+	flags := cmd.Flags()
+	flags.String("state", "", "A path to a state file in JSON format. If not set, the prior state in the plan is used")
+	flags.String("plan", "", "A path to a Terraform JSON plan file generated before migration")
+	flags.String("migration", "", "A path to a migration file to be verified")
+	flags.String("schema", "", "A path to a schema file which defines import IDs for additional resource types")
+	_ = viper.BindPFlag("migration.verify.state", flags.Lookup("state"))
+	_ = viper.BindPFlag("migration.verify.plan", flags.Lookup("plan"))
+	_ = viper.BindPFlag("migration.verify.migration", flags.Lookup("migration"))
+	_ = viper.BindPFlag("migration.verify.schema", flags.Lookup("schema"))
+
+	return cmd
+}
+
+func runMigrationVerifyCmd(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected 0 argument, but got %d arguments", len(args))
+	}
+
+	stateFile := viper.GetString("migration.verify.state")
+	planFile := viper.GetString("migration.verify.plan")
+	migrationFile := viper.GetString("migration.verify.migration")
+	schemaFile := viper.GetString("migration.verify.schema")
+
+	if planFile == "" {
+		return fmt.Errorf("the --plan flag is required")
+	}
+	if migrationFile == "" {
+		return fmt.Errorf("the --migration flag is required")
+	}
+
+	var stateJSON []byte
+	if stateFile != "" {
+		b, err := os.ReadFile(stateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %s", err)
+		}
+		stateJSON = b
+	}
+
+	planJSON, err := os.ReadFile(planFile)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %s", err)
+	}
+
+	migrationSrc, err := os.ReadFile(migrationFile)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %s", err)
+	}
+
+	dictionary, err := newMigrationDictionary(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	o := &migration.VerifyOption{
+		Dictionary: dictionary,
+	}
+	result, err := migration.VerifyMigrationFile(stateJSON, planJSON, migrationSrc, migrationFile, o)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), string(result.Render()))
+
+	if !result.IsClean() {
+		return fmt.Errorf("verification failed: %s", migrationFile)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAppendConfigFile(t *testing.T) {
+	cases := []struct {
+		desc    string
+		current string
+		blocks  string
+		ok      bool
+		want    string
+	}{
+		{
+			desc:    "not exist",
+			current: "",
+			blocks: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+			ok: true,
+			want: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+		},
+		{
+			desc: "append",
+			current: `moved {
+  from = aws_s3_bucket.foo
+  to   = aws_s3_bucket.bar
+}
+`,
+			blocks: `# WARNING: foo
+# WARNING: bar
+
+# Source: aws_s3_bucket.example
+import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+			ok: true,
+			want: `moved {
+  from = aws_s3_bucket.foo
+  to   = aws_s3_bucket.bar
+}
+
+# WARNING: foo
+# WARNING: bar
+
+# Source: aws_s3_bucket.example
+import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+		},
+		{
+			desc: "skip existing blocks",
+			current: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+
+removed {
+  from = aws_s3_bucket_policy.example
+}
+`,
+			blocks: `# Source: aws_s3_bucket.example
+import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+
+# Source: aws_s3_bucket.example
+import {
+  to = aws_s3_bucket_policy.example
+  id = "tfedit-test"
+}
+
+removed {
+  from = aws_s3_bucket_policy.example
+}
+`,
+			ok: true,
+			want: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+
+removed {
+  from = aws_s3_bucket_policy.example
+}
+
+# Source: aws_s3_bucket.example
+import {
+  to = aws_s3_bucket_policy.example
+  id = "tfedit-test"
+}
+`,
+		},
+		{
+			desc: "all blocks exist",
+			current: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+			blocks: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+			ok:   true,
+			want: "",
+		},
+		{
+			desc:    "invalid file",
+			current: `import {`,
+			blocks: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), defaultMigrationConfigFile)
+			if tc.current != "" {
+				if err := os.WriteFile(filename, []byte(tc.current), 0600); err != nil {
+					t.Fatalf("failed to write file: %s", err)
+				}
+			}
+
+			output, err := appendConfigFile(filename, []byte(tc.blocks))
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: \n%s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
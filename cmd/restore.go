@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/minamijoyo/tfedit/tfeditor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	RootCmd.AddCommand(newRestoreCmd())
+}
+
+func newRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Roll back the last run of filter from backups",
+		Long: `Roll back the last run of filter from backups
+
+Restore files updated in the last run of tfedit filter with -u --backup
+from their backups recorded in a manifest file. Files created in the run are
+removed. After restoring, the backups and the manifest are removed.
+`,
+		RunE: runRestoreCmd,
+	}
+
+	flags := cmd.Flags()
+	flags.String("manifest", tfeditor.DefaultBackupManifest, "A path to a manifest file of backups")
+	_ = viper.BindPFlag("restore.manifest", flags.Lookup("manifest"))
+
+	return cmd
+}
+
+func runRestoreCmd(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("expected 0 argument, but got %d arguments", len(args))
+	}
+
+	manifest := viper.GetString("restore.manifest")
+	restored, err := tfeditor.Restore(manifest)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range restored {
+		fmt.Fprintf(cmd.OutOrStdout(), "Restored %s\n", f)
+	}
+
+	return nil
+}
@@ -3,7 +3,7 @@ package cmd
 import (
 	"os"
 
-	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/tfeditor"
 	"github.com/spf13/cobra"
 )
 
@@ -25,11 +25,12 @@ func setDefaultStream(cmd *cobra.Command) {
 	cmd.SetErr(os.Stderr)
 }
 
-func newDefaultClient(cmd *cobra.Command) editor.Client {
-	o := &editor.Option{
-		InStream:  cmd.InOrStdin(),
-		OutStream: cmd.OutOrStdout(),
-		ErrStream: cmd.ErrOrStderr(),
+func newDefaultClient(cmd *cobra.Command, fs tfeditor.FileSystem) tfeditor.Client {
+	o := &tfeditor.Option{
+		InStream:   cmd.InOrStdin(),
+		OutStream:  cmd.OutOrStdout(),
+		ErrStream:  cmd.ErrOrStderr(),
+		FileSystem: fs,
 	}
-	return editor.NewClient(o)
+	return tfeditor.NewClient(o)
 }
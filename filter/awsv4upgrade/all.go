@@ -10,20 +10,120 @@ import (
 // to AWS provider v4.
 // https://registry.terraform.io/providers/hashicorp/aws/latest/docs/guides/version-4-upgrade
 type AllFilter struct {
+	// A recorder for split resources. It's nil if not needed.
+	recorder *SplitRecorder
+	// The owner of aws_s3_bucket_acl. It's nil if a placeholder is enough.
+	owner *canonicalUserID
+	// Generate aws_s3_bucket_ownership_controls for aws_s3_bucket_acl.
+	ownershipControls bool
+	// References to nested attributes of aws_s3_bucket in the module.
+	references *nestedReferences
+	// Split resources which already exist in the module.
+	existing *existingSplits
+	// Names of split resources.
+	namer *splitNamer
+	// True if Prepare has been called.
+	prepared bool
+	// A name of the file being filtered.
+	filename string
 }
 
 var _ editor.Filter = (*AllFilter)(nil)
+var _ tfeditor.FileSetFilter = (*AllFilter)(nil)
+
+// Option is a set of options for AllFilter.
+type Option struct {
+	// A recorder for split resources. It's nil if not needed.
+	Recorder *SplitRecorder
+
+	// A literal canonical user ID set to the owner of aws_s3_bucket_acl.
+	// It takes precedence over CanonicalUserIDDataSource.
+	CanonicalUserID string
+
+	// If true, the owner of aws_s3_bucket_acl refers to an
+	// aws_canonical_user_id data source, which is inserted once per module if
+	// not found.
+	CanonicalUserIDDataSource bool
+
+	// If true, aws_s3_bucket_ownership_controls is generated for each
+	// aws_s3_bucket_acl, which is required to apply it to buckets created
+	// after April 2023 because ACLs are disabled by default.
+	OwnershipControls bool
+
+	// A naming template for split resources. If nil,
+	// DefaultSplitNameTemplate is used.
+	SplitNameTemplate *SplitNameTemplate
+}
 
 // NewAllFilter creates a new instance of AllFilter.
 func NewAllFilter() editor.Filter {
-	return &AllFilter{}
+	return NewAllFilterWithOption(&Option{})
+}
+
+// NewAllFilterWithRecorder creates a new instance of AllFilter which records
+// split resources to a given recorder.
+func NewAllFilterWithRecorder(recorder *SplitRecorder) editor.Filter {
+	return NewAllFilterWithOption(&Option{Recorder: recorder})
+}
+
+// NewAllFilterWithOption creates a new instance of AllFilter with a given
+// option.
+func NewAllFilterWithOption(o *Option) editor.Filter {
+	namer := newSplitNamer(o.SplitNameTemplate)
+	f := &AllFilter{
+		recorder:          o.Recorder,
+		ownershipControls: o.OwnershipControls,
+		references:        newNestedReferences(namer, o.Recorder),
+		existing:          newExistingSplits(),
+		namer:             namer,
+	}
+	if o.CanonicalUserID != "" || o.CanonicalUserIDDataSource {
+		f.owner = newCanonicalUserID(o.CanonicalUserID, o.CanonicalUserIDDataSource)
+	}
+	return f
+}
+
+// Prepare finds existing aws_canonical_user_id data sources and split
+// resources, indexes arguments of aws_s3_bucket resources and plans names of
+// split resources in given files.
+func (f *AllFilter) Prepare(files map[string][]byte) error {
+	f.owner.prepare(files)
+	f.existing.prepare(files)
+	f.references.prepare(files)
+	if err := f.namer.prepare(files); err != nil {
+		return err
+	}
+	f.prepared = true
+	return nil
+}
+
+// SetFilename sets a name of the file being filtered.
+func (f *AllFilter) SetFilename(filename string) {
+	f.owner.setFilename(filename)
+	f.existing.setFilename(filename)
+	f.references.setFilename(filename)
+	f.namer.setFilename(filename)
+	f.filename = filename
 }
 
 // Filter upgrades configurations to AWS provider v4.
 func (f *AllFilter) Filter(inFile *hclwrite.File) (*hclwrite.File, error) {
+	// When reading from stdin, Prepare is not called. In this case, we can only
+	// refer to the file being filtered.
+	if !f.prepared {
+		files := map[string][]byte{f.filename: inFile.Bytes()}
+		f.references.prepare(files)
+		if err := f.namer.prepare(files); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rewrite references before splitting, so that references to the website
+	// attributes are renamed to the split resources named by the namer.
 	mf := tfeditor.NewMultiBlockFilter([]tfeditor.BlockFilter{
 		NewProviderAWSFilter(),
-		NewAWSS3BucketFilter(),
+		tfeditor.BlockFilterFunc(f.references.BlockFilter),
+		newAWSS3BucketFilter(f.recorder, f.owner, f.ownershipControls, f.existing, f.namer),
 	})
 
 	bf := tfeditor.NewFileFilter(mf)
@@ -1,6 +1,12 @@
 package awsv4upgrade
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/minamijoyo/tfedit/tfeditor"
 	"github.com/minamijoyo/tfedit/tfwrite"
 )
@@ -9,52 +15,251 @@ import (
 // aws_s3_bucket to AWS provider v4.
 // https://registry.terraform.io/providers/hashicorp/aws/latest/docs/guides/version-4-upgrade#s3-bucket-refactor
 type AWSS3BucketFilter struct {
-	filters []tfeditor.BlockFilter
+	filters []splitFilter
+	// A formatter for the aws_s3_bucket resource applied after propagating
+	// meta arguments to split resources. It's nil if not needed.
+	formatter tfeditor.BlockFilter
+	// A recorder for split resources. It's nil if not needed.
+	recorder *SplitRecorder
+	// Split resources which already exist in the module. If nil, only the
+	// file being filtered is searched.
+	existing *existingSplits
+	// Names of split resources. If nil, they are the same as the bucket.
+	namer *splitNamer
 }
 
 var _ tfeditor.BlockFilter = (*AWSS3BucketFilter)(nil)
 
+// splitFilter is a filter which splits arguments of aws_s3_bucket to a new
+// resource of a given type.
+type splitFilter struct {
+	resourceType string
+	filter       tfeditor.BlockFilter
+}
+
 // NewAWSS3BucketFilter creates a new instance of AWSS3BucketFilter.
 func NewAWSS3BucketFilter() tfeditor.BlockFilter {
-	filters := []tfeditor.BlockFilter{
-		tfeditor.ResourceFilterFunc(AWSS3BucketAccelerationStatusResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketACLResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketCorsRuleResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketGrantResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketLifecycleRuleResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketLoggingResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketObjectLockConfigurationResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketPolicyResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketReplicationConfigurationResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketRequestPayerResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketServerSideEncryptionConfigurationResourceFilter),
-		tfeditor.ResourceFilterFunc(AWSS3BucketVersioningResourceFilter),
-		tfeditor.BlockFilterFunc(AWSS3BucketWebsiteBlockFilter),
+	return NewAWSS3BucketFilterWithRecorder(nil)
+}
+
+// NewAWSS3BucketFilterWithRecorder creates a new instance of
+// AWSS3BucketFilter which records split resources to a given recorder.
+func NewAWSS3BucketFilterWithRecorder(recorder *SplitRecorder) tfeditor.BlockFilter {
+	return newAWSS3BucketFilter(recorder, nil, false, nil, nil)
+}
 
+// newAWSS3BucketFilter creates a new instance of AWSS3BucketFilter which
+// records split resources to a given recorder and sets the owner of
+// aws_s3_bucket_acl with a given canonicalUserID. Both of them can be nil.
+// If ownershipControls is true, aws_s3_bucket_ownership_controls is also
+// generated for aws_s3_bucket_acl. Arguments are not split if a split resource
+// for the bucket already exists in the existingSplits, which can be nil.
+// Split resources are named by a given splitNamer, which can be nil.
+func newAWSS3BucketFilter(recorder *SplitRecorder, owner *canonicalUserID, ownershipControls bool, existing *existingSplits, namer *splitNamer) tfeditor.BlockFilter {
+	filters := []splitFilter{
+		{"aws_s3_bucket_accelerate_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketAccelerationStatusResourceFilter)},
+		{"aws_s3_bucket_acl", newAWSS3BucketACLResourceFilter(ownershipControls, existing)},
+		{"aws_s3_bucket_cors_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketCorsRuleResourceFilter)},
+		{"aws_s3_bucket_acl", newAWSS3BucketGrantResourceFilter(owner, ownershipControls, existing)},
+		{"aws_s3_bucket_lifecycle_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketLifecycleRuleResourceFilter)},
+		{"aws_s3_bucket_logging", tfeditor.ResourceFilterFunc(AWSS3BucketLoggingResourceFilter)},
+		{"aws_s3_bucket_object_lock_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketObjectLockConfigurationResourceFilter)},
+		{"aws_s3_bucket_policy", tfeditor.ResourceFilterFunc(AWSS3BucketPolicyResourceFilter)},
+		{"aws_s3_bucket_replication_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketReplicationConfigurationResourceFilter)},
+		{"aws_s3_bucket_request_payment_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketRequestPayerResourceFilter)},
+		{"aws_s3_bucket_server_side_encryption_configuration", tfeditor.ResourceFilterFunc(AWSS3BucketServerSideEncryptionConfigurationResourceFilter)},
+		{"aws_s3_bucket_versioning", tfeditor.ResourceFilterFunc(AWSS3BucketVersioningResourceFilter)},
+		{"aws_s3_bucket_website_configuration", tfeditor.BlockFilterFunc(AWSS3BucketWebsiteBlockFilter)},
+	}
+
+	return &AWSS3BucketFilter{
+		filters: filters,
 		// Remove redundant TokenNewLine tokens in the resource block after removing nested blocks.
 		// Since VerticalFormat clears tokens internally, we should call it at the end.
-		tfeditor.NewVerticalFormatterBlockFilter("resource", "aws_s3_bucket"),
+		formatter: tfeditor.NewVerticalFormatterBlockFilter("resource", "aws_s3_bucket"),
+		recorder:  recorder,
+		existing:  existing,
+		namer:     namer,
 	}
-
-	return &AWSS3BucketFilter{filters: filters}
 }
 
 // BlockFilter upgrades arguments of aws_s3_bucket to AWS provider v4.
 // Some rules have not been implemented yet.
 func (f *AWSS3BucketFilter) BlockFilter(inFile *tfwrite.File, block tfwrite.Block) (*tfwrite.File, error) {
-	m := tfeditor.NewMultiBlockFilter(f.filters)
-	return m.BlockFilter(inFile, block)
+	resource, ok := block.(*tfwrite.Resource)
+	if !ok || resource.SchemaType() != "aws_s3_bucket" {
+		filters := make([]tfeditor.BlockFilter, 0, len(f.filters))
+		for _, sf := range f.filters {
+			filters = append(filters, sf.filter)
+		}
+		return tfeditor.NewMultiBlockFilter(filters).BlockFilter(inFile, block)
+	}
+
+	// If a split resource for the bucket already exists, such as a partially
+	// upgraded module, appending a new one causes a duplicate declaration.
+	// Leave the arguments as they are and report it instead.
+	// Note that we should check it before filtering, because split resources
+	// appended by filters also refer to the bucket.
+	filters := []tfeditor.BlockFilter{}
+	skipped := map[string]bool{}
+	for _, sf := range f.filters {
+		if skipped[sf.resourceType] {
+			continue
+		}
+		if hasSplitArguments(resource, sf.resourceType) {
+			if address := f.existing.find(inFile, resource, sf.resourceType); address != "" {
+				skipped[sf.resourceType] = true
+				if f.recorder != nil {
+					f.recorder.warn(skippedSplitWarning(resource, address))
+				}
+				continue
+			}
+		}
+		filters = append(filters, sf.filter)
+	}
+	m := tfeditor.NewMultiBlockFilter(filters)
+
+	// Each filter inserts split resources after the bucket, so new blocks
+	// after filtering are the split resources of the bucket.
+	oldBlocks := map[*hclwrite.Block]bool{}
+	for _, b := range inFile.Blocks() {
+		oldBlocks[b.Raw()] = true
+	}
+	outFile, err := m.BlockFilter(inFile, block)
+	if err != nil {
+		return nil, err
+	}
+	newBlocks := []tfwrite.Block{}
+	for _, b := range outFile.Blocks() {
+		if !oldBlocks[b.Raw()] {
+			newBlocks = append(newBlocks, b)
+		}
+	}
+
+	splits := []*tfwrite.Resource{}
+	for _, b := range newBlocks {
+		if split, ok := b.(*tfwrite.Resource); ok {
+			splits = append(splits, split)
+		}
+	}
+	if f.namer != nil {
+		warnings, err := renameSplits(resource, splits, f.namer)
+		if err != nil {
+			return nil, err
+		}
+		if f.recorder != nil {
+			for _, w := range warnings {
+				f.recorder.warn(w)
+			}
+		}
+	}
+
+	warnings, err := propagateLifecycle(resource, splits)
+	if err != nil {
+		return nil, err
+	}
+	if f.recorder != nil {
+		for _, w := range warnings {
+			f.recorder.warn(w)
+		}
+	}
+
+	if f.recorder != nil {
+		f.recorder.record(resource, newBlocks)
+	}
+
+	if f.formatter != nil {
+		return f.formatter.BlockFilter(outFile, resource)
+	}
+	return outFile, nil
 }
 
 // setParentBucket is a helper method for setting the followings:
-// - copy provider, count and for_each meta arguments
+// - copy provider, count, for_each and depends_on meta arguments
 // - set a bucket argument of a new `aws_s3_bucket_*` resource to the original `aws_s3_bucket` resource.
+// Note that the lifecycle meta argument is propagated by the AWSS3BucketFilter
+// after all split resources are created.
 func setParentBucket(newResource *tfwrite.Resource, oldResource *tfwrite.Resource) {
-	// copy provider, count and for_each meta arguments
+	// copy provider, count, for_each and depends_on meta arguments
 	newResource.CopyAttribute(oldResource, "provider")
 	newResource.CopyAttribute(oldResource, "count")
 	newResource.CopyAttribute(oldResource, "for_each")
+	// The depends_on may be extended later, so set it as a structured attribute.
+	if dependsOn := oldResource.GetAttribute("depends_on"); dependsOn != nil {
+		newResource.SetAttributeRaw("depends_on", dependsOn.ValueAsTokens())
+	}
 
 	// set a bucket argument
 	newResource.SetAttributeByReference("bucket", oldResource, "id")
 }
+
+// insertSplit inserts a new split resource directly after a given bucket.
+// Split resources which have already been inserted after the bucket are
+// skipped, so that they are placed in the order of filters.
+// If the bucket is not found in the file, it's appended to the end.
+func insertSplit(inFile *tfwrite.File, bucket *tfwrite.Resource, newResource *tfwrite.Resource) {
+	var pos tfwrite.Block
+	for _, b := range inFile.Blocks() {
+		if pos == nil {
+			if b.Raw() == bucket.Raw() {
+				pos = b
+			}
+			continue
+		}
+		if !isSplitOf(b, bucket) {
+			break
+		}
+		pos = b
+	}
+	inFile.InsertBlockAfter(newResource, pos)
+}
+
+// isSplitOf returns true if a given block is a split resource of a given
+// bucket.
+func isSplitOf(block tfwrite.Block, bucket *tfwrite.Resource) bool {
+	resource, ok := block.(*tfwrite.Resource)
+	if !ok {
+		return false
+	}
+	if !isSplitResourceType(resource.SchemaType()) {
+		return false
+	}
+	bucketName, ok := referredBucketName(resource)
+	return ok && bucketName == bucket.Name()
+}
+
+// conditionalStatus returns tokens of a conditional expression which maps a
+// non-literal boolean expression of a given attribute to status strings.
+// enabled = var.enabled => status = var.enabled ? "Enabled" : "Suspended"
+func conditionalStatus(attr *tfwrite.Attribute, trueStatus string, falseStatus string) (hclwrite.Tokens, error) {
+	cond := strings.TrimSpace(string(attr.ValueAsTokens().Bytes()))
+	if needsParentheses(cond) {
+		cond = "(" + cond + ")"
+	}
+
+	tokens, err := tfwrite.ParseExpressionAsTokens(fmt.Sprintf("%s ? %q : %q", cond, trueStatus, falseStatus))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build a conditional expression for %s: %s", cond, err)
+	}
+	return tokens, nil
+}
+
+// needsParentheses returns true if a given expression needs to be wrapped in
+// parentheses to be used as a condition of a conditional expression, such as
+// `var.a && var.b` or `var.a ? true : false`.
+func needsParentheses(src string) bool {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return true
+	}
+
+	switch expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr, *hclsyntax.RelativeTraversalExpr,
+		*hclsyntax.FunctionCallExpr, *hclsyntax.IndexExpr,
+		*hclsyntax.TemplateWrapExpr, *hclsyntax.ParenthesesExpr,
+		*hclsyntax.LiteralValueExpr:
+		return false
+	default:
+		return true
+	}
+}
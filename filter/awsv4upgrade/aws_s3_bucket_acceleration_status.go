@@ -22,7 +22,7 @@ func AWSS3BucketAccelerationStatusResourceFilter(inFile *tfwrite.File, resource
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
 	setParentBucket(newResource, resource)
 
 	// Map an `acceleration_status` attribute to an `status` attribute.
@@ -1,6 +1,7 @@
 package awsv4upgrade
 
 import (
+	"github.com/minamijoyo/tfedit/tfeditor"
 	"github.com/minamijoyo/tfedit/tfwrite"
 )
 
@@ -8,6 +9,23 @@ import (
 // acl argument of aws_s3_bucket.
 // https://registry.terraform.io/providers/hashicorp/aws/latest/docs/guides/version-4-upgrade#acl-argument
 func AWSS3BucketACLResourceFilter(inFile *tfwrite.File, resource *tfwrite.Resource) (*tfwrite.File, error) {
+	return awsS3BucketACLResourceFilter(inFile, resource, false, nil)
+}
+
+// newAWSS3BucketACLResourceFilter returns a filter implementation for
+// upgrading the acl argument of aws_s3_bucket. If ownershipControls is true,
+// it also generates aws_s3_bucket_ownership_controls which aws_s3_bucket_acl
+// depends on, unless it already exists in the existingSplits, which can be
+// nil.
+func newAWSS3BucketACLResourceFilter(ownershipControls bool, existing *existingSplits) tfeditor.ResourceFilterFunc {
+	return tfeditor.ResourceFilterFunc(func(inFile *tfwrite.File, resource *tfwrite.Resource) (*tfwrite.File, error) {
+		return awsS3BucketACLResourceFilter(inFile, resource, ownershipControls, existing)
+	})
+}
+
+// awsS3BucketACLResourceFilter splits the acl argument to a new
+// aws_s3_bucket_acl resource.
+func awsS3BucketACLResourceFilter(inFile *tfwrite.File, resource *tfwrite.Resource, ownershipControls bool, existing *existingSplits) (*tfwrite.File, error) {
 	if resource.SchemaType() != "aws_s3_bucket" {
 		return inFile, nil
 	}
@@ -20,12 +38,22 @@ func AWSS3BucketACLResourceFilter(inFile *tfwrite.File, resource *tfwrite.Resour
 		return inFile, nil
 	}
 
+	ownershipControlsAddress := ""
+	if ownershipControls {
+		ownershipControlsAddress = appendOwnershipControls(inFile, resource, existing)
+	}
+
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
 	setParentBucket(newResource, resource)
 	newResource.AppendAttribute(attr)
 	resource.RemoveAttribute(oldAttribute)
+	if ownershipControlsAddress != "" {
+		if err := appendDependsOn(newResource, ownershipControlsAddress); err != nil {
+			return nil, err
+		}
+	}
 
 	return inFile, nil
 }
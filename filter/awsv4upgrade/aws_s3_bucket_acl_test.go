@@ -72,3 +72,166 @@ resource "aws_s3_bucket" "example" {
 		})
 	}
 }
+
+func TestAWSS3BucketACLFilterWithOwnershipControls(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		ok   bool
+		want string
+	}{
+		{
+			name: "simple",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_ownership_controls" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket     = aws_s3_bucket.example.id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.example]
+}
+`,
+		},
+		{
+			name: "with count",
+			src: `
+resource "aws_s3_bucket" "example" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+  acl    = "private"
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+}
+
+resource "aws_s3_bucket_ownership_controls" "example" {
+  count  = 2
+  bucket = aws_s3_bucket.example[count.index].id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  count      = 2
+  bucket     = aws_s3_bucket.example[count.index].id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.example]
+}
+`,
+		},
+		{
+			name: "already exists",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+
+resource "aws_s3_bucket_ownership_controls" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "ObjectWriter"
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_ownership_controls" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "ObjectWriter"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket     = aws_s3_bucket.example.id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.example]
+}
+`,
+		},
+		{
+			name: "with depends_on",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket     = "tfedit-test"
+  acl        = "private"
+  depends_on = [aws_iam_role.example]
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket     = "tfedit-test"
+  depends_on = [aws_iam_role.example]
+}
+
+resource "aws_s3_bucket_ownership_controls" "example" {
+  depends_on = [aws_iam_role.example]
+  bucket     = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  depends_on = [aws_iam_role.example, aws_s3_bucket_ownership_controls.example]
+  bucket     = aws_s3_bucket.example.id
+  acl        = "private"
+}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := buildTestResourceFilter(newAWSS3BucketACLResourceFilter(true, nil))
+			o := editor.NewEditOperator(filter)
+			output, err := o.Apply([]byte(tc.src), "test")
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, outStream: \n%s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
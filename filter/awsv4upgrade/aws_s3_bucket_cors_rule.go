@@ -16,13 +16,19 @@ func AWSS3BucketCorsRuleResourceFilter(inFile *tfwrite.File, resource *tfwrite.R
 	newResourceType := "aws_s3_bucket_cors_configuration"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+	if len(nestedBlocks) == 0 {
+		if err := setDynamicCount(newResource, resource, dynamicBlocks); err != nil {
+			return nil, err
+		}
+	}
 	setParentBucket(newResource, resource)
 
 	for _, nestedBlock := range nestedBlocks {
@@ -30,5 +36,11 @@ func AWSS3BucketCorsRuleResourceFilter(inFile *tfwrite.File, resource *tfwrite.R
 		resource.RemoveNestedBlock(nestedBlock)
 	}
 
+	// A `dynamic "cors_rule"` block can be moved as it is.
+	for _, dynamicBlock := range dynamicBlocks {
+		newResource.AppendNestedBlock(dynamicBlock)
+		resource.RemoveNestedBlock(dynamicBlock)
+	}
+
 	return inFile, nil
 }
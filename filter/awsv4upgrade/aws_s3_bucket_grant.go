@@ -1,14 +1,38 @@
 package awsv4upgrade
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfeditor"
 	"github.com/minamijoyo/tfedit/tfwrite"
-	"github.com/zclconf/go-cty/cty"
 )
 
 // AWSS3BucketGrantResourceFilter is a filter implementation for
 // upgrading the grant argument of aws_s3_bucket.
 // https://registry.terraform.io/providers/hashicorp/aws/latest/docs/guides/version-4-upgrade#grant-argument
+// It sets a placeholder to the owner of aws_s3_bucket_acl, which needs to be
+// fixed by hand.
 func AWSS3BucketGrantResourceFilter(inFile *tfwrite.File, resource *tfwrite.Resource) (*tfwrite.File, error) {
+	return awsS3BucketGrantResourceFilter(inFile, resource, nil, false, nil)
+}
+
+// newAWSS3BucketGrantResourceFilter returns a filter implementation for
+// upgrading the grant argument of aws_s3_bucket, which sets the owner of
+// aws_s3_bucket_acl with a given canonicalUserID. If ownershipControls is
+// true, it also generates aws_s3_bucket_ownership_controls which
+// aws_s3_bucket_acl depends on, unless it already exists in the
+// existingSplits, which can be nil.
+func newAWSS3BucketGrantResourceFilter(owner *canonicalUserID, ownershipControls bool, existing *existingSplits) tfeditor.ResourceFilterFunc {
+	return tfeditor.ResourceFilterFunc(func(inFile *tfwrite.File, resource *tfwrite.Resource) (*tfwrite.File, error) {
+		return awsS3BucketGrantResourceFilter(inFile, resource, owner, ownershipControls, existing)
+	})
+}
+
+// awsS3BucketGrantResourceFilter splits the grant argument to a new
+// aws_s3_bucket_acl resource.
+func awsS3BucketGrantResourceFilter(inFile *tfwrite.File, resource *tfwrite.Resource, owner *canonicalUserID, ownershipControls bool, existing *existingSplits) (*tfwrite.File, error) {
 	if resource.SchemaType() != "aws_s3_bucket" {
 		return inFile, nil
 	}
@@ -17,14 +41,34 @@ func AWSS3BucketGrantResourceFilter(inFile *tfwrite.File, resource *tfwrite.Reso
 	newResourceType := "aws_s3_bucket_acl"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
+	// Resolve the owner before appending a new resource, so that an inserted
+	// data source is placed before the resource.
+	ownerID := owner.ownerID(inFile)
+
+	ownershipControlsAddress := ""
+	if ownershipControls {
+		ownershipControlsAddress = appendOwnershipControls(inFile, resource, existing)
+	}
+
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+	if len(nestedBlocks) == 0 {
+		if err := setDynamicCount(newResource, resource, dynamicBlocks); err != nil {
+			return nil, err
+		}
+	}
 	setParentBucket(newResource, resource)
+	if ownershipControlsAddress != "" {
+		if err := appendDependsOn(newResource, ownershipControlsAddress); err != nil {
+			return nil, err
+		}
+	}
 
 	acpBlock := tfwrite.NewEmptyNestedBlock("access_control_policy")
 	newResource.AppendNestedBlock(acpBlock)
@@ -65,7 +109,11 @@ func AWSS3BucketGrantResourceFilter(inFile *tfwrite.File, resource *tfwrite.Reso
 		if permissions == nil {
 			// The `permissions` attrubute cannot be parsed as a list.
 			// If the `permissions` attribute is passed as a variable or generated by a function,
-			// it cannot be split automatically.
+			// it cannot be split statically, so generate grant blocks with a dynamic block.
+			if err := appendDynamicGrant(acpBlock, nestedBlock, permissionsAttr); err != nil {
+				return nil, err
+			}
+			resource.RemoveNestedBlock(nestedBlock)
 			continue
 		}
 
@@ -82,14 +130,143 @@ func AWSS3BucketGrantResourceFilter(inFile *tfwrite.File, resource *tfwrite.Reso
 		resource.RemoveNestedBlock(nestedBlock)
 	}
 
+	for _, dynamicBlock := range dynamicBlocks {
+		if dynamicBlock.Content().GetAttribute("permissions") == nil {
+			// The `permissions` attrubute is required, skip if not found.
+			continue
+		}
+		if err := appendDynamicGrantFromDynamic(acpBlock, dynamicBlock); err != nil {
+			return nil, err
+		}
+		resource.RemoveNestedBlock(dynamicBlock)
+	}
+
 	ownerBlock := tfwrite.NewEmptyNestedBlock("owner")
 	acpBlock.AppendNestedBlock(ownerBlock)
 	// A grant argument of aws_s3_bucket in v3 doesn’t have an owner block,
 	// https://registry.terraform.io/providers/hashicorp/aws/3.74.3/docs/resources/s3_bucket#grant
 	// but an access_control_policy argument of aws_s3_bucket_acl in v4 has an owner block as required.
 	// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/s3_bucket_acl#access_control_policy
-	// There is no way to set it automatically without the AWS API call.
-	ownerBlock.SetAttributeValue("id", cty.StringVal("set_aws_canonical_user_id"))
+	// There is no way to know it statically without the AWS API call, so it's a
+	// placeholder, a literal or a reference to an aws_canonical_user_id data
+	// source depending on the option.
+	ownerBlock.SetAttributeRaw("id", ownerID)
 
 	return inFile, nil
 }
+
+// appendDynamicGrant appends a dynamic grant block to a given
+// access_control_policy block, which splits a non-literal permissions
+// attribute of a given grant block to each grant block.
+func appendDynamicGrant(acpBlock tfwrite.Block, grantBlock tfwrite.Block, permissionsAttr *tfwrite.Attribute) error {
+	permission, err := tfwrite.ParseExpressionAsTokens("grant.value")
+	if err != nil {
+		return fmt.Errorf("failed to build a dynamic grant block: %s", err)
+	}
+
+	// grant {
+	//   type        = "Group"
+	//   permissions = var.permissions
+	//   uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+	// }
+	// =>
+	// dynamic "grant" {
+	//   for_each = var.permissions
+	//   content {
+	//     grantee {
+	//       type = "Group"
+	//       uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+	//     }
+	//     permission = grant.value
+	//   }
+	// }
+	dynamicBlock := tfwrite.NewEmptyDynamicBlock("grant")
+	acpBlock.AppendNestedBlock(dynamicBlock)
+	dynamicBlock.SetAttributeRaw("for_each", permissionsAttr.ValueAsTokens())
+	contentBlock := tfwrite.NewEmptyNestedBlock("content")
+	dynamicBlock.AppendNestedBlock(contentBlock)
+	granteeBlock := tfwrite.NewEmptyNestedBlock("grantee")
+	contentBlock.AppendNestedBlock(granteeBlock)
+	grantBlock.RemoveAttribute("permissions")
+	granteeBlock.AppendUnwrappedNestedBlockBody(grantBlock)
+	contentBlock.SetAttributeRaw("permission", permission)
+
+	return nil
+}
+
+// appendDynamicGrantFromDynamic appends a dynamic grant block to a given
+// access_control_policy block, which is converted from a given dynamic grant
+// block of aws_s3_bucket. Since each grant block has only one permission in
+// v4, the for_each argument is flattened to pairs of a grant and a permission.
+func appendDynamicGrantFromDynamic(acpBlock tfwrite.Block, dynamicBlock *tfwrite.DynamicBlock) error {
+	iterator := dynamicBlock.Iterator()
+	content := dynamicBlock.Content()
+
+	// dynamic "grant" {
+	//   for_each = var.grants
+	//   content {
+	//     type        = grant.value.type
+	//     permissions = grant.value.permissions
+	//     uri         = grant.value.uri
+	//   }
+	// }
+	// =>
+	// dynamic "grant" {
+	//   for_each = flatten([for key, value in var.grants : [for permission in value.permissions : { key = key, value = value, permission = permission }]])
+	//   content {
+	//     grantee {
+	//       type = grant.value.value.type
+	//       uri  = grant.value.value.uri
+	//     }
+	//     permission = grant.value.permission
+	//   }
+	// }
+	permissions, err := replaceIteratorAsTokens(content.GetAttribute("permissions").ValueAsTokens(), iterator, "value", "key")
+	if err != nil {
+		return err
+	}
+	forEach, err := tfwrite.ParseExpressionAsTokens(fmt.Sprintf(
+		"flatten([for key, value in %s : [for permission in %s : { key = key, value = value, permission = permission }]])",
+		forEachAsString(dynamicBlock), strings.TrimSpace(string(permissions.Bytes())),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to build a dynamic grant block: %s", err)
+	}
+	permission, err := tfwrite.ParseExpressionAsTokens(iterator + ".value.permission")
+	if err != nil {
+		return fmt.Errorf("failed to build a dynamic grant block: %s", err)
+	}
+
+	newDynamicBlock := tfwrite.NewEmptyDynamicBlock("grant")
+	acpBlock.AppendNestedBlock(newDynamicBlock)
+	newDynamicBlock.SetAttributeRaw("for_each", forEach)
+	if attr := dynamicBlock.GetAttribute("iterator"); attr != nil {
+		newDynamicBlock.SetAttributeRaw("iterator", attr.ValueAsTokens())
+	}
+	contentBlock := tfwrite.NewEmptyNestedBlock("content")
+	newDynamicBlock.AppendNestedBlock(contentBlock)
+	granteeBlock := tfwrite.NewEmptyNestedBlock("grantee")
+	contentBlock.AppendNestedBlock(granteeBlock)
+	content.RemoveAttribute("permissions")
+	grantee, err := replaceIteratorAsTokens(buildContentTokens(content), iterator, iterator+".value.value", iterator+".value.key")
+	if err != nil {
+		return err
+	}
+	granteeBlock.Raw().Body().AppendUnstructuredTokens(grantee)
+	contentBlock.SetAttributeRaw("permission", permission)
+
+	return nil
+}
+
+// replaceIteratorAsTokens replaces references to the value and key of a
+// given iterator in given tokens with given expressions.
+func replaceIteratorAsTokens(tokens hclwrite.Tokens, iterator string, value string, key string) (hclwrite.Tokens, error) {
+	for _, r := range [][2]string{{iterator + ".value", value}, {iterator + ".key", key}} {
+		replacement, err := tfwrite.ParseExpressionAsTokens(r[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to replace references to %s: %s", r[0], err)
+		}
+		tokens = tfwrite.ReplaceReferenceAsTokens(tokens, r[0], replacement)
+	}
+	return tokens, nil
+}
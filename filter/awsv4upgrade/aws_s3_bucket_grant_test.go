@@ -85,6 +85,172 @@ resource "aws_s3_bucket_acl" "example" {
     }
   }
 }
+`,
+		},
+		{
+			name: "non-literal permissions",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    type        = "Group"
+    permissions = var.log_delivery_permissions
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    dynamic "grant" {
+      for_each = var.log_delivery_permissions
+
+      content {
+
+        grantee {
+
+          type = "Group"
+          uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+        }
+        permission = grant.value
+      }
+    }
+
+    owner {
+      id = "set_aws_canonical_user_id"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "grant" {
+    for_each = var.grants
+    content {
+      id          = grant.value.id
+      type        = grant.value.type
+      permissions = grant.value.permissions
+      uri         = grant.value.uri
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  count  = length(var.grants) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    dynamic "grant" {
+      for_each = flatten([for key, value in var.grants : [for permission in value.permissions : { key = key, value = value, permission = permission }]])
+
+      content {
+
+        grantee {
+
+          id   = grant.value.value.id
+          type = grant.value.value.type
+          uri  = grant.value.value.uri
+        }
+        permission = grant.value.permission
+      }
+    }
+
+    owner {
+      id = "set_aws_canonical_user_id"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "dynamic with iterator",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    type        = "Group"
+    permissions = ["READ_ACP"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+
+  dynamic "grant" {
+    for_each = var.grants
+    iterator = g
+    content {
+      id          = g.value
+      type        = "CanonicalUser"
+      permissions = ["FULL_CONTROL"]
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "READ_ACP"
+    }
+
+    dynamic "grant" {
+      for_each = flatten([for key, value in var.grants : [for permission in ["FULL_CONTROL"] : { key = key, value = value, permission = permission }]])
+      iterator = g
+
+      content {
+
+        grantee {
+
+          id   = g.value.value
+          type = "CanonicalUser"
+        }
+        permission = g.value.permission
+      }
+    }
+
+    owner {
+      id = "set_aws_canonical_user_id"
+    }
+  }
+}
 `,
 		},
 		{
@@ -125,3 +291,265 @@ resource "aws_s3_bucket" "example" {
 		})
 	}
 }
+
+func TestAWSS3BucketGrantFilterWithOption(t *testing.T) {
+	cases := []struct {
+		name              string
+		src               string
+		literal           string
+		useDataSource     bool
+		ownershipControls bool
+		ok                bool
+		want              string
+	}{
+		{
+			name: "literal",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+			literal: "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+			ok:      true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "insert a data source",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+
+resource "aws_s3_bucket" "example2" {
+  bucket = "tfedit-test2"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+			useDataSource: true,
+			ok:            true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current.id
+    }
+  }
+}
+
+resource "aws_s3_bucket" "example2" {
+  bucket = "tfedit-test2"
+
+}
+
+resource "aws_s3_bucket_acl" "example2" {
+  bucket = aws_s3_bucket.example2.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current.id
+    }
+  }
+}
+
+data "aws_canonical_user_id" "current" {
+}
+`,
+		},
+		{
+			name: "reuse an existing data source",
+			src: `
+data "aws_canonical_user_id" "current_user" {}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    id          = data.aws_canonical_user_id.current_user.id
+    type        = "CanonicalUser"
+    permissions = ["FULL_CONTROL"]
+  }
+}
+`,
+			useDataSource: true,
+			ok:            true,
+			want: `
+data "aws_canonical_user_id" "current_user" {}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        id   = data.aws_canonical_user_id.current_user.id
+        type = "CanonicalUser"
+      }
+      permission = "FULL_CONTROL"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current_user.id
+    }
+  }
+}
+`,
+		},
+		{
+			name: "ownership controls",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+			useDataSource:     true,
+			ownershipControls: true,
+			ok:                true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_ownership_controls" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket     = aws_s3_bucket.example.id
+  depends_on = [aws_s3_bucket_ownership_controls.example]
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current.id
+    }
+  }
+}
+
+data "aws_canonical_user_id" "current" {
+}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			owner := newCanonicalUserID(tc.literal, tc.useDataSource)
+			filter := buildTestResourceFilter(newAWSS3BucketGrantResourceFilter(owner, tc.ownershipControls, nil))
+			o := editor.NewEditOperator(filter)
+			output, err := o.Apply([]byte(tc.src), "test")
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, outStream: \n%s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
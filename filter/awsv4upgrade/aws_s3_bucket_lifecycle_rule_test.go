@@ -526,6 +526,102 @@ resource "aws_s3_bucket_lifecycle_configuration" "example" {
     }
   }
 }
+`,
+		},
+		{
+			name: "enabled = var.enabled",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  lifecycle_rule {
+    id      = "rule-0"
+    enabled = var.enabled
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    id     = "rule-0"
+    status = var.enabled ? "Enabled" : "Disabled"
+
+    filter {
+      prefix = ""
+    }
+  }
+}
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "lifecycle_rule" {
+    for_each = var.lifecycle_rules
+    content {
+      id      = lifecycle_rule.value.id
+      enabled = lifecycle_rule.value.enabled
+      prefix  = lifecycle_rule.value.prefix
+
+      dynamic "noncurrent_version_expiration" {
+        for_each = lifecycle_rule.value.noncurrent_version_expiration
+        content {
+          days = noncurrent_version_expiration.value.days
+        }
+      }
+
+      expiration {
+        date = "2022-12-31"
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "example" {
+  count  = length(var.lifecycle_rules) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  dynamic "rule" {
+    for_each = var.lifecycle_rules
+    content {
+      id = rule.value.id
+
+      dynamic "noncurrent_version_expiration" {
+        for_each = rule.value.noncurrent_version_expiration
+        content {
+          noncurrent_days = noncurrent_version_expiration.value.days
+        }
+      }
+
+      expiration {
+        date = "2022-12-31T00:00:00Z"
+      }
+      status = rule.value.enabled ? "Enabled" : "Disabled"
+
+      filter {
+        prefix = rule.value.prefix
+      }
+    }
+  }
+}
 `,
 		},
 		{
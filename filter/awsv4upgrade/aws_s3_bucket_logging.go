@@ -16,13 +16,30 @@ func AWSS3BucketLoggingResourceFilter(inFile *tfwrite.File, resource *tfwrite.Re
 	newResourceType := "aws_s3_bucket_logging"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+
+	if len(nestedBlocks) == 0 {
+		// A logging block appears at most once, so unwrap the content of a
+		// dynamic block and create the new resource only if it's not empty.
+		dynamicBlock := dynamicBlocks[0]
+		if err := setDynamicCount(newResource, resource, dynamicBlocks[:1]); err != nil {
+			return nil, err
+		}
+		setParentBucket(newResource, resource)
+		if err := appendUnwrappedDynamicContent(newResource, dynamicBlock); err != nil {
+			return nil, err
+		}
+		resource.RemoveNestedBlock(dynamicBlock)
+		return inFile, nil
+	}
+
 	setParentBucket(newResource, resource)
 	newResource.AppendUnwrappedNestedBlockBody(nestedBlocks[0])
 	resource.RemoveNestedBlock(nestedBlocks[0])
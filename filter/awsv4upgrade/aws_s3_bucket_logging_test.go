@@ -53,6 +53,37 @@ resource "aws_s3_bucket_logging" "example" {
   target_bucket = aws_s3_bucket.log.id
   target_prefix = "log/"
 }
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "logging" {
+    for_each = var.logging == null ? [] : [var.logging]
+    content {
+      target_bucket = logging.value.target_bucket
+      target_prefix = "${logging.value.prefix}/"
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_logging" "example" {
+  count  = length(var.logging == null ? [] : [var.logging]) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  target_bucket = one(var.logging == null ? [] : [var.logging]).target_bucket
+  target_prefix = "${one(var.logging == null ? [] : [var.logging]).prefix}/"
+}
 `,
 		},
 		{
@@ -1,6 +1,8 @@
 package awsv4upgrade
 
 import (
+	"fmt"
+
 	"github.com/minamijoyo/tfedit/tfwrite"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -17,13 +19,43 @@ func AWSS3BucketObjectLockConfigurationResourceFilter(inFile *tfwrite.File, reso
 	newResourceType := "aws_s3_bucket_object_lock_configuration"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+
+	if len(nestedBlocks) == 0 {
+		dynamicBlock := dynamicBlocks[0]
+		if err := setDynamicCount(newResource, resource, dynamicBlocks[:1]); err != nil {
+			return nil, err
+		}
+		setParentBucket(newResource, resource)
+
+		// The only valid value of `object_lock_enabled` was "Enabled" in v3,
+		// so object lock is enabled if the dynamic block is not empty.
+		// object_lock_enabled = length(var.object_lock_configuration) > 0
+		content := dynamicBlock.Content()
+		if content.GetAttribute("object_lock_enabled") != nil {
+			enabled, err := tfwrite.ParseExpressionAsTokens(fmt.Sprintf("length(%s) > 0", forEachAsString(dynamicBlock)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to set object_lock_enabled to %s.%s: %s", resource.SchemaType(), resource.Name(), err)
+			}
+			resource.SetAttributeRaw("object_lock_enabled", enabled)
+			content.RemoveAttribute("object_lock_enabled")
+		}
+
+		// Move rule blocks in the content block to a new resource
+		if err := appendUnwrappedDynamicContent(newResource, dynamicBlock); err != nil {
+			return nil, err
+		}
+		resource.RemoveNestedBlock(dynamicBlock)
+		return inFile, nil
+	}
+
 	setParentBucket(newResource, resource)
 
 	objectLockBlock := nestedBlocks[0]
@@ -34,6 +66,11 @@ func AWSS3BucketObjectLockConfigurationResourceFilter(inFile *tfwrite.File, reso
 		newResource.AppendNestedBlock(ruleBlock)
 	}
 
+	// A `dynamic "rule"` block can be moved as it is.
+	for _, dynamicBlock := range findDynamicBlocks(objectLockBlock, "rule") {
+		newResource.AppendNestedBlock(dynamicBlock)
+	}
+
 	// Map an `object_lock_configuration.object_lock_enabled` attribute
 	// to a top-level `object_lock_enabled` attribute.
 	// In addition, the valid type is now bool.
@@ -50,6 +50,49 @@ resource "aws_s3_bucket_object_lock_configuration" "example" {
     }
   }
 }
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "object_lock_configuration" {
+    for_each = var.object_lock
+    iterator = lock
+    content {
+      object_lock_enabled = "Enabled"
+
+      rule {
+        default_retention {
+          mode = lock.value.mode
+          days = lock.value.days
+        }
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  object_lock_enabled = length(var.object_lock) > 0
+}
+
+resource "aws_s3_bucket_object_lock_configuration" "example" {
+  count  = length(var.object_lock) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    default_retention {
+      mode = one(var.object_lock).mode
+      days = one(var.object_lock).days
+    }
+  }
+}
 `,
 		},
 		{
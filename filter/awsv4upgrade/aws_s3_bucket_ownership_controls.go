@@ -0,0 +1,33 @@
+package awsv4upgrade
+
+import (
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// appendOwnershipControls inserts an aws_s3_bucket_ownership_controls resource
+// for a given bucket, and returns the address of it for depends_on.
+// Since April 2023, ACLs are disabled by default for new buckets, so that
+// aws_s3_bucket_acl cannot be applied without enabling them:
+// https://aws.amazon.com/blogs/aws/heads-up-amazon-s3-security-changes-are-coming-in-april-of-2023/
+// If a resource which refers to the bucket already exists in the file or in
+// the existingSplits, which can be nil, it's reused. It should be called before
+// inserting aws_s3_bucket_acl, so that the resource is placed before it.
+func appendOwnershipControls(inFile *tfwrite.File, bucket *tfwrite.Resource, existing *existingSplits) string {
+	newResourceType := "aws_s3_bucket_ownership_controls"
+
+	if address := existing.find(inFile, bucket, newResourceType); address != "" {
+		return address
+	}
+
+	resourceName := bucket.Name()
+	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
+	insertSplit(inFile, bucket, newResource)
+	setParentBucket(newResource, bucket)
+
+	ruleBlock := tfwrite.NewEmptyNestedBlock("rule")
+	newResource.AppendNestedBlock(ruleBlock)
+	ruleBlock.SetAttributeValue("object_ownership", cty.StringVal("BucketOwnerPreferred"))
+
+	return newResourceType + "." + resourceName
+}
@@ -16,13 +16,30 @@ func AWSS3BucketReplicationConfigurationResourceFilter(inFile *tfwrite.File, res
 	newResourceType := "aws_s3_bucket_replication_configuration"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+
+	if len(nestedBlocks) == 0 {
+		// Convert the content of a dynamic block in place and unwrap it.
+		dynamicBlock := dynamicBlocks[0]
+		if err := setDynamicCount(newResource, resource, dynamicBlocks[:1]); err != nil {
+			return nil, err
+		}
+		setParentBucket(newResource, resource)
+		upgradeReplicationRules(dynamicBlock.Content())
+		if err := appendUnwrappedDynamicContent(newResource, dynamicBlock); err != nil {
+			return nil, err
+		}
+		resource.RemoveNestedBlock(dynamicBlock)
+		return inFile, nil
+	}
+
 	setParentBucket(newResource, resource)
 
 	for _, nestedBlock := range nestedBlocks {
@@ -31,74 +48,16 @@ func AWSS3BucketReplicationConfigurationResourceFilter(inFile *tfwrite.File, res
 			newResource.AppendAttribute(roleAttr)
 		}
 
+		// Find blocks before renaming, because hclwrite still returns the old
+		// type for a renamed block.
 		rulesBlocks := nestedBlock.FindNestedBlocksByType("rules")
+		dynamicBlocks := findDynamicBlocks(nestedBlock, "rules")
+		upgradeReplicationRules(nestedBlock)
 		for _, rulesBlock := range rulesBlocks {
-			// Rename a `rules` block to a `rule` block
-			rulesBlock.SetType("rule")
 			newResource.AppendNestedBlock(rulesBlock)
-
-			// Map a `delete_marker_replication_status` attribute to a `delete_marker_replication` block
-			// delete_marker_replication_status = "Enabled"
-			// =>
-			// delete_marker_replication {
-			//   status = "Enabled"
-			// }
-			deleteMarkerAttr := rulesBlock.GetAttribute("delete_marker_replication_status")
-			if deleteMarkerAttr != nil {
-				deleteMarkerBlock := tfwrite.NewEmptyNestedBlock("delete_marker_replication")
-				rulesBlock.AppendNestedBlock(deleteMarkerBlock)
-				deleteMarkerBlock.SetAttributeRaw("status", deleteMarkerAttr.ValueAsTokens())
-				rulesBlock.RemoveAttribute("delete_marker_replication_status")
-			}
-
-			destinationBlocks := rulesBlock.FindNestedBlocksByType("destination")
-			for _, destinationBlock := range destinationBlocks {
-				// Map a `replication_time.minutes` attribute to a `replication_time.time.minutes` attribute
-				// replication_time {
-				//   status  = "Enabled"
-				//   minutes = 15
-				// }
-				// =>
-				// replication_time {
-				//   status = "Enabled"
-				//   time {
-				//     minutes = 15
-				//   }
-				// }
-				replicationTimeBlocks := destinationBlock.FindNestedBlocksByType("replication_time")
-				for _, replicationTimeBlock := range replicationTimeBlocks {
-					minutesAttribute := replicationTimeBlock.GetAttribute("minutes")
-					if minutesAttribute != nil {
-						timeBlock := tfwrite.NewEmptyNestedBlock("time")
-						replicationTimeBlock.AppendNestedBlock(timeBlock)
-						timeBlock.SetAttributeRaw("minutes", minutesAttribute.ValueAsTokens())
-						replicationTimeBlock.RemoveAttribute("minutes")
-					}
-				}
-
-				// Map a `metrics.minutes` attribute to a `metrics.event_threshold.minutes` attribute
-				// metrics {
-				//   status  = "Enabled"
-				//   minutes = 15
-				// }
-				// =>
-				// metrics {
-				//   status = "Enabled"
-				//   event_threshold {
-				//     minutes = 15
-				//   }
-				// }
-				metricsBlocks := destinationBlock.FindNestedBlocksByType("metrics")
-				for _, metricsBlock := range metricsBlocks {
-					minutesAttribute := metricsBlock.GetAttribute("minutes")
-					if minutesAttribute != nil {
-						eventThresholdBlock := tfwrite.NewEmptyNestedBlock("event_threshold")
-						metricsBlock.AppendNestedBlock(eventThresholdBlock)
-						eventThresholdBlock.SetAttributeRaw("minutes", minutesAttribute.ValueAsTokens())
-						metricsBlock.RemoveAttribute("minutes")
-					}
-				}
-			}
+		}
+		for _, dynamicBlock := range dynamicBlocks {
+			newResource.AppendNestedBlock(dynamicBlock)
 		}
 
 		resource.RemoveNestedBlock(nestedBlock)
@@ -106,3 +65,87 @@ func AWSS3BucketReplicationConfigurationResourceFilter(inFile *tfwrite.File, res
 
 	return inFile, nil
 }
+
+// upgradeReplicationRules converts `rules` blocks and `dynamic "rules"`
+// blocks in a given `replication_configuration` block or a content block of a
+// `dynamic "replication_configuration"` block to `rule` blocks in place.
+func upgradeReplicationRules(nestedBlock tfwrite.Block) {
+	for _, rulesBlock := range nestedBlock.FindNestedBlocksByType("rules") {
+		// Rename a `rules` block to a `rule` block
+		rulesBlock.SetType("rule")
+		upgradeReplicationRule(rulesBlock)
+	}
+
+	for _, dynamicBlock := range findDynamicBlocks(nestedBlock, "rules") {
+		// Rename a `dynamic "rules"` block to a `dynamic "rule"` block
+		dynamicBlock.SetLabel("rule")
+		upgradeReplicationRule(dynamicBlock.Content())
+	}
+}
+
+// upgradeReplicationRule converts arguments of a given `rules` block or a
+// content block of a `dynamic "rules"` block in place.
+func upgradeReplicationRule(rulesBlock tfwrite.Block) {
+	// Map a `delete_marker_replication_status` attribute to a `delete_marker_replication` block
+	// delete_marker_replication_status = "Enabled"
+	// =>
+	// delete_marker_replication {
+	//   status = "Enabled"
+	// }
+	deleteMarkerAttr := rulesBlock.GetAttribute("delete_marker_replication_status")
+	if deleteMarkerAttr != nil {
+		deleteMarkerBlock := tfwrite.NewEmptyNestedBlock("delete_marker_replication")
+		rulesBlock.AppendNestedBlock(deleteMarkerBlock)
+		deleteMarkerBlock.SetAttributeRaw("status", deleteMarkerAttr.ValueAsTokens())
+		rulesBlock.RemoveAttribute("delete_marker_replication_status")
+	}
+
+	destinationBlocks := findNestedBlockBodies(rulesBlock, "destination")
+	for _, destinationBlock := range destinationBlocks {
+		// Map a `replication_time.minutes` attribute to a `replication_time.time.minutes` attribute
+		// replication_time {
+		//   status  = "Enabled"
+		//   minutes = 15
+		// }
+		// =>
+		// replication_time {
+		//   status = "Enabled"
+		//   time {
+		//     minutes = 15
+		//   }
+		// }
+		replicationTimeBlocks := findNestedBlockBodies(destinationBlock, "replication_time")
+		for _, replicationTimeBlock := range replicationTimeBlocks {
+			minutesAttribute := replicationTimeBlock.GetAttribute("minutes")
+			if minutesAttribute != nil {
+				timeBlock := tfwrite.NewEmptyNestedBlock("time")
+				replicationTimeBlock.AppendNestedBlock(timeBlock)
+				timeBlock.SetAttributeRaw("minutes", minutesAttribute.ValueAsTokens())
+				replicationTimeBlock.RemoveAttribute("minutes")
+			}
+		}
+
+		// Map a `metrics.minutes` attribute to a `metrics.event_threshold.minutes` attribute
+		// metrics {
+		//   status  = "Enabled"
+		//   minutes = 15
+		// }
+		// =>
+		// metrics {
+		//   status = "Enabled"
+		//   event_threshold {
+		//     minutes = 15
+		//   }
+		// }
+		metricsBlocks := findNestedBlockBodies(destinationBlock, "metrics")
+		for _, metricsBlock := range metricsBlocks {
+			minutesAttribute := metricsBlock.GetAttribute("minutes")
+			if minutesAttribute != nil {
+				eventThresholdBlock := tfwrite.NewEmptyNestedBlock("event_threshold")
+				metricsBlock.AppendNestedBlock(eventThresholdBlock)
+				eventThresholdBlock.SetAttributeRaw("minutes", minutesAttribute.ValueAsTokens())
+				metricsBlock.RemoveAttribute("minutes")
+			}
+		}
+	}
+}
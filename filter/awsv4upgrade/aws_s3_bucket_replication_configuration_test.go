@@ -108,6 +108,119 @@ resource "aws_s3_bucket_replication_configuration" "example" {
     }
   }
 }
+`,
+		},
+		{
+			name: "dynamic rules",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  replication_configuration {
+    role = aws_iam_role.replication.arn
+
+    dynamic "rules" {
+      for_each = var.replication_rules
+      content {
+        id     = rules.value.id
+        status = "Enabled"
+        delete_marker_replication_status = "Enabled"
+
+        destination {
+          bucket = rules.value.destination_bucket
+
+          metrics {
+            status  = "Enabled"
+            minutes = 15
+          }
+        }
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_replication_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+  role   = aws_iam_role.replication.arn
+
+  dynamic "rule" {
+    for_each = var.replication_rules
+    content {
+      id     = rule.value.id
+      status = "Enabled"
+
+      destination {
+        bucket = rule.value.destination_bucket
+
+        metrics {
+          status = "Enabled"
+
+          event_threshold {
+            minutes = 15
+          }
+        }
+      }
+
+      delete_marker_replication {
+        status = "Enabled"
+      }
+    }
+  }
+}
+`,
+		},
+		{
+			name: "dynamic replication_configuration",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "replication_configuration" {
+    for_each = var.replication
+    content {
+      role = replication_configuration.value.role
+
+      rules {
+        id     = "foobar"
+        status = "Enabled"
+
+        destination {
+          bucket = replication_configuration.value.destination_bucket
+        }
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_replication_configuration" "example" {
+  count  = length(var.replication) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  role = one(var.replication).role
+
+  rule {
+    id     = "foobar"
+    status = "Enabled"
+
+    destination {
+      bucket = one(var.replication).destination_bucket
+    }
+  }
+}
 `,
 		},
 		{
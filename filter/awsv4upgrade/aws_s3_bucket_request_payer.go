@@ -22,7 +22,7 @@ func AWSS3BucketRequestPayerResourceFilter(inFile *tfwrite.File, resource *tfwri
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
 	setParentBucket(newResource, resource)
 
 	// Map an `request_payer` attribute to an `payer` attribute.
@@ -17,13 +17,30 @@ func AWSS3BucketServerSideEncryptionConfigurationResourceFilter(inFile *tfwrite.
 	newResourceType := "aws_s3_bucket_server_side_encryption_configuration"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+
+	if len(nestedBlocks) == 0 {
+		// Unwrap the content of a dynamic block in the same way as a static one.
+		// The rule blocks in it are moved as they are.
+		dynamicBlock := dynamicBlocks[0]
+		if err := setDynamicCount(newResource, resource, dynamicBlocks[:1]); err != nil {
+			return nil, err
+		}
+		setParentBucket(newResource, resource)
+		if err := appendUnwrappedDynamicContent(newResource, dynamicBlock); err != nil {
+			return nil, err
+		}
+		resource.RemoveNestedBlock(dynamicBlock)
+		return inFile, nil
+	}
+
 	setParentBucket(newResource, resource)
 	newResource.AppendUnwrappedNestedBlockBody(nestedBlocks[0])
 	resource.RemoveNestedBlock(nestedBlocks[0])
@@ -47,6 +47,46 @@ resource "aws_s3_bucket_server_side_encryption_configuration" "example" {
     }
   }
 }
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "server_side_encryption_configuration" {
+    for_each = var.kms_key_id == null ? [] : [var.kms_key_id]
+    iterator = key
+    content {
+      rule {
+        apply_server_side_encryption_by_default {
+          kms_master_key_id = key.value
+          sse_algorithm     = "aws:kms"
+        }
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "example" {
+  count  = length(var.kms_key_id == null ? [] : [var.kms_key_id]) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      kms_master_key_id = one(var.kms_key_id == null ? [] : [var.kms_key_id])
+      sse_algorithm     = "aws:kms"
+    }
+  }
+}
 `,
 		},
 		{
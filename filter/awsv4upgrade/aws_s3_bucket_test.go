@@ -12,8 +12,8 @@ import (
 func buildTestResourceFilter(f tfeditor.ResourceFilterFunc) editor.Filter {
 	return tfeditor.NewFileFilter(
 		&AWSS3BucketFilter{
-			filters: []tfeditor.BlockFilter{
-				tfeditor.ResourceFilterFunc(f),
+			filters: []splitFilter{
+				{filter: tfeditor.ResourceFilterFunc(f)},
 			},
 		},
 	)
@@ -23,8 +23,8 @@ func buildTestResourceFilter(f tfeditor.ResourceFilterFunc) editor.Filter {
 func buildTestBlockFilter(f tfeditor.BlockFilterFunc) editor.Filter {
 	return tfeditor.NewFileFilter(
 		&AWSS3BucketFilter{
-			filters: []tfeditor.BlockFilter{
-				tfeditor.BlockFilterFunc(f),
+			filters: []splitFilter{
+				{filter: tfeditor.BlockFilterFunc(f)},
 			},
 		},
 	)
@@ -32,10 +32,11 @@ func buildTestBlockFilter(f tfeditor.BlockFilterFunc) editor.Filter {
 
 func TestAWSS3BucketFilter(t *testing.T) {
 	cases := []struct {
-		name string
-		src  string
-		ok   bool
-		want string
+		name     string
+		src      string
+		ok       bool
+		want     string
+		warnings []string
 	}{
 		{
 			name: "simple",
@@ -118,10 +119,6 @@ resource "aws_s3_bucket" "example1" {
   bucket = "tfedit-test1"
 }
 
-resource "aws_s3_bucket" "example2" {
-  bucket = "tfedit-test2"
-}
-
 resource "aws_s3_bucket_acl" "example1" {
   bucket = aws_s3_bucket.example1.id
   acl    = "private"
@@ -134,6 +131,10 @@ resource "aws_s3_bucket_logging" "example1" {
   target_prefix = "log/"
 }
 
+resource "aws_s3_bucket" "example2" {
+  bucket = "tfedit-test2"
+}
+
 resource "aws_s3_bucket_acl" "example2" {
   bucket = aws_s3_bucket.example2.id
   acl    = "private"
@@ -351,6 +352,12 @@ resource "aws_s3_bucket" "log" {
   bucket = "tfedit-log"
 }
 
+resource "aws_s3_bucket_acl" "log" {
+  bucket = aws_s3_bucket.log.id
+  # You must give the log-delivery group WRITE and READ_ACP permissions to the target bucket
+  acl = "log-delivery-write"
+}
+
 resource "aws_s3_bucket" "destination" {
   bucket = "tfedit-destination"
 }
@@ -361,12 +368,6 @@ resource "aws_s3_bucket" "example" {
   object_lock_enabled = true
 }
 
-resource "aws_s3_bucket_acl" "log" {
-  bucket = aws_s3_bucket.log.id
-  # You must give the log-delivery group WRITE and READ_ACP permissions to the target bucket
-  acl = "log-delivery-write"
-}
-
 resource "aws_s3_bucket_accelerate_configuration" "example" {
   bucket = aws_s3_bucket.example.id
   status = "Enabled"
@@ -676,13 +677,189 @@ resource "aws_s3_bucket_acl" "example" {
   bucket   = aws_s3_bucket.example.id
   acl      = "private"
 }
+`,
+		},
+		{
+			name: "depends_on",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket     = "tfedit-test"
+  acl        = "private"
+  depends_on = [aws_iam_role.example]
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket     = "tfedit-test"
+  depends_on = [aws_iam_role.example]
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  depends_on = [aws_iam_role.example]
+  bucket     = aws_s3_bucket.example.id
+  acl        = "private"
+}
+`,
+		},
+		{
+			name: "lifecycle",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  versioning {
+    enabled = true
+  }
+
+  lifecycle_rule {
+    id      = "log"
+    enabled = true
+  }
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [lifecycle_rule[0].enabled, lifecycle_rule[0].transition, tags, versioning[0].enabled]
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [lifecycle_rule[0].transition, tags]
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    id     = "log"
+    status = "Enabled"
+
+    filter {
+      prefix = ""
+    }
+  }
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [rule[0].status]
+  }
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [versioning_configuration[0].status]
+  }
+}
+`,
+			warnings: []string{
+				"lifecycle_rule[0].transition in ignore_changes of aws_s3_bucket.example was not moved to aws_s3_bucket_lifecycle_configuration.example because rule of aws_s3_bucket_lifecycle_configuration has a different shape. Please rewrite it by yourself.",
+			},
+		},
+		{
+			name: "lifecycle ignore_changes moved all",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  lifecycle_rule {
+    id      = "log"
+    enabled = true
+  }
+
+  lifecycle {
+    ignore_changes = [lifecycle_rule]
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    id     = "log"
+    status = "Enabled"
+
+    filter {
+      prefix = ""
+    }
+  }
+
+  lifecycle {
+    ignore_changes = [rule]
+  }
+}
+`,
+		},
+		{
+			name: "lifecycle ignore_changes = all",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  lifecycle {
+    create_before_destroy = true
+    ignore_changes        = all
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  lifecycle {
+    create_before_destroy = true
+    ignore_changes        = all
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+
+  lifecycle {
+    create_before_destroy = true
+    ignore_changes        = all
+  }
+}
 `,
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			filter := tfeditor.NewFileFilter(NewAWSS3BucketFilter())
+			recorder := NewSplitRecorder()
+			filter := tfeditor.NewFileFilter(NewAWSS3BucketFilterWithRecorder(recorder))
 			o := editor.NewEditOperator(filter)
 			output, err := o.Apply([]byte(tc.src), "test")
 			if tc.ok && err != nil {
@@ -697,6 +874,13 @@ resource "aws_s3_bucket_acl" "example" {
 			if diff := cmp.Diff(got, tc.want); diff != "" {
 				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
 			}
+
+			warnings := recorder.Warnings()
+			if len(warnings) != 0 || len(tc.warnings) != 0 {
+				if diff := cmp.Diff(warnings, tc.warnings); diff != "" {
+					t.Fatalf("got warnings:\n%#v\nwant:\n%#v\ndiff:\n%s", warnings, tc.warnings, diff)
+				}
+			}
 		})
 	}
 }
@@ -18,20 +18,50 @@ func AWSS3BucketVersioningResourceFilter(inFile *tfwrite.File, resource *tfwrite
 	newNestedBlock := "versioning_configuration"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+
+	if len(nestedBlocks) == 0 {
+		// Rename a `dynamic "versioning"` block to a `dynamic "versioning_configuration"` block
+		dynamicBlock := dynamicBlocks[0]
+		if err := setDynamicCount(newResource, resource, dynamicBlocks[:1]); err != nil {
+			return nil, err
+		}
+		setParentBucket(newResource, resource)
+		dynamicBlock.SetLabel(newNestedBlock)
+		if err := upgradeVersioning(dynamicBlock.Content()); err != nil {
+			return nil, err
+		}
+		newResource.AppendNestedBlock(dynamicBlock)
+		resource.RemoveNestedBlock(dynamicBlock)
+		return inFile, nil
+	}
+
 	setParentBucket(newResource, resource)
 
 	nestedBlock := nestedBlocks[0]
 
 	// Rename a `versioning` block to a `versioning_configuration` block
 	nestedBlock.SetType(newNestedBlock)
+	if err := upgradeVersioning(nestedBlock); err != nil {
+		return nil, err
+	}
 
+	newResource.AppendNestedBlock(nestedBlock)
+	resource.RemoveNestedBlock(nestedBlock)
+
+	return inFile, nil
+}
+
+// upgradeVersioning converts arguments of a given `versioning` block or a
+// content block of a `dynamic "versioning"` block in place.
+func upgradeVersioning(nestedBlock tfwrite.Block) error {
 	// Map an `enabled` attribute to a `status` attribute
 	// enabled = true => status = "Enabled"
 	// enabled = false => status = "Suspended"
@@ -45,9 +75,13 @@ func AWSS3BucketVersioningResourceFilter(inFile *tfwrite.File, resource *tfwrite
 			case "false":
 				nestedBlock.SetAttributeValue("status", cty.StringVal("Suspended"))
 			default:
-				// If the value is a variable, not literal, we cannot rewrite it automatically.
-				// Set original raw tokens as it is.
-				nestedBlock.SetAttributeRaw("status", enabledAttr.ValueAsTokens())
+				// If the value is a variable, not literal, map it with a conditional expression.
+				// enabled = var.enabled => status = var.enabled ? "Enabled" : "Suspended"
+				status, err := conditionalStatus(enabledAttr, "Enabled", "Suspended")
+				if err != nil {
+					return err
+				}
+				nestedBlock.SetAttributeRaw("status", status)
 			}
 		}
 		nestedBlock.RemoveAttribute("enabled")
@@ -67,15 +101,16 @@ func AWSS3BucketVersioningResourceFilter(inFile *tfwrite.File, resource *tfwrite
 			case "false":
 				nestedBlock.SetAttributeValue("mfa_delete", cty.StringVal("Disabled"))
 			default:
-				// If the value is a variable, not literal, we cannot rewrite it automatically.
-				// Set original raw tokens as it is.
-				nestedBlock.SetAttributeRaw("mfa_delete", mfaDeleteAttr.ValueAsTokens())
+				// If the value is a variable, not literal, map it with a conditional expression.
+				// mfa_delete = var.mfa_delete => mfa_delete = var.mfa_delete ? "Enabled" : "Disabled"
+				status, err := conditionalStatus(mfaDeleteAttr, "Enabled", "Disabled")
+				if err != nil {
+					return err
+				}
+				nestedBlock.SetAttributeRaw("mfa_delete", status)
 			}
 		}
 	}
 
-	newResource.AppendNestedBlock(nestedBlock)
-	resource.RemoveNestedBlock(nestedBlock)
-
-	return inFile, nil
+	return nil
 }
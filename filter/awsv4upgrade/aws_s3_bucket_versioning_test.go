@@ -90,7 +90,81 @@ resource "aws_s3_bucket_versioning" "example" {
   bucket = aws_s3_bucket.example.id
 
   versioning_configuration {
-    status = var.enabled
+    status = var.enabled ? "Enabled" : "Suspended"
+  }
+}
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "versioning" {
+    for_each = var.versioning_enabled ? [1] : []
+    content {
+      enabled    = true
+      mfa_delete = false
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  count  = length(var.versioning_enabled ? [1] : []) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  dynamic "versioning_configuration" {
+    for_each = var.versioning_enabled ? [1] : []
+    content {
+      mfa_delete = "Disabled"
+      status     = "Enabled"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "dynamic with count",
+			src: `
+resource "aws_s3_bucket" "example" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+
+  dynamic "versioning" {
+    for_each = var.versioning
+    content {
+      enabled = versioning.value.enabled
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  count  = 2
+  bucket = aws_s3_bucket.example[count.index].id
+
+  dynamic "versioning_configuration" {
+    for_each = var.versioning
+    content {
+      status = versioning_configuration.value.enabled ? "Enabled" : "Suspended"
+    }
   }
 }
 `,
@@ -167,6 +241,35 @@ resource "aws_s3_bucket_versioning" "example" {
     status     = "Enabled"
   }
 }
+`,
+		},
+		{
+			name: "mfa_delete = var.mfa_delete",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  versioning {
+    enabled    = var.enabled && var.versioning
+    mfa_delete = var.mfa_delete
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  versioning_configuration {
+    mfa_delete = var.mfa_delete ? "Enabled" : "Disabled"
+    status     = (var.enabled && var.versioning) ? "Enabled" : "Suspended"
+  }
+}
 `,
 		},
 	}
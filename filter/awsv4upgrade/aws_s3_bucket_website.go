@@ -29,17 +29,46 @@ func awsS3BucketWebsiteResourceFilter(inFile *tfwrite.File, resource *tfwrite.Re
 	newResourceType := "aws_s3_bucket_website_configuration"
 
 	nestedBlocks := resource.FindNestedBlocksByType(oldNestedBlock)
-	if len(nestedBlocks) == 0 {
+	dynamicBlocks := findDynamicBlocks(resource, oldNestedBlock)
+	if len(nestedBlocks) == 0 && len(dynamicBlocks) == 0 {
 		return inFile, nil
 	}
 
 	resourceName := resource.Name()
 	newResource := tfwrite.NewEmptyResource(newResourceType, resourceName)
-	inFile.AppendBlock(newResource)
+	insertSplit(inFile, resource, newResource)
+
+	if len(nestedBlocks) == 0 {
+		// Convert the content of a dynamic block in place and unwrap it.
+		dynamicBlock := dynamicBlocks[0]
+		if err := setDynamicCount(newResource, resource, dynamicBlocks[:1]); err != nil {
+			return nil, err
+		}
+		setParentBucket(newResource, resource)
+		content := dynamicBlock.Content()
+		upgradeWebsite(content, content)
+		if err := appendUnwrappedDynamicContent(newResource, dynamicBlock); err != nil {
+			return nil, err
+		}
+		resource.RemoveNestedBlock(dynamicBlock)
+		return inFile, nil
+	}
+
 	setParentBucket(newResource, resource)
 
 	websiteBlock := nestedBlocks[0]
+	upgradeWebsite(newResource, websiteBlock)
 
+	newResource.AppendUnwrappedNestedBlockBody(websiteBlock)
+	resource.RemoveNestedBlock(websiteBlock)
+
+	return inFile, nil
+}
+
+// upgradeWebsite converts attributes of a given `website` block or a content
+// block of a `dynamic "website"` block to nested blocks, and appends them to
+// a given target block.
+func upgradeWebsite(target tfwrite.Block, websiteBlock tfwrite.Block) {
 	// Map an `index_document` attribute to an `index_document` block
 	// index_document = "index.html"
 	// =>
@@ -49,7 +78,7 @@ func awsS3BucketWebsiteResourceFilter(inFile *tfwrite.File, resource *tfwrite.Re
 	indexDocumentAttr := websiteBlock.GetAttribute("index_document")
 	if indexDocumentAttr != nil {
 		indexDocumentBlock := tfwrite.NewEmptyNestedBlock("index_document")
-		newResource.AppendNestedBlock(indexDocumentBlock)
+		target.AppendNestedBlock(indexDocumentBlock)
 		suffix := indexDocumentAttr.ValueAsTokens()
 		indexDocumentBlock.SetAttributeRaw("suffix", suffix)
 		websiteBlock.RemoveAttribute("index_document")
@@ -64,16 +93,11 @@ func awsS3BucketWebsiteResourceFilter(inFile *tfwrite.File, resource *tfwrite.Re
 	errorDocumentAttr := websiteBlock.GetAttribute("error_document")
 	if errorDocumentAttr != nil {
 		errorDocumentBlock := tfwrite.NewEmptyNestedBlock("error_document")
-		newResource.AppendNestedBlock(errorDocumentBlock)
+		target.AppendNestedBlock(errorDocumentBlock)
 		key := errorDocumentAttr.ValueAsTokens()
 		errorDocumentBlock.SetAttributeRaw("key", key)
 		websiteBlock.RemoveAttribute("error_document")
 	}
-
-	newResource.AppendUnwrappedNestedBlockBody(websiteBlock)
-	resource.RemoveNestedBlock(websiteBlock)
-
-	return inFile, nil
 }
 
 var regexWebsiteDomain = regexp.MustCompile(`aws_s3_bucket\.(.+)\.website_domain`)
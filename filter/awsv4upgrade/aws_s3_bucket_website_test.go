@@ -45,6 +45,42 @@ resource "aws_s3_bucket_website_configuration" "example" {
   }
 
 }
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "website" {
+    for_each = var.website
+    content {
+      index_document = website.value.index_document
+      error_document = "error.html"
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+}
+
+resource "aws_s3_bucket_website_configuration" "example" {
+  count  = length(var.website) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.example.id
+
+  index_document {
+    suffix = one(var.website).index_document
+  }
+
+  error_document {
+    key = "error.html"
+  }
+}
 `,
 		},
 		{
@@ -0,0 +1,139 @@
+package awsv4upgrade
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// canonicalUserIDPlaceholder is a placeholder for the owner of
+	// aws_s3_bucket_acl, which needs to be set by hand.
+	canonicalUserIDPlaceholder = "set_aws_canonical_user_id"
+
+	// canonicalUserIDDataSourceType is a type of data source for the canonical
+	// user ID of the current AWS account.
+	canonicalUserIDDataSourceType = "aws_canonical_user_id"
+
+	// canonicalUserIDDataSourceName is a name of data source inserted by the
+	// filter.
+	canonicalUserIDDataSourceName = "current"
+)
+
+// canonicalUserID sets the owner of aws_s3_bucket_acl split from grant
+// arguments. A grant argument of aws_s3_bucket in v3 doesn’t have an owner,
+// but an access_control_policy argument of aws_s3_bucket_acl in v4 requires
+// it. By default, it sets a placeholder which needs to be fixed by hand.
+// If a literal ID is given, it's used as it is. If useDataSource is true, it
+// refers to an aws_canonical_user_id data source, which is inserted once per
+// module (directory) if not found.
+type canonicalUserID struct {
+	// A literal canonical user ID. If not empty, it takes precedence.
+	literal string
+	// Refer to an aws_canonical_user_id data source.
+	useDataSource bool
+	// Names of aws_canonical_user_id data sources indexed by module dir.
+	// It contains both existing and inserted ones.
+	dataSources map[string]string
+	// A module dir of the file being filtered.
+	dir string
+}
+
+// newCanonicalUserID returns a new instance of canonicalUserID.
+func newCanonicalUserID(literal string, useDataSource bool) *canonicalUserID {
+	return &canonicalUserID{
+		literal:       literal,
+		useDataSource: useDataSource,
+		dataSources:   map[string]string{},
+	}
+}
+
+// prepare finds existing aws_canonical_user_id data sources in given files
+// indexed by name, so that we can reuse them in any file of the module.
+// Files which cannot be parsed are ignored here, and reported later when
+// filtering them.
+func (c *canonicalUserID) prepare(files map[string][]byte) {
+	if c == nil || !c.useDataSource {
+		return
+	}
+
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		f, diags := hclwrite.ParseConfig(files[filename], filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		dir := filepath.Dir(filename)
+		if _, ok := c.dataSources[dir]; ok {
+			continue
+		}
+		if name := findCanonicalUserIDDataSource(tfwrite.NewFile(f)); name != "" {
+			c.dataSources[dir] = name
+		}
+	}
+}
+
+// setFilename sets a name of the file being filtered.
+func (c *canonicalUserID) setFilename(filename string) {
+	if c == nil {
+		return
+	}
+	c.dir = filepath.Dir(filename)
+}
+
+// ownerID returns tokens for an id attribute of an owner block.
+// If a data source is needed but not found in the module, it's appended to a
+// given file.
+func (c *canonicalUserID) ownerID(inFile *tfwrite.File) hclwrite.Tokens {
+	switch {
+	case c == nil:
+		return hclwrite.TokensForValue(cty.StringVal(canonicalUserIDPlaceholder))
+	case c.literal != "":
+		return hclwrite.TokensForValue(cty.StringVal(c.literal))
+	case !c.useDataSource:
+		return hclwrite.TokensForValue(cty.StringVal(canonicalUserIDPlaceholder))
+	}
+
+	name, ok := c.dataSources[c.dir]
+	if !ok {
+		// Even if Prepare is not called, such as reading from stdin, reuse an
+		// existing one in the same file.
+		name = findCanonicalUserIDDataSource(inFile)
+		if name == "" {
+			name = canonicalUserIDDataSourceName
+			inFile.AppendBlock(tfwrite.NewEmptyDataSource(canonicalUserIDDataSourceType, name))
+		}
+		c.dataSources[c.dir] = name
+	}
+
+	return hclwrite.TokensForTraversal(hcl.Traversal{
+		hcl.TraverseRoot{Name: "data"},
+		hcl.TraverseAttr{Name: canonicalUserIDDataSourceType},
+		hcl.TraverseAttr{Name: name},
+		hcl.TraverseAttr{Name: "id"},
+	})
+}
+
+// findCanonicalUserIDDataSource returns a name of an aws_canonical_user_id
+// data source in a given file. Data sources with count or for_each are
+// ignored because they cannot be referenced without an index.
+// It returns an empty string if not found.
+func findCanonicalUserIDDataSource(f *tfwrite.File) string {
+	for _, block := range f.FindBlocksByType("data", canonicalUserIDDataSourceType) {
+		dataSource, ok := block.(*tfwrite.DataSource)
+		if !ok || dataSource.Count() != nil || dataSource.ForEach() != nil {
+			continue
+		}
+		return dataSource.Name()
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+package awsv4upgrade
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/tfedit/tfeditor"
+)
+
+func TestAllFilterCanonicalUserIDDataSource(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		ok    bool
+		want  map[string]string
+	}{
+		{
+			name: "reuse one in another file of the same module",
+			files: map[string]string{
+				"foo/data.tf": `
+data "aws_canonical_user_id" "current_user" {}
+`,
+				"foo/main.tf": `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+			},
+			ok: true,
+			want: map[string]string{
+				"foo/data.tf": `
+data "aws_canonical_user_id" "current_user" {}
+`,
+				"foo/main.tf": `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current_user.id
+    }
+  }
+}
+`,
+			},
+		},
+		{
+			name: "insert once per module",
+			files: map[string]string{
+				"foo/a.tf": `
+resource "aws_s3_bucket" "a" {
+  bucket = "tfedit-test-a"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+				"foo/b.tf": `
+resource "aws_s3_bucket" "b" {
+  bucket = "tfedit-test-b"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+				"bar/c.tf": `
+resource "aws_s3_bucket" "c" {
+  bucket = "tfedit-test-c"
+
+  grant {
+    type        = "Group"
+    permissions = ["WRITE"]
+    uri         = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+  }
+}
+`,
+			},
+			ok: true,
+			want: map[string]string{
+				"foo/a.tf": `
+resource "aws_s3_bucket" "a" {
+  bucket = "tfedit-test-a"
+}
+
+resource "aws_s3_bucket_acl" "a" {
+  bucket = aws_s3_bucket.a.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current.id
+    }
+  }
+}
+
+data "aws_canonical_user_id" "current" {
+}
+`,
+				"foo/b.tf": `
+resource "aws_s3_bucket" "b" {
+  bucket = "tfedit-test-b"
+}
+
+resource "aws_s3_bucket_acl" "b" {
+  bucket = aws_s3_bucket.b.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current.id
+    }
+  }
+}
+`,
+				"bar/c.tf": `
+resource "aws_s3_bucket" "c" {
+  bucket = "tfedit-test-c"
+}
+
+resource "aws_s3_bucket_acl" "c" {
+  bucket = aws_s3_bucket.c.id
+
+  access_control_policy {
+
+    grant {
+
+      grantee {
+
+        type = "Group"
+        uri  = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+      }
+      permission = "WRITE"
+    }
+
+    owner {
+      id = data.aws_canonical_user_id.current.id
+    }
+  }
+}
+
+data "aws_canonical_user_id" "current" {
+}
+`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string][]byte{}
+			for filename, src := range tc.files {
+				files[filename] = []byte(src)
+			}
+			fs := tfeditor.NewMemoryFileSystem(files)
+			c := tfeditor.NewClient(&tfeditor.Option{FileSystem: fs})
+
+			filter := NewAllFilterWithOption(&Option{CanonicalUserIDDataSource: true})
+			err := c.EditFiles(fs.Names(), filter)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error")
+			}
+
+			for filename, want := range tc.want {
+				got, err := fs.ReadFile(filename)
+				if err != nil {
+					t.Fatalf("failed to read file: %s", err)
+				}
+				if diff := cmp.Diff(string(got), want); diff != "" {
+					t.Errorf("%s: got:\n%s\nwant:\n%s\ndiff:\n%s", filename, got, want, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package awsv4upgrade
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+)
+
+// findDynamicBlocks returns all dynamic blocks in a given block which
+// generate nested blocks of a given type.
+// A dynamic block without a content block is invalid, so we ignore it and
+// leave it as it is.
+func findDynamicBlocks(block tfwrite.Block, label string) []*tfwrite.DynamicBlock {
+	ret := []*tfwrite.DynamicBlock{}
+	for _, dynamicBlock := range block.FindNestedDynamicBlocksByLabel(label) {
+		if dynamicBlock.Content() != nil {
+			ret = append(ret, dynamicBlock)
+		}
+	}
+	return ret
+}
+
+// findNestedBlockBodies returns all nested blocks of a given type and content
+// blocks of dynamic blocks which generate them, so that we can apply the same
+// conversion to both of them.
+func findNestedBlockBodies(block tfwrite.Block, blockType string) []tfwrite.Block {
+	ret := block.FindNestedBlocksByType(blockType)
+	for _, dynamicBlock := range findDynamicBlocks(block, blockType) {
+		ret = append(ret, dynamicBlock.Content())
+	}
+	return ret
+}
+
+// setDynamicCount sets a count meta argument to a new resource split only
+// from given dynamic blocks. The dynamic blocks may generate no nested block,
+// but the new resource requires at least one, so we create it only if the
+// for_each arguments are not empty:
+// count = length(var.foo) > 0 ? 1 : 0
+// If the original resource already has count or for_each, we cannot combine
+// them, so do nothing.
+func setDynamicCount(newResource *tfwrite.Resource, oldResource *tfwrite.Resource, dynamicBlocks []*tfwrite.DynamicBlock) error {
+	if oldResource.Count() != nil || oldResource.ForEach() != nil {
+		return nil
+	}
+
+	lengths := []string{}
+	for _, dynamicBlock := range dynamicBlocks {
+		lengths = append(lengths, fmt.Sprintf("length(%s)", forEachAsString(dynamicBlock)))
+	}
+	if len(lengths) == 0 {
+		return nil
+	}
+
+	tokens, err := tfwrite.ParseExpressionAsTokens(strings.Join(lengths, " + ") + " > 0 ? 1 : 0")
+	if err != nil {
+		return fmt.Errorf("failed to set count to %s.%s: %s", newResource.SchemaType(), newResource.Name(), err)
+	}
+	newResource.SetAttributeRaw("count", tokens)
+	return nil
+}
+
+// appendUnwrappedDynamicContent appends a body of a content block of a given
+// dynamic block to a new resource. It's the same as the
+// AppendUnwrappedNestedBlockBody for a dynamic block which generates at most
+// one nested block, but references to the iterator are replaced with the
+// element of the for_each argument.
+// logging.value.target_bucket => one(var.logging).target_bucket
+// Note that the for_each argument is assumed to be a list or set.
+func appendUnwrappedDynamicContent(newResource *tfwrite.Resource, dynamicBlock *tfwrite.DynamicBlock) error {
+	value, err := tfwrite.ParseExpressionAsTokens(fmt.Sprintf("one(%s)", forEachAsString(dynamicBlock)))
+	if err != nil {
+		return fmt.Errorf("failed to unwrap dynamic %s: %s", dynamicBlock.Label(), err)
+	}
+
+	unwrapped := buildContentTokens(dynamicBlock.Content())
+	replaced := tfwrite.ReplaceReferenceAsTokens(unwrapped, dynamicBlock.Iterator()+".value", value)
+	newResource.Raw().Body().AppendUnstructuredTokens(replaced)
+	return nil
+}
+
+// buildContentTokens returns tokens of a body of a given content block whose
+// leading newlines are collapsed into one, so that appending them to another
+// body leaves a single blank line as the same as a static nested block.
+func buildContentTokens(content tfwrite.Block) hclwrite.Tokens {
+	tokens := content.Raw().Body().BuildTokens(nil)
+	for len(tokens) > 1 && tokens[0].Type == hclsyntax.TokenNewline && tokens[1].Type == hclsyntax.TokenNewline {
+		tokens = tokens[1:]
+	}
+	return tokens
+}
+
+// forEachAsString returns a for_each argument of a given dynamic block as a
+// string.
+func forEachAsString(dynamicBlock *tfwrite.DynamicBlock) string {
+	forEach := dynamicBlock.ForEach()
+	if forEach == nil {
+		return "[]"
+	}
+	return strings.TrimSpace(string(forEach.ValueAsTokens().Bytes()))
+}
@@ -0,0 +1,176 @@
+package awsv4upgrade
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+)
+
+// existingSplit is a split resource which already exists in the
+// configuration, such as a partially upgraded module.
+type existingSplit struct {
+	// A type of the split resource. (e.g. aws_s3_bucket_acl)
+	resourceType string
+	// A name of the split resource.
+	resourceName string
+	// A name of the aws_s3_bucket resource referred by the bucket argument.
+	bucketName string
+}
+
+// address returns a resource address of the split resource.
+func (s existingSplit) address() string {
+	return s.resourceType + "." + s.resourceName
+}
+
+// isSplitResourceType returns true if a given resource type is created from
+// aws_s3_bucket, which includes aws_s3_bucket_ownership_controls generated
+// for aws_s3_bucket_acl.
+func isSplitResourceType(resourceType string) bool {
+	if _, ok := splitArguments[resourceType]; ok {
+		return true
+	}
+	return resourceType == "aws_s3_bucket_ownership_controls"
+}
+
+// findExistingSplits returns split resources in a given file.
+// A resource is considered as a split resource if its type is one of split
+// resource types and its bucket argument refers to an aws_s3_bucket resource.
+func findExistingSplits(f *tfwrite.File) []existingSplit {
+	ret := []existingSplit{}
+	for _, block := range f.FindBlocksByType("resource", "") {
+		resource, ok := block.(*tfwrite.Resource)
+		if !ok {
+			continue
+		}
+		if !isSplitResourceType(resource.SchemaType()) {
+			continue
+		}
+		bucketName, ok := referredBucketName(resource)
+		if !ok {
+			continue
+		}
+		ret = append(ret, existingSplit{
+			resourceType: resource.SchemaType(),
+			resourceName: resource.Name(),
+			bucketName:   bucketName,
+		})
+	}
+	return ret
+}
+
+// referredBucketName returns a name of the aws_s3_bucket resource referred by
+// the bucket argument of a given resource.
+func referredBucketName(resource *tfwrite.Resource) (string, bool) {
+	bucketAttr := resource.GetAttribute("bucket")
+	if bucketAttr == nil {
+		return "", false
+	}
+	for _, ref := range bucketAttr.References() {
+		if !strings.HasPrefix(ref, "aws_s3_bucket.") {
+			continue
+		}
+		// aws_s3_bucket.example.id or aws_s3_bucket.example[count.index].id
+		name := strings.FieldsFunc(strings.TrimPrefix(ref, "aws_s3_bucket."), func(r rune) bool {
+			return r == '.' || r == '['
+		})
+		if len(name) > 0 {
+			return name[0], true
+		}
+	}
+	return "", false
+}
+
+// hasExistingSplit returns true if given existing split resources contain a
+// resource of a given type for a given bucket.
+func hasExistingSplit(splits []existingSplit, bucket *tfwrite.Resource, resourceType string) bool {
+	for _, split := range splits {
+		if split.resourceType == resourceType && split.bucketName == bucket.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+// existingSplits finds split resources which already exist in the module, so
+// that we don't append a duplicate resource for the same bucket.
+type existingSplits struct {
+	// Existing split resources indexed by module dir.
+	splits map[string][]existingSplit
+	// A module dir of the file being filtered.
+	dir string
+}
+
+// newExistingSplits returns a new instance of existingSplits.
+func newExistingSplits() *existingSplits {
+	return &existingSplits{
+		splits: map[string][]existingSplit{},
+		dir:    filepath.Dir(""),
+	}
+}
+
+// prepare finds existing split resources in given files.
+// Files which cannot be parsed are ignored here, and reported later when
+// filtering them.
+func (s *existingSplits) prepare(files map[string][]byte) {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		f, diags := hclwrite.ParseConfig(files[filename], filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		dir := filepath.Dir(filename)
+		s.splits[dir] = append(s.splits[dir], findExistingSplits(tfwrite.NewFile(f))...)
+	}
+}
+
+// setFilename sets a name of the file being filtered.
+func (s *existingSplits) setFilename(filename string) {
+	s.dir = filepath.Dir(filename)
+}
+
+// find returns an address of an existing split resource of a given type
+// which refers to a given bucket. It returns an empty string if not found.
+// The file being filtered is always searched, so that it works without
+// prepare. If s is nil, only the file being filtered is searched.
+func (s *existingSplits) find(inFile *tfwrite.File, bucket *tfwrite.Resource, resourceType string) string {
+	splits := findExistingSplits(inFile)
+	if s != nil {
+		splits = append(splits, s.splits[s.dir]...)
+	}
+
+	for _, split := range splits {
+		if split.resourceType == resourceType && split.bucketName == bucket.Name() {
+			return split.address()
+		}
+	}
+	return ""
+}
+
+// hasSplitArguments returns true if a given bucket has arguments which are
+// split to a given resource type.
+func hasSplitArguments(bucket *tfwrite.Resource, resourceType string) bool {
+	for oldArg := range splitArguments[resourceType] {
+		if bucket.GetAttribute(oldArg) != nil ||
+			len(bucket.FindNestedBlocksByType(oldArg)) > 0 ||
+			len(findDynamicBlocks(bucket, oldArg)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// skippedSplitWarning returns a warning message for arguments which are not
+// split because a split resource already exists.
+func skippedSplitWarning(bucket *tfwrite.Resource, address string) string {
+	return fmt.Sprintf("aws_s3_bucket.%s was not split to %s because it already exists. Please merge the arguments left in aws_s3_bucket.%s by yourself.", bucket.Name(), address, bucket.Name())
+}
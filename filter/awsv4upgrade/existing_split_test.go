@@ -0,0 +1,322 @@
+package awsv4upgrade
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/tfeditor"
+)
+
+func TestAllFilterExistingSplits(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		ok       bool
+		want     string
+		warnings []string
+	}{
+		{
+			name: "partially upgraded",
+			src: `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  versioning {
+    enabled = true
+  }
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+`,
+			ok: true,
+			want: `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  versioning {
+    enabled = true
+  }
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.example was not split to aws_s3_bucket_versioning.example because it already exists. Please merge the arguments left in aws_s3_bucket.example by yourself.",
+			},
+		},
+		{
+			name: "existing split with a different name",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+
+resource "aws_s3_bucket_acl" "private" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+
+resource "aws_s3_bucket_acl" "private" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.example was not split to aws_s3_bucket_acl.private because it already exists. Please merge the arguments left in aws_s3_bucket.example by yourself.",
+			},
+		},
+		{
+			name: "existing split for another bucket",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  policy = "{}"
+}
+
+resource "aws_s3_bucket_policy" "other" {
+  bucket = aws_s3_bucket.other.id
+  policy = "{}"
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_policy" "example" {
+  bucket = aws_s3_bucket.example.id
+  policy = "{}"
+}
+
+resource "aws_s3_bucket_policy" "other" {
+  bucket = aws_s3_bucket.other.id
+  policy = "{}"
+}
+`,
+			warnings: []string{},
+		},
+		{
+			name: "existing split without arguments to split",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`,
+			ok: true,
+			want: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`,
+			warnings: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := NewSplitRecorder()
+			o := editor.NewEditOperator(NewAllFilterWithOption(&Option{Recorder: recorder}))
+			output, err := o.Apply([]byte(tc.src), "test")
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, outStream: \n%s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+
+			if diff := cmp.Diff(recorder.Warnings(), tc.warnings); diff != "" {
+				t.Fatalf("got warnings:\n%#v\nwant warnings:\n%#v\ndiff:\n%s", recorder.Warnings(), tc.warnings, diff)
+			}
+		})
+	}
+}
+
+func TestAllFilterIdempotent(t *testing.T) {
+	src := `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  lifecycle_rule {
+    id      = "log"
+    enabled = true
+    prefix  = "log/"
+  }
+
+  versioning {
+    enabled = true
+  }
+
+  website {
+    index_document = "index.html"
+  }
+}
+`
+
+	o := editor.NewEditOperator(NewAllFilterWithOption(&Option{OwnershipControls: true}))
+	first, err := o.Apply([]byte(src), "test")
+	if err != nil {
+		t.Fatalf("failed to apply filter: %s", err)
+	}
+
+	recorder := NewSplitRecorder()
+	o = editor.NewEditOperator(NewAllFilterWithOption(&Option{Recorder: recorder, OwnershipControls: true}))
+	second, err := o.Apply(first, "test")
+	if err != nil {
+		t.Fatalf("failed to apply filter twice: %s", err)
+	}
+
+	if diff := cmp.Diff(string(second), string(first)); diff != "" {
+		t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", second, first, diff)
+	}
+	if len(recorder.Splits()) != 0 || len(recorder.Warnings()) != 0 {
+		t.Fatalf("expected no splits and warnings, but got splits: %d, warnings: %#v", len(recorder.Splits()), recorder.Warnings())
+	}
+}
+
+func TestAllFilterExistingSplitsInModule(t *testing.T) {
+	files := map[string][]byte{
+		"foo/main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`),
+		"foo/acl.tf": []byte(`
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`),
+	}
+	fs := tfeditor.NewMemoryFileSystem(files)
+	c := tfeditor.NewClient(&tfeditor.Option{FileSystem: fs})
+
+	recorder := NewSplitRecorder()
+	if err := c.EditFiles(fs.Names(), NewAllFilterWithOption(&Option{Recorder: recorder})); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	for filename, want := range files {
+		got, err := fs.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("failed to read file: %s", err)
+		}
+		if diff := cmp.Diff(string(got), string(want)); diff != "" {
+			t.Errorf("%s: got:\n%s\nwant:\n%s\ndiff:\n%s", filename, got, want, diff)
+		}
+	}
+
+	wantWarnings := []string{
+		"aws_s3_bucket.example was not split to aws_s3_bucket_acl.example because it already exists. Please merge the arguments left in aws_s3_bucket.example by yourself.",
+	}
+	if diff := cmp.Diff(recorder.Warnings(), wantWarnings); diff != "" {
+		t.Fatalf("got warnings:\n%#v\nwant warnings:\n%#v\ndiff:\n%s", recorder.Warnings(), wantWarnings, diff)
+	}
+}
+
+func TestAllFilterExistingOwnershipControlsInModule(t *testing.T) {
+	files := map[string][]byte{
+		"foo/main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`),
+		"foo/ownership.tf": []byte(`
+resource "aws_s3_bucket_ownership_controls" "this" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "ObjectWriter"
+  }
+}
+`),
+	}
+	fs := tfeditor.NewMemoryFileSystem(files)
+	c := tfeditor.NewClient(&tfeditor.Option{FileSystem: fs})
+
+	if err := c.EditFiles(fs.Names(), NewAllFilterWithOption(&Option{OwnershipControls: true})); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	want := map[string]string{
+		"foo/main.tf": `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket     = aws_s3_bucket.example.id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.this]
+}
+`,
+		"foo/ownership.tf": string(files["foo/ownership.tf"]),
+	}
+	for filename, want := range want {
+		got, err := fs.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("failed to read file: %s", err)
+		}
+		if diff := cmp.Diff(string(got), want); diff != "" {
+			t.Errorf("%s: got:\n%s\nwant:\n%s\ndiff:\n%s", filename, got, want, diff)
+		}
+	}
+}
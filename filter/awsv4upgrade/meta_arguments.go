@@ -0,0 +1,244 @@
+package awsv4upgrade
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+)
+
+// splitArguments is a mapping table of arguments of aws_s3_bucket in v3 to
+// arguments of split resources in v4 indexed by the split resource type.
+// It's used for rewriting the ignore_changes meta argument.
+var splitArguments = map[string]map[string][]string{
+	"aws_s3_bucket_accelerate_configuration": {
+		"acceleration_status": {"status"},
+	},
+	"aws_s3_bucket_acl": {
+		"acl":   {"acl"},
+		"grant": {"access_control_policy"},
+	},
+	"aws_s3_bucket_cors_configuration": {
+		"cors_rule": {"cors_rule"},
+	},
+	"aws_s3_bucket_lifecycle_configuration": {
+		"lifecycle_rule": {"rule"},
+	},
+	"aws_s3_bucket_logging": {
+		"logging": {"target_bucket", "target_prefix"},
+	},
+	"aws_s3_bucket_object_lock_configuration": {
+		"object_lock_configuration": {"rule"},
+	},
+	"aws_s3_bucket_policy": {
+		"policy": {"policy"},
+	},
+	"aws_s3_bucket_replication_configuration": {
+		"replication_configuration": {"role", "rule"},
+	},
+	"aws_s3_bucket_request_payment_configuration": {
+		"request_payer": {"payer"},
+	},
+	"aws_s3_bucket_server_side_encryption_configuration": {
+		"server_side_encryption_configuration": {"rule"},
+	},
+	"aws_s3_bucket_versioning": {
+		"versioning": {"versioning_configuration"},
+	},
+	"aws_s3_bucket_website_configuration": {
+		"website": {"index_document", "error_document", "redirect_all_requests_to", "routing_rules"},
+	},
+}
+
+// propagateLifecycle copies a lifecycle block of a given bucket to given
+// split resources, so that we don't lose protection such as prevent_destroy.
+// The prevent_destroy and create_before_destroy arguments are copied as they
+// are. Elements of the ignore_changes argument which refer to split
+// arguments are renamed and moved to the split resource:
+// ignore_changes = [lifecycle_rule] => ignore_changes = [rule]
+// Elements which refer to a nested attribute are mapped with the
+// nestedReferenceRules:
+// ignore_changes = [versioning[0].enabled] => ignore_changes = [versioning_configuration[0].status]
+// If a nested attribute cannot be mapped, it's left in the bucket and
+// reported as a warning.
+// If the ignore_changes is not a list such as `all`, it's copied as it is.
+func propagateLifecycle(bucket *tfwrite.Resource, splits []*tfwrite.Resource) ([]string, error) {
+	lifecycleBlocks := bucket.FindNestedBlocksByType("lifecycle")
+	if len(lifecycleBlocks) == 0 || len(splits) == 0 {
+		return nil, nil
+	}
+	lifecycle := lifecycleBlocks[0]
+
+	var ignoreChanges []string
+	ignoreChangesAttr := lifecycle.GetAttribute("ignore_changes")
+	isList := false
+	if ignoreChangesAttr != nil {
+		ignoreChanges, isList = splitTupleExpression(ignoreChangesAttr.ValueAsTokens())
+	}
+
+	warnings := []string{}
+	moved := map[string]bool{}
+	for _, split := range splits {
+		newLifecycle := tfwrite.NewEmptyNestedBlock("lifecycle")
+		// Note that copied attributes are appended as unstructured tokens, so
+		// we cannot count them with the Attributes method.
+		changed := false
+		for _, name := range []string{"prevent_destroy", "create_before_destroy"} {
+			if lifecycle.GetAttribute(name) != nil {
+				newLifecycle.CopyAttribute(lifecycle, name)
+				changed = true
+			}
+		}
+
+		switch {
+		case ignoreChangesAttr != nil && !isList:
+			newLifecycle.CopyAttribute(lifecycle, "ignore_changes")
+			changed = true
+		case len(ignoreChanges) > 0:
+			names := []string{}
+			for _, elem := range ignoreChanges {
+				if newNames, ok := splitArguments[split.SchemaType()][elem]; ok {
+					names = append(names, newNames...)
+					moved[elem] = true
+					continue
+				}
+
+				steps := splitPathSteps(elem)
+				if len(steps) < 2 {
+					continue
+				}
+				if _, ok := splitArguments[split.SchemaType()][steps[0]]; !ok {
+					continue
+				}
+				newPath, err := mapIgnoreChangesPath(steps, split.SchemaType())
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("%s in ignore_changes of aws_s3_bucket.%s was not moved to %s.%s because %s. Please rewrite it by yourself.", elem, bucket.Name(), split.SchemaType(), split.Name(), err))
+					continue
+				}
+				names = append(names, newPath)
+				moved[elem] = true
+			}
+			if len(names) > 0 {
+				tokens, err := tfwrite.ParseExpressionAsTokens("[" + strings.Join(names, ", ") + "]")
+				if err != nil {
+					return nil, fmt.Errorf("failed to build ignore_changes for %s.%s: %s", split.SchemaType(), split.Name(), err)
+				}
+				newLifecycle.SetAttributeRaw("ignore_changes", tokens)
+				changed = true
+			}
+		}
+
+		if changed {
+			split.AppendNestedBlock(newLifecycle)
+		}
+	}
+
+	if len(moved) == 0 {
+		return warnings, nil
+	}
+
+	remaining := []string{}
+	for _, elem := range ignoreChanges {
+		if !moved[elem] {
+			remaining = append(remaining, elem)
+		}
+	}
+	if len(remaining) == 0 {
+		lifecycle.RemoveAttribute("ignore_changes")
+		if len(lifecycle.Attributes()) == 0 && len(lifecycle.NestedBlocks()) == 0 {
+			bucket.RemoveNestedBlock(lifecycle)
+		}
+		return warnings, nil
+	}
+
+	tokens, err := tfwrite.ParseExpressionAsTokens("[" + strings.Join(remaining, ", ") + "]")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ignore_changes for %s.%s: %s", bucket.SchemaType(), bucket.Name(), err)
+	}
+	lifecycle.SetAttributeRaw("ignore_changes", tokens)
+	return warnings, nil
+}
+
+// mapIgnoreChangesPath maps given steps of a nested attribute of
+// aws_s3_bucket in ignore_changes to a path of a given split resource type
+// with the nestedReferenceRules. Unlike references, a value conversion is not
+// needed, so the new path of a rule with a conversion is used as it is.
+// It returns an error if the path cannot be mapped.
+func mapIgnoreChangesPath(steps []string, resourceType string) (string, error) {
+	for _, rule := range nestedReferenceRules {
+		if rule.argument != "" {
+			// Computed attributes such as website_endpoint are not nested
+			// attributes of the argument.
+			continue
+		}
+		oldSteps := splitPathSteps(rule.oldPath)
+		indexes, ok := matchPathSteps(steps, oldSteps)
+		if !ok {
+			continue
+		}
+		if rule.reason != "" {
+			return "", fmt.Errorf("%s", rule.reason)
+		}
+		if rule.newResourceType != resourceType {
+			continue
+		}
+		remaining := steps[len(oldSteps):]
+		if rule.wrap != "" {
+			return "", fmt.Errorf("%s of %s is a set, which cannot be indexed", rule.newPath, rule.newResourceType)
+		}
+		if rule.convert != "" && len(remaining) > 0 {
+			return "", fmt.Errorf("the attribute is converted from %s.%s and cannot be followed by %s", rule.newResourceType, rule.newPath, joinPathSteps(remaining))
+		}
+
+		newPath := rule.newPath
+		for _, index := range indexes {
+			newPath = strings.Replace(newPath, "[*]", index, 1)
+		}
+		return newPath + joinPathSteps(remaining), nil
+	}
+
+	return "", fmt.Errorf("no attribute of %s is known to correspond to it", resourceType)
+}
+
+// appendDependsOn appends a given address to the depends_on meta argument of
+// a given resource. If it doesn't have depends_on yet, it's created.
+func appendDependsOn(resource *tfwrite.Resource, address string) error {
+	refs := []string{}
+	if attr := resource.GetAttribute("depends_on"); attr != nil {
+		refs, _ = splitTupleExpression(attr.ValueAsTokens())
+	}
+	refs = append(refs, address)
+
+	tokens, err := tfwrite.ParseExpressionAsTokens("[" + strings.Join(refs, ", ") + "]")
+	if err != nil {
+		return fmt.Errorf("failed to build depends_on for %s.%s: %s", resource.SchemaType(), resource.Name(), err)
+	}
+	resource.SetAttributeRaw("depends_on", tokens)
+	return nil
+}
+
+// splitTupleExpression parses given tokens as a tuple expression and returns
+// the source of each element. The second return value is false if it's not a
+// tuple expression.
+// `[foo, bar[0].baz]` => ["foo", "bar[0].baz"]
+func splitTupleExpression(tokens hclwrite.Tokens) ([]string, bool) {
+	src := tokens.Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return nil, false
+	}
+
+	ret := []string{}
+	for _, elem := range tuple.Exprs {
+		ret = append(ret, string(elem.Range().SliceBytes(src)))
+	}
+	return ret, true
+}
@@ -0,0 +1,451 @@
+package awsv4upgrade
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+)
+
+// nestedReferenceRule is a rule for rewriting a reference to a nested
+// attribute of aws_s3_bucket which moved to a split resource.
+type nestedReferenceRule struct {
+	// An argument of aws_s3_bucket which is split. If empty, the first step of
+	// oldPath is used.
+	argument string
+	// A path of the old attribute relative to aws_s3_bucket.
+	// `[*]` matches any index, which is carried over to the new path.
+	oldPath string
+	// A type of the split resource.
+	newResourceType string
+	// A path of the new attribute relative to the split resource.
+	newPath string
+	// A format for converting a value whose shape changed. The %s (or %[1]s)
+	// is replaced with the new reference. If empty, no conversion is needed.
+	// A rule with a conversion matches only a reference of the same length.
+	convert string
+	// A format for wrapping a collection whose type changed, such as a set
+	// which cannot be indexed. The %s is replaced with the new reference, and
+	// the rest of the old reference follows it.
+	wrap string
+	// A reason why a reference cannot be rewritten. If not empty, the
+	// reference is left as it is and reported as a warning, instead of falling
+	// back to a generic prefix rule which produces a wrong path.
+	reason string
+}
+
+// nestedReferenceRules is a table for rewriting references to nested
+// attributes of aws_s3_bucket. Rules are evaluated in order, so a specific
+// rule should be placed before a generic prefix rule.
+// A nested block whose shape changed, such as lifecycle_rule, has explicit
+// rules only for its leaf attributes. A reference to the whole collection or
+// to an element of it is matched by a generic rule with a reason, so that it's
+// reported instead of being renamed to a value of a different shape.
+// A reference is rewritten only if the bucket actually has the argument.
+var nestedReferenceRules = []nestedReferenceRule{
+	{oldPath: "acceleration_status", newResourceType: "aws_s3_bucket_accelerate_configuration", newPath: "status"},
+	{oldPath: "acl", newResourceType: "aws_s3_bucket_acl", newPath: "acl"},
+	{oldPath: "cors_rule[*]", reason: "cors_rule of aws_s3_bucket_cors_configuration is a set, which cannot be indexed"},
+	{oldPath: "cors_rule", newResourceType: "aws_s3_bucket_cors_configuration", newPath: "cors_rule"},
+	{oldPath: "lifecycle_rule[*].enabled", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].status", convert: `(%s == "Enabled")`},
+	{oldPath: "lifecycle_rule[*].prefix", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].filter[0]", convert: `try(%[1]s.and[0].prefix, %[1]s.prefix)`},
+	{oldPath: "lifecycle_rule[*].tags", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].filter[0].and[0].tags"},
+	{oldPath: "lifecycle_rule[*].abort_incomplete_multipart_upload_days", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].abort_incomplete_multipart_upload[0].days_after_initiation", convert: `try(%s, 0)`},
+	{oldPath: "lifecycle_rule[*].expiration[*].date", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].expiration[*].date", convert: `formatdate("YYYY-MM-DD", %s)`},
+	{oldPath: "lifecycle_rule[*].transition[*]", reason: "transition of aws_s3_bucket_lifecycle_configuration is a set, which cannot be indexed"},
+	{oldPath: "lifecycle_rule[*].noncurrent_version_transition[*]", reason: "noncurrent_version_transition of aws_s3_bucket_lifecycle_configuration is a set, which cannot be indexed"},
+	{oldPath: "lifecycle_rule[*].noncurrent_version_expiration[*].days", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].noncurrent_version_expiration[*].noncurrent_days"},
+	{oldPath: "lifecycle_rule[*].id", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].id"},
+	{oldPath: "lifecycle_rule[*].expiration[*].days", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].expiration[*].days"},
+	{oldPath: "lifecycle_rule[*].expiration[*].expired_object_delete_marker", newResourceType: "aws_s3_bucket_lifecycle_configuration", newPath: "rule[*].expiration[*].expired_object_delete_marker"},
+	{oldPath: "lifecycle_rule", reason: "rule of aws_s3_bucket_lifecycle_configuration has a different shape"},
+	{oldPath: "logging[0].target_bucket", newResourceType: "aws_s3_bucket_logging", newPath: "target_bucket"},
+	{oldPath: "logging[0].target_prefix", newResourceType: "aws_s3_bucket_logging", newPath: "target_prefix"},
+	{oldPath: "object_lock_configuration[0].rule[*].default_retention[*].mode", newResourceType: "aws_s3_bucket_object_lock_configuration", newPath: "rule[*].default_retention[*].mode"},
+	{oldPath: "object_lock_configuration[0].rule[*].default_retention[*].days", newResourceType: "aws_s3_bucket_object_lock_configuration", newPath: "rule[*].default_retention[*].days"},
+	{oldPath: "object_lock_configuration[0].rule[*].default_retention[*].years", newResourceType: "aws_s3_bucket_object_lock_configuration", newPath: "rule[*].default_retention[*].years"},
+	{oldPath: "object_lock_configuration", reason: "object_lock_enabled moved to aws_s3_bucket and only attributes of default_retention can be rewritten"},
+	{oldPath: "policy", newResourceType: "aws_s3_bucket_policy", newPath: "policy"},
+	{oldPath: "replication_configuration[0].role", newResourceType: "aws_s3_bucket_replication_configuration", newPath: "role"},
+	{oldPath: "replication_configuration", reason: "rule of aws_s3_bucket_replication_configuration has a different shape"},
+	{oldPath: "request_payer", newResourceType: "aws_s3_bucket_request_payment_configuration", newPath: "payer"},
+	{oldPath: "server_side_encryption_configuration[0].rule[0]", newResourceType: "aws_s3_bucket_server_side_encryption_configuration", newPath: "rule", wrap: "one(%s)"},
+	{oldPath: "server_side_encryption_configuration", reason: "rule of aws_s3_bucket_server_side_encryption_configuration is a set, which cannot be indexed"},
+	{oldPath: "versioning[0].enabled", newResourceType: "aws_s3_bucket_versioning", newPath: "versioning_configuration[0].status", convert: `(%s == "Enabled")`},
+	{oldPath: "versioning[0].mfa_delete", newResourceType: "aws_s3_bucket_versioning", newPath: "versioning_configuration[0].mfa_delete", convert: `(%s == "Enabled")`},
+	{oldPath: "website[0].index_document", newResourceType: "aws_s3_bucket_website_configuration", newPath: "index_document[0].suffix"},
+	{oldPath: "website[0].error_document", newResourceType: "aws_s3_bucket_website_configuration", newPath: "error_document[0].key"},
+	{oldPath: "website[0].routing_rules", newResourceType: "aws_s3_bucket_website_configuration", newPath: "routing_rules"},
+	{argument: "website", oldPath: "website_domain", newResourceType: "aws_s3_bucket_website_configuration", newPath: "website_domain"},
+	{argument: "website", oldPath: "website_endpoint", newResourceType: "aws_s3_bucket_website_configuration", newPath: "website_endpoint"},
+}
+
+// arg returns an argument of aws_s3_bucket which is split.
+func (rule nestedReferenceRule) arg() string {
+	if rule.argument != "" {
+		return rule.argument
+	}
+	return splitPathSteps(rule.oldPath)[0]
+}
+
+// splitKind represents how a split resource is created from an argument.
+type splitKind int
+
+const (
+	// splitStatic means the split resource is always created.
+	splitStatic splitKind = iota
+	// splitDynamic means the split resource is created only from dynamic
+	// blocks, and it has `count = length(...) > 0 ? 1 : 0`, so that a
+	// reference to it needs an index `[0]`.
+	splitDynamic
+)
+
+// nestedReferences rewrites references to nested attributes of aws_s3_bucket
+// which moved to split resources, such as:
+// aws_s3_bucket.example.versioning[0].enabled
+// => (aws_s3_bucket_versioning.example.versioning_configuration[0].status == "Enabled")
+// Since references can be in any file of the module, it indexes arguments of
+// all aws_s3_bucket resources in the module before filtering, and rewrites a
+// reference only if the bucket actually has the argument.
+type nestedReferences struct {
+	// Arguments of aws_s3_bucket resources indexed by module dir and resource
+	// name.
+	buckets map[string]map[string]map[string]splitKind
+	// A module dir of the file being filtered.
+	dir string
+	// Names of split resources. If nil, they are the same as the bucket.
+	namer *splitNamer
+	// A recorder for warnings of references which cannot be rewritten.
+	// It's nil if not needed.
+	recorder *SplitRecorder
+}
+
+// newNestedReferences returns a new instance of nestedReferences which
+// refers to split resources named by a given namer, and reports references
+// which cannot be rewritten to a given recorder. Both can be nil.
+func newNestedReferences(namer *splitNamer, recorder *SplitRecorder) *nestedReferences {
+	return &nestedReferences{
+		buckets:  map[string]map[string]map[string]splitKind{},
+		dir:      filepath.Dir(""),
+		namer:    namer,
+		recorder: recorder,
+	}
+}
+
+// prepare indexes arguments of aws_s3_bucket resources in given files.
+// Files which cannot be parsed are ignored here, and reported later when
+// filtering them.
+func (r *nestedReferences) prepare(files map[string][]byte) {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	existing := map[string][]existingSplit{}
+	for _, filename := range filenames {
+		f, diags := hclwrite.ParseConfig(files[filename], filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+
+		dir := filepath.Dir(filename)
+		if _, ok := r.buckets[dir]; !ok {
+			r.buckets[dir] = map[string]map[string]splitKind{}
+		}
+
+		file := tfwrite.NewFile(f)
+		for _, block := range file.FindBlocksByType("resource", "aws_s3_bucket") {
+			resource, ok := block.(*tfwrite.Resource)
+			if !ok {
+				continue
+			}
+			r.buckets[dir][resource.Name()] = bucketArguments(resource)
+		}
+		existing[dir] = append(existing[dir], findExistingSplits(file)...)
+	}
+
+	// Arguments are not split if a split resource for the bucket already
+	// exists, so references to them should be left as they are.
+	for dir, splits := range existing {
+		for _, split := range splits {
+			args, ok := r.buckets[dir][split.bucketName]
+			if !ok {
+				continue
+			}
+			for oldArg := range splitArguments[split.resourceType] {
+				delete(args, oldArg)
+			}
+		}
+	}
+}
+
+// bucketArguments returns arguments of a given aws_s3_bucket resource which
+// are split by rules.
+func bucketArguments(resource *tfwrite.Resource) map[string]splitKind {
+	ret := map[string]splitKind{}
+	for _, rule := range nestedReferenceRules {
+		arg := rule.arg()
+		switch {
+		case resource.GetAttribute(arg) != nil || len(resource.FindNestedBlocksByType(arg)) > 0:
+			ret[arg] = splitStatic
+		case len(findDynamicBlocks(resource, arg)) > 0:
+			// A split resource created only from dynamic blocks has count,
+			// unless the bucket already has count or for_each.
+			if resource.Count() == nil && resource.ForEach() == nil {
+				ret[arg] = splitDynamic
+			} else {
+				ret[arg] = splitStatic
+			}
+		}
+	}
+	return ret
+}
+
+// setFilename sets a name of the file being filtered.
+func (r *nestedReferences) setFilename(filename string) {
+	r.dir = filepath.Dir(filename)
+}
+
+// BlockFilter rewrites references to nested attributes of aws_s3_bucket in
+// all attributes of a given block.
+func (r *nestedReferences) BlockFilter(inFile *tfwrite.File, block tfwrite.Block) (*tfwrite.File, error) {
+	if err := r.rewriteBlock(block); err != nil {
+		return nil, err
+	}
+	return inFile, nil
+}
+
+// rewriteBlock rewrites references in a given block recursively.
+func (r *nestedReferences) rewriteBlock(block tfwrite.Block) error {
+	// Sort attributes by name so that warnings are reported in a stable order.
+	attrs := block.Raw().Body().Attributes()
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tokens, changed, err := r.rewriteTokens(tfwrite.NewAttribute(attrs[name]).ValueAsTokens())
+		if err != nil {
+			return err
+		}
+		if changed {
+			block.SetAttributeRaw(name, tokens)
+		}
+	}
+
+	for _, nestedBlock := range block.NestedBlocks() {
+		if err := r.rewriteBlock(nestedBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nestedReferenceReplacement is a replacement of a byte range in source.
+type nestedReferenceReplacement struct {
+	rng hcl.Range
+	src string
+}
+
+// rewriteTokens rewrites references in given tokens of an expression.
+func (r *nestedReferences) rewriteTokens(tokens hclwrite.Tokens) (hclwrite.Tokens, bool, error) {
+	buckets := r.buckets[r.dir]
+	if len(buckets) == 0 {
+		return tokens, false, nil
+	}
+
+	src := tokens.Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1, Byte: 0})
+	if diags.HasErrors() {
+		// Leave it as it is if we cannot parse it.
+		return tokens, false, nil
+	}
+
+	replacements := []nestedReferenceReplacement{}
+	_ = hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		traversalExpr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+		if !ok {
+			return nil
+		}
+		replaced, ok, err := rewriteNestedReference(traversalExpr.Traversal, src, buckets, r.namer)
+		if err != nil {
+			r.warn(traversalExpr.SrcRange.SliceBytes(src), err)
+			return nil
+		}
+		if ok {
+			replacements = append(replacements, nestedReferenceReplacement{rng: traversalExpr.SrcRange, src: replaced})
+		}
+		return nil
+	})
+
+	if len(replacements) == 0 {
+		return tokens, false, nil
+	}
+
+	// Replace from the end so that byte offsets are not shifted.
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].rng.Start.Byte > replacements[j].rng.Start.Byte
+	})
+	newSrc := string(src)
+	for _, rep := range replacements {
+		newSrc = newSrc[:rep.rng.Start.Byte] + rep.src + newSrc[rep.rng.End.Byte:]
+	}
+
+	newTokens, err := tfwrite.ParseExpressionAsTokens(strings.TrimSpace(newSrc))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to rewrite references: %s", err)
+	}
+	return newTokens, true, nil
+}
+
+// warn records a warning for a given reference which cannot be rewritten.
+func (r *nestedReferences) warn(ref []byte, err error) {
+	if r.recorder == nil {
+		return
+	}
+	r.recorder.warn(fmt.Sprintf("%s was not rewritten because %s. Please rewrite it by yourself.", ref, err))
+}
+
+// rewriteNestedReference returns a new reference for a given traversal which
+// refers to a nested attribute of aws_s3_bucket, and true if it's rewritten.
+// The src is the source of the expression which contains the traversal.
+// It returns an error if the traversal refers to a split attribute, but it
+// cannot be rewritten.
+func rewriteNestedReference(traversal hcl.Traversal, src []byte, buckets map[string]map[string]splitKind, namer *splitNamer) (string, bool, error) {
+	if len(traversal) < 3 || traversal.RootName() != "aws_s3_bucket" {
+		return "", false, nil
+	}
+	nameStep, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false, nil
+	}
+	args, ok := buckets[nameStep.Name]
+	if !ok {
+		return "", false, nil
+	}
+
+	// An index for count or for_each of the bucket.
+	rest := traversal[2:]
+	bucketIndex := ""
+	if step, ok := rest[0].(hcl.TraverseIndex); ok {
+		bucketIndex = string(step.SrcRange.SliceBytes(src))
+		rest = rest[1:]
+	}
+
+	// Convert the rest of steps to strings such as ["versioning", "[0]", "enabled"]
+	steps := []string{}
+	for _, step := range rest {
+		switch s := step.(type) {
+		case hcl.TraverseAttr:
+			steps = append(steps, s.Name)
+		case hcl.TraverseIndex:
+			steps = append(steps, string(s.SrcRange.SliceBytes(src)))
+		default:
+			return "", false, nil
+		}
+	}
+	if len(steps) == 0 {
+		return "", false, nil
+	}
+
+	for _, rule := range nestedReferenceRules {
+		kind, ok := args[rule.arg()]
+		if !ok {
+			continue
+		}
+		oldSteps := splitPathSteps(rule.oldPath)
+		indexes, ok := matchPathSteps(steps, oldSteps)
+		if !ok {
+			continue
+		}
+		if rule.reason != "" {
+			return "", false, fmt.Errorf("%s", rule.reason)
+		}
+		remaining := steps[len(oldSteps):]
+		if rule.convert != "" && len(remaining) > 0 {
+			return "", false, fmt.Errorf("the attribute is converted from %s.%s and cannot be followed by %s", rule.newResourceType, rule.newPath, joinPathSteps(remaining))
+		}
+
+		address := rule.newResourceType + "." + namer.lookup(nameStep.Name, rule.newResourceType) + bucketIndex
+		if kind == splitDynamic {
+			address += "[0]"
+		}
+
+		newPath := rule.newPath
+		for _, index := range indexes {
+			newPath = strings.Replace(newPath, "[*]", index, 1)
+		}
+		ref := address + "." + newPath
+		switch {
+		case rule.convert != "":
+			ref = fmt.Sprintf(rule.convert, ref)
+		case rule.wrap != "":
+			ref = fmt.Sprintf(rule.wrap, ref)
+		}
+		return ref + joinPathSteps(remaining), true, nil
+	}
+
+	return "", false, nil
+}
+
+// splitPathSteps splits a given path to steps.
+// `versioning[0].enabled` => ["versioning", "[0]", "enabled"]
+func splitPathSteps(path string) []string {
+	ret := []string{}
+	for _, name := range strings.Split(path, ".") {
+		i := strings.Index(name, "[")
+		if i == -1 {
+			ret = append(ret, name)
+			continue
+		}
+		ret = append(ret, name[:i])
+		for _, index := range strings.SplitAfter(name[i:], "]") {
+			if index != "" {
+				ret = append(ret, index)
+			}
+		}
+	}
+	return ret
+}
+
+// joinPathSteps joins given steps as a relative path.
+// ["[0]", "foo"] => `[0].foo`
+func joinPathSteps(steps []string) string {
+	ret := ""
+	for _, step := range steps {
+		if strings.HasPrefix(step, "[") {
+			ret += step
+		} else {
+			ret += "." + step
+		}
+	}
+	return ret
+}
+
+// matchPathSteps returns true if given steps start with given pattern steps.
+// A `[*]` pattern matches any index, and matched indexes are also returned.
+func matchPathSteps(steps []string, patterns []string) ([]string, bool) {
+	if len(steps) < len(patterns) {
+		return nil, false
+	}
+
+	indexes := []string{}
+	for i, pattern := range patterns {
+		switch {
+		case pattern == "[*]" && strings.HasPrefix(steps[i], "["):
+			indexes = append(indexes, steps[i])
+		case pattern == steps[i]:
+		default:
+			return nil, false
+		}
+	}
+	return indexes, true
+}
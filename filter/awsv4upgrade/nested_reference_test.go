@@ -0,0 +1,548 @@
+package awsv4upgrade
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/tfeditor"
+)
+
+func TestNestedReferences(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		ok       bool
+		want     string
+		warnings []string
+	}{
+		{
+			name: "simple",
+			src: `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+locals {
+  log_bucket = aws_s3_bucket.example.logging[0].target_bucket
+  log_path   = "${aws_s3_bucket.example.logging[0].target_bucket}/${aws_s3_bucket.example.logging[0].target_prefix}"
+  id         = aws_s3_bucket.example.id
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  logging {
+    target_bucket = "tfedit-log"
+    target_prefix = "log/"
+  }
+
+  versioning {
+    enabled = true
+  }
+}
+`,
+			ok: true,
+			want: `
+output "versioning_enabled" {
+  value = (aws_s3_bucket_versioning.example.versioning_configuration[0].status == "Enabled")
+}
+
+locals {
+  log_bucket = aws_s3_bucket_logging.example.target_bucket
+  log_path   = "${aws_s3_bucket_logging.example.target_bucket}/${aws_s3_bucket_logging.example.target_prefix}"
+  id         = aws_s3_bucket.example.id
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_logging" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  target_bucket = "tfedit-log"
+  target_prefix = "log/"
+}
+
+resource "aws_s3_bucket_versioning" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+`,
+		},
+		{
+			name: "nested blocks and lists",
+			src: `
+output "sse_algorithm" {
+  value = aws_s3_bucket.example.server_side_encryption_configuration[0].rule[0].apply_server_side_encryption_by_default[0].sse_algorithm
+}
+
+output "lifecycle_rules" {
+  value = aws_s3_bucket.example.lifecycle_rule
+}
+
+output "lifecycle_rule_enabled" {
+  value = aws_s3_bucket.example.lifecycle_rule[0].enabled
+}
+
+output "lifecycle_rules_enabled" {
+  value = [for r in aws_s3_bucket.example.lifecycle_rule : r.enabled]
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  lifecycle_rule {
+    id      = "log"
+    enabled = true
+    prefix  = "log/"
+  }
+
+  server_side_encryption_configuration {
+    rule {
+      apply_server_side_encryption_by_default {
+        sse_algorithm = "AES256"
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+output "sse_algorithm" {
+  value = one(aws_s3_bucket_server_side_encryption_configuration.example.rule).apply_server_side_encryption_by_default[0].sse_algorithm
+}
+
+output "lifecycle_rules" {
+  value = aws_s3_bucket.example.lifecycle_rule
+}
+
+output "lifecycle_rule_enabled" {
+  value = (aws_s3_bucket_lifecycle_configuration.example.rule[0].status == "Enabled")
+}
+
+output "lifecycle_rules_enabled" {
+  value = [for r in aws_s3_bucket.example.lifecycle_rule : r.enabled]
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    id     = "log"
+    status = "Enabled"
+
+    filter {
+      prefix = "log/"
+    }
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.example.lifecycle_rule was not rewritten because rule of aws_s3_bucket_lifecycle_configuration has a different shape. Please rewrite it by yourself.",
+				"aws_s3_bucket.example.lifecycle_rule was not rewritten because rule of aws_s3_bucket_lifecycle_configuration has a different shape. Please rewrite it by yourself.",
+			},
+		},
+		{
+			name: "other nested collections",
+			src: `
+locals {
+  sse_rules      = aws_s3_bucket.example.server_side_encryption_configuration[0].rule
+  retention_days = aws_s3_bucket.example.object_lock_configuration[0].rule[0].default_retention[0].days
+  lock_rules     = aws_s3_bucket.example.object_lock_configuration[0].rule
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  object_lock_configuration {
+    object_lock_enabled = "Enabled"
+
+    rule {
+      default_retention {
+        mode = "COMPLIANCE"
+        days = 3
+      }
+    }
+  }
+
+  server_side_encryption_configuration {
+    rule {
+      apply_server_side_encryption_by_default {
+        sse_algorithm = "AES256"
+      }
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+locals {
+  sse_rules      = aws_s3_bucket.example.server_side_encryption_configuration[0].rule
+  retention_days = aws_s3_bucket_object_lock_configuration.example.rule[0].default_retention[0].days
+  lock_rules     = aws_s3_bucket.example.object_lock_configuration[0].rule
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  object_lock_enabled = true
+}
+
+resource "aws_s3_bucket_object_lock_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    default_retention {
+      mode = "COMPLIANCE"
+      days = 3
+    }
+  }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.example.object_lock_configuration[0].rule was not rewritten because object_lock_enabled moved to aws_s3_bucket and only attributes of default_retention can be rewritten. Please rewrite it by yourself.",
+				"aws_s3_bucket.example.server_side_encryption_configuration[0].rule was not rewritten because rule of aws_s3_bucket_server_side_encryption_configuration is a set, which cannot be indexed. Please rewrite it by yourself.",
+			},
+		},
+		{
+			name: "lifecycle rule sub-paths",
+			src: `
+locals {
+  prefix     = aws_s3_bucket.example.lifecycle_rule[0].prefix
+  tags       = aws_s3_bucket.example.lifecycle_rule[0].tags
+  abort_days = aws_s3_bucket.example.lifecycle_rule[0].abort_incomplete_multipart_upload_days
+  date       = aws_s3_bucket.example.lifecycle_rule[0].expiration[0].date
+  id         = aws_s3_bucket.example.lifecycle_rule[0].id
+  transition = aws_s3_bucket.example.lifecycle_rule[0].transition[0].days
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  lifecycle_rule {
+    id                                     = "log"
+    enabled                                = true
+    prefix                                 = "log/"
+    abort_incomplete_multipart_upload_days = 7
+
+    tags = {
+      rule = "log"
+    }
+
+    expiration {
+      date = "2022-12-31"
+    }
+
+    transition {
+      days          = 30
+      storage_class = "GLACIER"
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+locals {
+  prefix     = try(aws_s3_bucket_lifecycle_configuration.example.rule[0].filter[0].and[0].prefix, aws_s3_bucket_lifecycle_configuration.example.rule[0].filter[0].prefix)
+  tags       = aws_s3_bucket_lifecycle_configuration.example.rule[0].filter[0].and[0].tags
+  abort_days = try(aws_s3_bucket_lifecycle_configuration.example.rule[0].abort_incomplete_multipart_upload[0].days_after_initiation, 0)
+  date       = formatdate("YYYY-MM-DD", aws_s3_bucket_lifecycle_configuration.example.rule[0].expiration[0].date)
+  id         = aws_s3_bucket_lifecycle_configuration.example.rule[0].id
+  transition = aws_s3_bucket.example.lifecycle_rule[0].transition[0].days
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_lifecycle_configuration" "example" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    id = "log"
+
+
+    expiration {
+      date = "2022-12-31T00:00:00Z"
+    }
+
+    transition {
+      days          = 30
+      storage_class = "GLACIER"
+    }
+    status = "Enabled"
+
+    filter {
+
+      and {
+        prefix = "log/"
+        tags = {
+          rule = "log"
+        }
+      }
+    }
+
+    abort_incomplete_multipart_upload {
+      days_after_initiation = 7
+    }
+  }
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.example.lifecycle_rule[0].transition[0].days was not rewritten because transition of aws_s3_bucket_lifecycle_configuration is a set, which cannot be indexed. Please rewrite it by yourself.",
+			},
+		},
+		{
+			name: "count and dynamic",
+			src: `
+output "policy" {
+  value = aws_s3_bucket.example[0].policy
+}
+
+output "versioning_enabled" {
+  value = aws_s3_bucket.dynamic.versioning[0].enabled
+}
+
+resource "aws_s3_bucket" "example" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+  policy = "{}"
+}
+
+resource "aws_s3_bucket" "dynamic" {
+  bucket = "tfedit-test"
+
+  dynamic "versioning" {
+    for_each = var.versioning
+    content {
+      enabled = versioning.value.enabled
+    }
+  }
+}
+`,
+			ok: true,
+			want: `
+output "policy" {
+  value = aws_s3_bucket_policy.example[0].policy
+}
+
+output "versioning_enabled" {
+  value = (aws_s3_bucket_versioning.dynamic[0].versioning_configuration[0].status == "Enabled")
+}
+
+resource "aws_s3_bucket" "example" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+}
+
+resource "aws_s3_bucket_policy" "example" {
+  count  = 2
+  bucket = aws_s3_bucket.example[count.index].id
+  policy = "{}"
+}
+
+resource "aws_s3_bucket" "dynamic" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_versioning" "dynamic" {
+  count  = length(var.versioning) > 0 ? 1 : 0
+  bucket = aws_s3_bucket.dynamic.id
+
+  dynamic "versioning_configuration" {
+    for_each = var.versioning
+    content {
+      status = versioning_configuration.value.enabled ? "Enabled" : "Suspended"
+    }
+  }
+}
+`,
+		},
+		{
+			name: "not split",
+			src: `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+output "foo" {
+  value = aws_s3_bucket.foo.versioning[0].enabled
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+`,
+			ok: true,
+			want: `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+output "foo" {
+  value = aws_s3_bucket.foo.versioning[0].enabled
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := NewSplitRecorder()
+			o := editor.NewEditOperator(NewAllFilterWithRecorder(recorder))
+			output, err := o.Apply([]byte(tc.src), "test")
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, outStream: \n%s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+
+			warnings := recorder.Warnings()
+			if len(warnings) != 0 || len(tc.warnings) != 0 {
+				if diff := cmp.Diff(warnings, tc.warnings); diff != "" {
+					t.Fatalf("got warnings:\n%#v\nwant:\n%#v\ndiff:\n%s", warnings, tc.warnings, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestNestedReferencesInModule(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		ok    bool
+		want  map[string]string
+	}{
+		{
+			name: "refer to a bucket in another file of the same module",
+			files: map[string]string{
+				"foo/main.tf": `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  versioning {
+    enabled = true
+  }
+}
+`,
+				"foo/outputs.tf": `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+`,
+				"bar/outputs.tf": `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+`,
+			},
+			ok: true,
+			want: map[string]string{
+				"foo/outputs.tf": `
+output "versioning_enabled" {
+  value = (aws_s3_bucket_versioning.example.versioning_configuration[0].status == "Enabled")
+}
+`,
+				"bar/outputs.tf": `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string][]byte{}
+			for filename, src := range tc.files {
+				files[filename] = []byte(src)
+			}
+			fs := tfeditor.NewMemoryFileSystem(files)
+			c := tfeditor.NewClient(&tfeditor.Option{FileSystem: fs})
+
+			err := c.EditFiles(fs.Names(), NewAllFilter())
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error")
+			}
+
+			for filename, want := range tc.want {
+				got, err := fs.ReadFile(filename)
+				if err != nil {
+					t.Fatalf("failed to read file: %s", err)
+				}
+				if diff := cmp.Diff(string(got), want); diff != "" {
+					t.Errorf("%s: got:\n%s\nwant:\n%s\ndiff:\n%s", filename, got, want, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitPathSteps(t *testing.T) {
+	cases := []struct {
+		path string
+		want []string
+	}{
+		{path: "policy", want: []string{"policy"}},
+		{path: "versioning[0].enabled", want: []string{"versioning", "[0]", "enabled"}},
+		{path: "rule[*].status", want: []string{"rule", "[*]", "status"}},
+		{path: "foo[0][1].bar", want: []string{"foo", "[0]", "[1]", "bar"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			got := splitPathSteps(tc.path)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package awsv4upgrade
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+)
+
+// DefaultSplitNameTemplate is a default naming template for split resources,
+// which reuses the name of the aws_s3_bucket resource.
+const DefaultSplitNameTemplate = "{{.Name}}"
+
+// SplitNameTemplate is a naming template for resources split from
+// aws_s3_bucket. It's a text/template with the following fields:
+// - Name: A name of the aws_s3_bucket resource. (e.g. example)
+// - Type: A type of the split resource. (e.g. aws_s3_bucket_acl)
+// - Suffix: The Type without the aws_s3_bucket_ prefix. (e.g. acl)
+type SplitNameTemplate struct {
+	tmpl *template.Template
+}
+
+// splitNameData is data passed to a SplitNameTemplate.
+type splitNameData struct {
+	Name   string
+	Type   string
+	Suffix string
+}
+
+// ParseSplitNameTemplate parses a given naming template for split resources.
+// It returns an error if the template is invalid or doesn't produce a valid
+// resource name.
+func ParseSplitNameTemplate(text string) (*SplitNameTemplate, error) {
+	tmpl, err := template.New("split-name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse split name template: %s", err)
+	}
+
+	t := &SplitNameTemplate{tmpl: tmpl}
+	// Check that it works with a typical input in advance.
+	if _, err := t.execute("example", "aws_s3_bucket_acl"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// execute returns a name of a split resource of a given type for a given
+// bucket name.
+func (t *SplitNameTemplate) execute(bucketName string, resourceType string) (string, error) {
+	data := splitNameData{
+		Name:   bucketName,
+		Type:   resourceType,
+		Suffix: strings.TrimPrefix(resourceType, "aws_s3_bucket_"),
+	}
+
+	var b bytes.Buffer
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to execute split name template: %s", err)
+	}
+
+	name := b.String()
+	if !hclsyntax.ValidIdentifier(name) {
+		return "", fmt.Errorf("split name template produced an invalid resource name: %q", name)
+	}
+	return name, nil
+}
+
+// splitNamer decides names of split resources.
+// A name is generated from a SplitNameTemplate. If another resource of the
+// same type already has the name in the module, a numbered suffix such as
+// `_2` is appended to avoid a duplicate declaration.
+// Since split resources can be referred from any file of the module, names
+// are planned for all aws_s3_bucket resources in the module before filtering.
+type splitNamer struct {
+	tmpl *SplitNameTemplate
+	// Names of split resources indexed by module dir and splitNameKey.
+	names map[string]map[string]string
+	// Names of resources used in the module indexed by module dir and type.
+	used map[string]map[string]map[string]bool
+	// A module dir of the file being filtered.
+	dir string
+}
+
+// newSplitNamer returns a new instance of splitNamer.
+// If tmpl is nil, DefaultSplitNameTemplate is used.
+func newSplitNamer(tmpl *SplitNameTemplate) *splitNamer {
+	if tmpl == nil {
+		// The default template is always valid.
+		tmpl, _ = ParseSplitNameTemplate(DefaultSplitNameTemplate)
+	}
+	return &splitNamer{
+		tmpl:  tmpl,
+		names: map[string]map[string]string{},
+		used:  map[string]map[string]map[string]bool{},
+		dir:   filepath.Dir(""),
+	}
+}
+
+// splitNameKey returns a key of a split resource for a given bucket name and
+// resource type.
+func splitNameKey(bucketName string, resourceType string) string {
+	return resourceType + "." + bucketName
+}
+
+// prepare plans names of split resources for aws_s3_bucket resources in given
+// files. Files which cannot be parsed are ignored here, and reported later
+// when filtering them.
+func (n *splitNamer) prepare(files map[string][]byte) error {
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	parsed := map[string]*tfwrite.File{}
+	existing := map[string][]existingSplit{}
+	for _, filename := range filenames {
+		f, diags := hclwrite.ParseConfig(files[filename], filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			continue
+		}
+		file := tfwrite.NewFile(f)
+		parsed[filename] = file
+		dir := filepath.Dir(filename)
+		n.use(dir, file)
+		existing[dir] = append(existing[dir], findExistingSplits(file)...)
+	}
+
+	// Sort split resource types to make names deterministic.
+	resourceTypes := make([]string, 0, len(splitArguments))
+	for resourceType := range splitArguments {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	for _, filename := range filenames {
+		file, ok := parsed[filename]
+		if !ok {
+			continue
+		}
+		dir := filepath.Dir(filename)
+		for _, block := range file.FindBlocksByType("resource", "aws_s3_bucket") {
+			bucket, ok := block.(*tfwrite.Resource)
+			if !ok {
+				continue
+			}
+			for _, resourceType := range resourceTypes {
+				if !hasSplitArguments(bucket, resourceType) || hasExistingSplit(existing[dir], bucket, resourceType) {
+					continue
+				}
+				if _, err := n.assign(dir, bucket.Name(), resourceType); err != nil {
+					return err
+				}
+			}
+			// aws_s3_bucket_ownership_controls is generated for aws_s3_bucket_acl
+			// if needed.
+			if hasSplitArguments(bucket, "aws_s3_bucket_acl") {
+				if _, err := n.assign(dir, bucket.Name(), "aws_s3_bucket_ownership_controls"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// use marks names of all resources in a given file as used.
+func (n *splitNamer) use(dir string, file *tfwrite.File) {
+	if _, ok := n.used[dir]; !ok {
+		n.used[dir] = map[string]map[string]bool{}
+	}
+	for _, block := range file.FindBlocksByType("resource", "") {
+		resource, ok := block.(*tfwrite.Resource)
+		if !ok {
+			continue
+		}
+		if _, ok := n.used[dir][resource.SchemaType()]; !ok {
+			n.used[dir][resource.SchemaType()] = map[string]bool{}
+		}
+		n.used[dir][resource.SchemaType()][resource.Name()] = true
+	}
+}
+
+// assign returns a name of a split resource of a given type for a given
+// bucket name in a given module dir. If not assigned yet, a new unique name
+// is assigned.
+func (n *splitNamer) assign(dir string, bucketName string, resourceType string) (string, error) {
+	key := splitNameKey(bucketName, resourceType)
+	if name, ok := n.names[dir][key]; ok {
+		return name, nil
+	}
+
+	base, err := n.tmpl.execute(bucketName, resourceType)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := n.used[dir]; !ok {
+		n.used[dir] = map[string]map[string]bool{}
+	}
+	if _, ok := n.used[dir][resourceType]; !ok {
+		n.used[dir][resourceType] = map[string]bool{}
+	}
+	used := n.used[dir][resourceType]
+
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+
+	if _, ok := n.names[dir]; !ok {
+		n.names[dir] = map[string]string{}
+	}
+	n.names[dir][key] = name
+	return name, nil
+}
+
+// setFilename sets a name of the file being filtered.
+func (n *splitNamer) setFilename(filename string) {
+	n.dir = filepath.Dir(filename)
+}
+
+// name returns a name of a split resource of a given type for a given bucket
+// in the file being filtered. It also returns true if the name was changed
+// from the template to avoid a collision.
+// If it has not been planned by prepare, a new name is assigned.
+// If n is nil, it returns the name of the bucket as it is.
+func (n *splitNamer) name(bucket *tfwrite.Resource, resourceType string) (string, bool, error) {
+	if n == nil {
+		return bucket.Name(), false, nil
+	}
+
+	name, err := n.assign(n.dir, bucket.Name(), resourceType)
+	if err != nil {
+		return "", false, err
+	}
+	// The template has already been validated by assign.
+	base, _ := n.tmpl.execute(bucket.Name(), resourceType)
+	return name, name != base, nil
+}
+
+// lookup returns a planned name of a split resource of a given type for a
+// given bucket name in the file being filtered. If not planned, it returns
+// the bucket name as it is.
+// If n is nil, it returns the bucket name as it is.
+func (n *splitNamer) lookup(bucketName string, resourceType string) string {
+	if n == nil {
+		return bucketName
+	}
+	if name, ok := n.names[n.dir][splitNameKey(bucketName, resourceType)]; ok {
+		return name
+	}
+	return bucketName
+}
+
+// renameSplits renames given split resources of a given bucket with a given
+// namer, and updates references among them such as depends_on.
+// Split resources are created with the name of the bucket by filters.
+// It returns warnings for names changed to avoid collisions.
+func renameSplits(bucket *tfwrite.Resource, splits []*tfwrite.Resource, namer *splitNamer) ([]string, error) {
+	warnings := []string{}
+	for _, split := range splits {
+		resourceType := split.SchemaType()
+		name, collided, err := namer.name(bucket, resourceType)
+		if err != nil {
+			return nil, err
+		}
+		if collided {
+			warnings = append(warnings, fmt.Sprintf("aws_s3_bucket.%s was split to %s.%s because the name is already used.", bucket.Name(), resourceType, name))
+		}
+
+		oldName := split.Name()
+		if name == oldName {
+			continue
+		}
+		split.Raw().SetLabels([]string{resourceType, name})
+
+		// Split resources refer to each other only in depends_on, which is set
+		// as raw tokens, so that we cannot use RenameReference here.
+		from := resourceType + "." + oldName
+		to := hclwrite.Tokens{
+			{Type: hclsyntax.TokenIdent, Bytes: []byte(resourceType)},
+			{Type: hclsyntax.TokenDot, Bytes: []byte(".")},
+			{Type: hclsyntax.TokenIdent, Bytes: []byte(name)},
+		}
+		for _, s := range splits {
+			if dependsOn := s.GetAttribute("depends_on"); dependsOn != nil {
+				s.SetAttributeRaw("depends_on", tfwrite.ReplaceReferenceAsTokens(dependsOn.ValueAsTokens(), from, to))
+			}
+		}
+	}
+	return warnings, nil
+}
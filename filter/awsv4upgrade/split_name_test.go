@@ -0,0 +1,381 @@
+package awsv4upgrade
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/migration"
+	"github.com/minamijoyo/tfedit/tfeditor"
+)
+
+func TestParseSplitNameTemplate(t *testing.T) {
+	cases := []struct {
+		name       string
+		text       string
+		bucketName string
+		ok         bool
+		want       string
+	}{
+		{
+			name:       "default",
+			text:       DefaultSplitNameTemplate,
+			bucketName: "example",
+			ok:         true,
+			want:       "example",
+		},
+		{
+			name:       "suffix",
+			text:       "{{.Name}}_{{.Suffix}}",
+			bucketName: "example",
+			ok:         true,
+			want:       "example_acl",
+		},
+		{
+			name:       "type",
+			text:       "{{.Type}}",
+			bucketName: "example",
+			ok:         true,
+			want:       "aws_s3_bucket_acl",
+		},
+		{
+			name: "syntax error",
+			text: "{{.Name",
+			ok:   false,
+		},
+		{
+			name: "unknown field",
+			text: "{{.Foo}}",
+			ok:   false,
+		},
+		{
+			name: "invalid name",
+			text: "{{.Name}}.{{.Suffix}}",
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := ParseSplitNameTemplate(tc.text)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error")
+				}
+				return
+			}
+
+			got, err := tmpl.execute(tc.bucketName, "aws_s3_bucket_acl")
+			if err != nil {
+				t.Fatalf("failed to execute template: %s", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAllFilterSplitName(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		tmpl     string
+		ok       bool
+		want     string
+		warnings []string
+	}{
+		{
+			name: "suffix",
+			src: `
+output "versioning_enabled" {
+  value = aws_s3_bucket.example.versioning[0].enabled
+}
+
+output "website_endpoint" {
+  value = aws_s3_bucket.example.website_endpoint
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  versioning {
+    enabled = true
+  }
+
+  website {
+    index_document = "index.html"
+  }
+}
+`,
+			tmpl: "{{.Name}}_{{.Suffix}}",
+			ok:   true,
+			want: `
+output "versioning_enabled" {
+  value = (aws_s3_bucket_versioning.example_versioning.versioning_configuration[0].status == "Enabled")
+}
+
+output "website_endpoint" {
+  value = aws_s3_bucket_website_configuration.example_website_configuration.website_endpoint
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_ownership_controls" "example_ownership_controls" {
+  bucket = aws_s3_bucket.example.id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "example_acl" {
+  bucket     = aws_s3_bucket.example.id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.example_ownership_controls]
+}
+
+resource "aws_s3_bucket_versioning" "example_versioning" {
+  bucket = aws_s3_bucket.example.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_website_configuration" "example_website_configuration" {
+  bucket = aws_s3_bucket.example.id
+
+  index_document {
+    suffix = "index.html"
+  }
+
+}
+`,
+			warnings: []string{},
+		},
+		{
+			name: "collision",
+			src: `
+output "policy" {
+  value = aws_s3_bucket.this.policy
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = "tfedit-test"
+  policy = "{}"
+}
+
+resource "aws_s3_bucket_policy" "this" {
+  bucket = aws_s3_bucket.other.id
+  policy = "{}"
+}
+`,
+			tmpl: DefaultSplitNameTemplate,
+			ok:   true,
+			want: `
+output "policy" {
+  value = aws_s3_bucket_policy.this_2.policy
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_policy" "this_2" {
+  bucket = aws_s3_bucket.this.id
+  policy = "{}"
+}
+
+resource "aws_s3_bucket_policy" "this" {
+  bucket = aws_s3_bucket.other.id
+  policy = "{}"
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.this was split to aws_s3_bucket_policy.this_2 because the name is already used.",
+			},
+		},
+		{
+			name: "collision between generated names",
+			src: `
+resource "aws_s3_bucket" "foo" {
+  bucket = "tfedit-foo"
+  acl    = "private"
+}
+
+resource "aws_s3_bucket" "bar" {
+  bucket = "tfedit-bar"
+  acl    = "private"
+}
+`,
+			tmpl: "{{.Suffix}}",
+			ok:   true,
+			want: `
+resource "aws_s3_bucket" "foo" {
+  bucket = "tfedit-foo"
+}
+
+resource "aws_s3_bucket_ownership_controls" "ownership_controls" {
+  bucket = aws_s3_bucket.foo.id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "acl" {
+  bucket     = aws_s3_bucket.foo.id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.ownership_controls]
+}
+
+resource "aws_s3_bucket" "bar" {
+  bucket = "tfedit-bar"
+}
+
+resource "aws_s3_bucket_ownership_controls" "ownership_controls_2" {
+  bucket = aws_s3_bucket.bar.id
+
+  rule {
+    object_ownership = "BucketOwnerPreferred"
+  }
+}
+
+resource "aws_s3_bucket_acl" "acl_2" {
+  bucket     = aws_s3_bucket.bar.id
+  acl        = "private"
+  depends_on = [aws_s3_bucket_ownership_controls.ownership_controls_2]
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.bar was split to aws_s3_bucket_ownership_controls.ownership_controls_2 because the name is already used.",
+				"aws_s3_bucket.bar was split to aws_s3_bucket_acl.acl_2 because the name is already used.",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := ParseSplitNameTemplate(tc.tmpl)
+			if err != nil {
+				t.Fatalf("failed to parse template: %s", err)
+			}
+			recorder := NewSplitRecorder()
+			filter := NewAllFilterWithOption(&Option{Recorder: recorder, OwnershipControls: true, SplitNameTemplate: tmpl})
+			o := editor.NewEditOperator(filter)
+			output, err := o.Apply([]byte(tc.src), "test")
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, outStream: \n%s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+
+			if diff := cmp.Diff(recorder.Warnings(), tc.warnings); diff != "" {
+				t.Fatalf("got warnings:\n%#v\nwant warnings:\n%#v\ndiff:\n%s", recorder.Warnings(), tc.warnings, diff)
+			}
+		})
+	}
+}
+
+func TestAllFilterSplitNameMigration(t *testing.T) {
+	src := `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`
+	tmpl, err := ParseSplitNameTemplate("{{.Name}}_{{.Suffix}}")
+	if err != nil {
+		t.Fatalf("failed to parse template: %s", err)
+	}
+	recorder := NewSplitRecorder()
+	o := editor.NewEditOperator(NewAllFilterWithOption(&Option{Recorder: recorder, SplitNameTemplate: tmpl}))
+	if _, err := o.Apply([]byte(src), "test"); err != nil {
+		t.Fatalf("failed to apply filter: %s", err)
+	}
+
+	output, err := recorder.Migration(migration.NewDefaultDictionary(), "").Render()
+	if err != nil {
+		t.Fatalf("failed to render migration: %s", err)
+	}
+
+	want := `migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_acl.example_acl tfedit-test,private",
+  ]
+}
+`
+	if diff := cmp.Diff(string(output), want); diff != "" {
+		t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", output, want, diff)
+	}
+}
+
+func TestAllFilterSplitNameInModule(t *testing.T) {
+	files := map[string][]byte{
+		"foo/main.tf": []byte(`
+resource "aws_s3_bucket" "this" {
+  bucket = "tfedit-test"
+  policy = "{}"
+}
+`),
+		"foo/other.tf": []byte(`
+resource "aws_s3_bucket_policy" "this" {
+  bucket = aws_s3_bucket.other.id
+  policy = "{}"
+}
+`),
+		"foo/outputs.tf": []byte(`
+output "policy" {
+  value = aws_s3_bucket.this.policy
+}
+`),
+	}
+	want := map[string]string{
+		"foo/main.tf": `
+resource "aws_s3_bucket" "this" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_policy" "this_2" {
+  bucket = aws_s3_bucket.this.id
+  policy = "{}"
+}
+`,
+		"foo/outputs.tf": `
+output "policy" {
+  value = aws_s3_bucket_policy.this_2.policy
+}
+`,
+	}
+
+	fs := tfeditor.NewMemoryFileSystem(files)
+	c := tfeditor.NewClient(&tfeditor.Option{FileSystem: fs})
+	if err := c.EditFiles(fs.Names(), NewAllFilter()); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	for filename, want := range want {
+		got, err := fs.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("failed to read file: %s", err)
+		}
+		if diff := cmp.Diff(string(got), want); diff != "" {
+			t.Errorf("%s: got:\n%s\nwant:\n%s\ndiff:\n%s", filename, got, want, diff)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package awsv4upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/migration"
+	"github.com/minamijoyo/tfedit/migration/schema"
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// SplitResource is a resource split from aws_s3_bucket by a filter.
+type SplitResource struct {
+	// A new resource split from the parent. (e.g. aws_s3_bucket_acl)
+	Resource *tfwrite.Resource
+	// An original aws_s3_bucket resource.
+	Parent *tfwrite.Resource
+}
+
+// SplitRecorder records split resources created by filters, so that we can
+// generate import actions for them without running terraform plan.
+// It also records warnings for arguments which filters could not split.
+type SplitRecorder struct {
+	splits   []*SplitResource
+	warnings []string
+}
+
+// NewSplitRecorder returns a new instance of SplitRecorder.
+func NewSplitRecorder() *SplitRecorder {
+	return &SplitRecorder{
+		splits:   []*SplitResource{},
+		warnings: []string{},
+	}
+}
+
+// Splits returns a list of recorded split resources.
+func (r *SplitRecorder) Splits() []*SplitResource {
+	return r.splits
+}
+
+// Warnings returns a list of warnings recorded by filters.
+func (r *SplitRecorder) Warnings() []string {
+	return r.warnings
+}
+
+// warn records a warning.
+func (r *SplitRecorder) warn(warning string) {
+	r.warnings = append(r.warnings, warning)
+}
+
+// record records new blocks created from a given parent.
+// Blocks other than resources are ignored.
+func (r *SplitRecorder) record(parent *tfwrite.Resource, blocks []tfwrite.Block) {
+	for _, b := range blocks {
+		if resource, ok := b.(*tfwrite.Resource); ok {
+			r.splits = append(r.splits, &SplitResource{Resource: resource, Parent: parent})
+		}
+	}
+}
+
+// Migration returns a state migration which imports the recorded split
+// resources. Import IDs are calculated with a given dictionary from literal
+// values in the configuration. If an import ID cannot be derived statically,
+// such as the bucket name is not a literal, it's reported as a warning, which
+// needs terraform plan and tfedit migration fromplan instead.
+// The dir is set to a dir attribute in a migration file.
+func (r *SplitRecorder) Migration(d *schema.Dictionary, dir string) *migration.StateMigration {
+	m := migration.NewStateMigration("awsv4upgrade", dir)
+	m.AppendWarnings(r.warnings...)
+	for _, s := range r.splits {
+		address := s.Resource.SchemaType() + "." + s.Resource.Name()
+		id, err := splitImportID(d, s)
+		if err != nil {
+			m.AppendWarnings(fmt.Sprintf("%s needs a plan to derive the import ID: %s", address, err))
+			continue
+		}
+		parent := s.Parent.SchemaType() + "." + s.Parent.Name()
+		m.AppendGroupedActions(parent, migration.NewStateImportAction(address, id))
+	}
+	m.SortActions()
+	return m
+}
+
+// splitImportID returns an import ID for a given split resource.
+func splitImportID(d *schema.Dictionary, s *SplitResource) (string, error) {
+	parentAddress := s.Parent.SchemaType() + "." + s.Parent.Name()
+	if s.Parent.Count() != nil || s.Parent.ForEach() != nil {
+		return "", fmt.Errorf("%s uses count or for_each", parentAddress)
+	}
+
+	// A resource split only from dynamic blocks may not exist.
+	if s.Resource.Count() != nil {
+		address := s.Resource.SchemaType() + "." + s.Resource.Name()
+		return "", fmt.Errorf("%s is created conditionally by count", address)
+	}
+
+	// The bucket argument of the split resource refers to the id of the parent,
+	// which is the same as the bucket name.
+	bucketAttr := s.Parent.GetAttribute("bucket")
+	if bucketAttr == nil {
+		return "", fmt.Errorf("the bucket name of %s is not set", parentAddress)
+	}
+	bucket, err := bucketAttr.LiteralValue()
+	if err != nil || bucket.Type() != cty.String || bucket.IsNull() {
+		return "", fmt.Errorf("the bucket name of %s is not a literal string", parentAddress)
+	}
+
+	// Attributes copied by filters are appended as unstructured tokens, so we
+	// need to parse the block again to read them.
+	resource, err := reparseBlock(s.Resource)
+	if err != nil {
+		return "", err
+	}
+	values, err := literalValues(resource)
+	if err != nil {
+		return "", err
+	}
+	values["bucket"] = bucket.AsString()
+
+	return d.ImportID(s.Resource.SchemaType(), values)
+}
+
+// reparseBlock parses a given block again from its tokens.
+func reparseBlock(block tfwrite.Block) (tfwrite.Block, error) {
+	src := block.Raw().BuildTokens(nil).Bytes()
+	f, diags := hclwrite.ParseConfig(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse block: %s", diags)
+	}
+
+	blocks := tfwrite.NewFile(f).Blocks()
+	if len(blocks) != 1 {
+		return nil, fmt.Errorf("failed to parse block: expected 1 block, but got %d", len(blocks))
+	}
+	return blocks[0], nil
+}
+
+// literalValues returns a schema.Resource which contains literal values of
+// attributes and nested blocks in a given block.
+// Attributes which are not literals are omitted.
+func literalValues(block tfwrite.Block) (schema.Resource, error) {
+	ret := schema.Resource{}
+	for name, attr := range block.Raw().Body().Attributes() {
+		v, err := tfwrite.NewAttribute(attr).LiteralValue()
+		if err != nil || v.IsNull() {
+			continue
+		}
+		b, err := ctyjson.Marshal(v, v.Type())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %s", name, err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(b, &value); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %s", name, err)
+		}
+		ret[name] = value
+	}
+
+	for _, nestedBlock := range block.NestedBlocks() {
+		values, err := literalValues(nestedBlock)
+		if err != nil {
+			return nil, err
+		}
+		list, _ := ret[nestedBlock.Type()].([]interface{})
+		ret[nestedBlock.Type()] = append(list, map[string]interface{}(values))
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,194 @@
+package awsv4upgrade
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/migration"
+)
+
+func TestSplitRecorderMigration(t *testing.T) {
+	cases := []struct {
+		name              string
+		src               string
+		ownershipControls bool
+		ok                bool
+		want              string
+	}{
+		{
+			name: "literal bucket name",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  versioning {
+    enabled = true
+  }
+}
+`,
+			ok: true,
+			want: `migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+    "import aws_s3_bucket_versioning.example tfedit-test",
+  ]
+}
+`,
+		},
+		{
+			name: "grant",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  grant {
+    id          = "1234"
+    type        = "CanonicalUser"
+    permissions = ["FULL_CONTROL"]
+  }
+}
+`,
+			ok: true,
+			want: `migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test",
+  ]
+}
+`,
+		},
+		{
+			name: "ownership controls",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`,
+			ownershipControls: true,
+			ok:                true,
+			want: `migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+    "import aws_s3_bucket_ownership_controls.example tfedit-test",
+  ]
+}
+`,
+		},
+		{
+			name: "needs plan",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = var.acl
+  policy = "{}"
+}
+
+resource "aws_s3_bucket" "variable" {
+  bucket = var.bucket
+  acl    = "private"
+}
+
+resource "aws_s3_bucket" "count" {
+  count = 2
+
+  bucket = "tfedit-test-${count.index}"
+  acl    = "private"
+}
+`,
+			ok: true,
+			want: `# WARNING: aws_s3_bucket_acl.example needs a plan to derive the import ID: failed to detect an ID of aws_s3_bucket_acl resource for import: schema.Resource{"bucket":"tfedit-test"}
+# WARNING: aws_s3_bucket_acl.variable needs a plan to derive the import ID: the bucket name of aws_s3_bucket.variable is not a literal string
+# WARNING: aws_s3_bucket_acl.count needs a plan to derive the import ID: aws_s3_bucket.count uses count or for_each
+migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_policy.example tfedit-test",
+  ]
+}
+`,
+		},
+		{
+			name: "dynamic",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+
+  dynamic "versioning" {
+    for_each = var.versioning
+    content {
+      enabled = versioning.value.enabled
+    }
+  }
+}
+`,
+			ok: true,
+			want: `# WARNING: aws_s3_bucket_versioning.example needs a plan to derive the import ID: aws_s3_bucket_versioning.example is created conditionally by count
+migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+		},
+		{
+			name: "already split",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+  policy = "{}"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`,
+			ok: true,
+			want: `# WARNING: aws_s3_bucket.example was not split to aws_s3_bucket_acl.example because it already exists. Please merge the arguments left in aws_s3_bucket.example by yourself.
+migration "state" "awsv4upgrade" {
+  actions = [
+    "import aws_s3_bucket_policy.example tfedit-test",
+  ]
+}
+`,
+		},
+		{
+			name: "no split",
+			src: `
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+`,
+			ok:   true,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := NewSplitRecorder()
+			o := editor.NewEditOperator(NewAllFilterWithOption(&Option{Recorder: recorder, OwnershipControls: tc.ownershipControls}))
+			if _, err := o.Apply([]byte(tc.src), "test"); err != nil {
+				t.Fatalf("failed to apply filter: %s", err)
+			}
+
+			m := recorder.Migration(migration.NewDefaultDictionary(), "")
+			output, err := m.Render()
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package migration
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SortActions sorts actions in a stable order for reviewability, so that the
+// same input always results in the same migration file regardless of the
+// order of changes in a plan or resolvers.
+// The actions are sorted by module path, then source resource, then action
+// type and address. The source resource is the group of the action if any,
+// otherwise the resource itself. The action type is ordered as mv, rm and
+// import, so that imports don't conflict with addresses which are moved or
+// removed.
+func (m *StateMigration) SortActions() {
+	keys := make(map[StateAction]actionSortKey, len(m.Actions))
+	for _, a := range m.Actions {
+		keys[a] = m.sortKey(a)
+	}
+
+	sort.SliceStable(m.Actions, func(i, j int) bool {
+		return keys[m.Actions[i]].less(keys[m.Actions[j]])
+	})
+}
+
+// actionSortKey is a key for sorting actions.
+type actionSortKey struct {
+	module  string
+	source  string
+	rank    int
+	address string
+}
+
+// less returns true if k should be sorted before other.
+func (k actionSortKey) less(other actionSortKey) bool {
+	if k.module != other.module {
+		return k.module < other.module
+	}
+	if k.source != other.source {
+		return k.source < other.source
+	}
+	if k.rank != other.rank {
+		return k.rank < other.rank
+	}
+	return k.address < other.address
+}
+
+// sortKey returns a key for sorting a given action.
+func (m *StateMigration) sortKey(a StateAction) actionSortKey {
+	address := actionAddress(a)
+	var rank int
+	switch a.(type) {
+	case *StateMvAction:
+		rank = 0
+	case *StateRmAction:
+		rank = 1
+	case *StateImportAction:
+		rank = 2
+	default:
+		rank = 3
+	}
+
+	module, source := splitModuleAddress(address)
+	if group := m.Group(a); group != "" {
+		module, source = splitModuleAddress(group)
+	}
+
+	return actionSortKey{
+		module:  module,
+		source:  source,
+		rank:    rank,
+		address: address,
+	}
+}
+
+// actionAddress returns an address which a given action mainly operates on.
+// It's a source address for mv and the first address for rm.
+func actionAddress(a StateAction) string {
+	switch t := a.(type) {
+	case *StateMvAction:
+		return t.source
+	case *StateRmAction:
+		if len(t.addresses) > 0 {
+			return t.addresses[0]
+		}
+		return ""
+	case *StateImportAction:
+		return t.address
+	default:
+		return a.MigrationAction()
+	}
+}
+
+// splitModuleAddress splits a given absolute address into a module path and
+// a relative address in the module.
+// (e.g. module.foo["a"].aws_s3_bucket.example => module.foo["a"], aws_s3_bucket.example)
+// If the address cannot be parsed, it's treated as a resource in the root module.
+func splitModuleAddress(address string) (string, string) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return "", address
+	}
+
+	i := 0
+	for i+1 < len(traversal) && traverserName(traversal[i]) == "module" {
+		i += 2
+		if i < len(traversal) {
+			if _, ok := traversal[i].(hcl.TraverseIndex); ok {
+				i++
+			}
+		}
+	}
+
+	if i == 0 || i >= len(traversal) {
+		return "", address
+	}
+
+	// The source range of an attribute traverser includes a leading dot.
+	start := traversal[i].SourceRange().Start.Byte
+	return address[:start], address[start+1:]
+}
+
+// traverserName returns a name of a given traverser if it's a root or an
+// attribute, otherwise an empty string.
+func traverserName(t hcl.Traverser) string {
+	switch v := t.(type) {
+	case hcl.TraverseRoot:
+		return v.Name
+	case hcl.TraverseAttr:
+		return v.Name
+	}
+	return ""
+}
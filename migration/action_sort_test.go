@@ -0,0 +1,176 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitModuleAddress(t *testing.T) {
+	cases := []struct {
+		desc    string
+		address string
+		module  string
+		source  string
+	}{
+		{
+			desc:    "root",
+			address: "aws_s3_bucket.example",
+			module:  "",
+			source:  "aws_s3_bucket.example",
+		},
+		{
+			desc:    "module",
+			address: "module.foo.aws_s3_bucket.example",
+			module:  "module.foo",
+			source:  "aws_s3_bucket.example",
+		},
+		{
+			desc:    "nested module instance",
+			address: `module.foo["a.b"].module.bar[0].aws_s3_bucket.example["c"]`,
+			module:  `module.foo["a.b"].module.bar[0]`,
+			source:  `aws_s3_bucket.example["c"]`,
+		},
+		{
+			desc:    "module only",
+			address: "module.foo",
+			module:  "",
+			source:  "module.foo",
+		},
+		{
+			desc:    "invalid",
+			address: "module.foo[",
+			module:  "",
+			source:  "module.foo[",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			module, source := splitModuleAddress(tc.address)
+			if module != tc.module || source != tc.source {
+				t.Errorf("got = (%s, %s), but want = (%s, %s)", module, source, tc.module, tc.source)
+			}
+		})
+	}
+}
+
+func TestStateMigrationSortActions(t *testing.T) {
+	type groupedAction struct {
+		group  string
+		action StateAction
+	}
+
+	cases := []struct {
+		desc          string
+		actions       []groupedAction
+		mode          GenerateMode
+		groupComments bool
+		want          string
+	}{
+		{
+			desc: "sort by module, source resource and action type",
+			actions: []groupedAction{
+				{group: "aws_s3_bucket.foo", action: NewStateImportAction("aws_s3_bucket_versioning.foo", "foo")},
+				{group: "module.bar.aws_s3_bucket.bar", action: NewStateImportAction("module.bar.aws_s3_bucket_acl.bar", "bar,private")},
+				{group: "aws_s3_bucket.foo", action: NewStateImportAction("aws_s3_bucket_acl.foo", "foo,private")},
+				{group: "", action: NewStateImportAction("aws_s3_object.baz", "baz/key")},
+				{group: "", action: NewStateMvAction("aws_s3_bucket.old", "aws_s3_bucket.new")},
+				{group: "aws_s3_bucket_object.baz", action: NewStateRmAction([]string{"aws_s3_bucket_object.baz"})},
+				{group: "aws_s3_bucket_object.baz", action: NewStateImportAction("aws_s3_object.baz", "baz/key")},
+			},
+			want: `migration "state" "mytest" {
+  actions = [
+    "import aws_s3_bucket_acl.foo foo,private",
+    "import aws_s3_bucket_versioning.foo foo",
+    "mv aws_s3_bucket.old aws_s3_bucket.new",
+    "rm aws_s3_bucket_object.baz",
+    "import aws_s3_object.baz baz/key",
+    "import aws_s3_object.baz baz/key",
+    "import module.bar.aws_s3_bucket_acl.bar bar,private",
+  ]
+}
+`,
+		},
+		{
+			desc: "group comments",
+			actions: []groupedAction{
+				{group: "aws_s3_bucket.foo", action: NewStateImportAction("aws_s3_bucket_versioning.foo", "foo")},
+				{group: "aws_s3_bucket.bar", action: NewStateImportActionWithComment("aws_s3_bucket_acl.bar", "bar,private", "this is a comment")},
+				{group: "aws_s3_bucket.foo", action: NewStateImportAction("aws_s3_bucket_acl.foo", "foo,private")},
+				{group: "", action: NewStateImportAction("aws_s3_object.baz", "baz/key")},
+			},
+			groupComments: true,
+			want: `migration "state" "mytest" {
+  actions = [
+    # Source: aws_s3_bucket.bar
+    # this is a comment
+    "import aws_s3_bucket_acl.bar bar,private",
+    # Source: aws_s3_bucket.foo
+    "import aws_s3_bucket_acl.foo foo,private",
+    "import aws_s3_bucket_versioning.foo foo",
+    "import aws_s3_object.baz baz/key",
+  ]
+}
+`,
+		},
+		{
+			desc: "group comments in config mode",
+			actions: []groupedAction{
+				{group: `aws_s3_bucket.foo["b"]`, action: NewStateImportAction(`aws_s3_bucket_acl.foo["b"]`, "b,private")},
+				{group: `aws_s3_bucket.foo["a"]`, action: NewStateImportAction(`aws_s3_bucket_acl.foo["a"]`, "a,private")},
+				{group: "aws_s3_bucket_object.baz", action: NewStateRmAction([]string{"aws_s3_bucket_object.baz"})},
+				{group: "aws_s3_bucket_object.baz", action: NewStateImportAction("aws_s3_object.baz", "baz/key")},
+			},
+			mode:          GenerateModeConfig,
+			groupComments: true,
+			want: `# WARNING: The import IDs of aws_s3_bucket_acl.foo are written as a literal collection because the for_each or count expression cannot be used: the configuration is not available
+
+# Source: aws_s3_bucket.foo
+import {
+  for_each = {
+    a = "a,private"
+    b = "b,private"
+  }
+  to = aws_s3_bucket_acl.foo[each.key]
+  id = each.value
+}
+
+# Source: aws_s3_bucket_object.baz
+removed {
+  from = aws_s3_bucket_object.baz
+
+  lifecycle {
+    destroy = false
+  }
+}
+
+import {
+  to = aws_s3_object.baz
+  id = "baz/key"
+}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			m := NewStateMigration("mytest", "")
+			m.GroupComments = tc.groupComments
+			for _, a := range tc.actions {
+				m.AppendGroupedActions(a.group, a.action)
+			}
+			m.SortActions()
+
+			output, err := RenderMigration(m, tc.mode)
+			if err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
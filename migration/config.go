@@ -0,0 +1,389 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/migration/schema"
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Config is a static model of Terraform configuration loaded from a
+// directory without Terraform. It's used for generating a migration from a
+// configuration diff when we cannot run terraform plan.
+// Only managed resources in the root module and local child modules are
+// tracked.
+type Config struct {
+	// A dictionary for provider schema.
+	dictionary *schema.Dictionary
+	// A map of resources indexed by absolute address.
+	resources map[string]*configResource
+	// A list of problems found while loading the configuration, such as
+	// modules which cannot be analyzed statically.
+	warnings []string
+}
+
+// configResource is a resource block in Config.
+type configResource struct {
+	// An absolute address. (e.g. module.foo.aws_s3_bucket.example)
+	address string
+	// An address prefix of the module which contains the resource.
+	// It's empty for the root module. (e.g. module.foo.)
+	module string
+	// A resource type. (e.g. aws_s3_bucket)
+	resourceType string
+	// A resource name. (e.g. example)
+	name string
+	// A meta argument which expands the resource into multiple instances.
+	// The valid values are count, for_each or empty.
+	expansion string
+	// A raw resource block.
+	block *tfwrite.Resource
+	// Statically known values of top-level attributes.
+	values map[string]cty.Value
+}
+
+// LoadConfig loads Terraform configuration files (*.tf) in a given directory
+// and local child modules called from it.
+// The dictionary is used for resolving references to an id of resources.
+func LoadConfig(dir string, d *schema.Dictionary) (*Config, error) {
+	c := &Config{
+		dictionary: d,
+		resources:  make(map[string]*configResource),
+		warnings:   []string{},
+	}
+
+	if err := c.loadModule(dir, "", map[string]cty.Value{}); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Addresses returns a sorted list of addresses of resources.
+func (c *Config) Addresses() []string {
+	ret := make([]string, 0, len(c.resources))
+	for addr := range c.resources {
+		ret = append(ret, addr)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// configModule is a set of blocks in a module directory used for evaluation.
+type configModule struct {
+	variables map[string]cty.Value
+	locals    map[string]*tfwrite.Attribute
+	resources []*configResource
+	modules   []*tfwrite.Module
+}
+
+// loadModule loads a module in a given directory with a given address prefix
+// and input variables.
+func (c *Config) loadModule(dir string, prefix string, inputs map[string]cty.Value) error {
+	m, err := c.parseModule(dir, prefix)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range inputs {
+		m.variables[k] = v
+	}
+
+	ctx := c.evaluateModule(m)
+
+	for _, r := range m.resources {
+		c.resources[r.address] = r
+	}
+
+	for _, mc := range m.modules {
+		name := mc.SchemaType()
+		address := prefix + "module." + name
+		source, ok := evaluateAttribute(mc.GetAttribute("source"), nil)
+		if !ok || source.Type() != cty.String {
+			c.warnings = append(c.warnings, fmt.Sprintf("The source of %s is not a literal string, so it cannot be analyzed statically.", address))
+			continue
+		}
+		if !isLocalModuleSource(source.AsString()) {
+			c.warnings = append(c.warnings, fmt.Sprintf("The source of %s is not a local path, so it cannot be analyzed statically: %s", address, source.AsString()))
+			continue
+		}
+		if mc.GetAttribute("count") != nil || mc.GetAttribute("for_each") != nil {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s uses count or for_each, which is not supported for static analysis.", address))
+			continue
+		}
+
+		args := map[string]cty.Value{}
+		for name, attr := range mc.Raw().Body().Attributes() {
+			switch name {
+			case "source", "version", "providers", "depends_on":
+				continue
+			}
+			if v, ok := evaluateAttribute(tfwrite.NewAttribute(attr), ctx); ok {
+				args[name] = v
+			}
+		}
+
+		if err := c.loadModule(filepath.Join(dir, source.AsString()), address+".", args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseModule parses configuration files in a given directory.
+func (c *Config) parseModule(dir string, prefix string) (*configModule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %s", err)
+	}
+
+	m := &configModule{
+		variables: map[string]cty.Value{},
+		locals:    map[string]*tfwrite.Attribute{},
+		resources: []*configResource{},
+		modules:   []*tfwrite.Module{},
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		filename := filepath.Join(dir, e.Name())
+		if strings.HasSuffix(e.Name(), ".tf.json") {
+			c.warnings = append(c.warnings, fmt.Sprintf("JSON configuration files are not supported, so it's ignored: %s", filename))
+			continue
+		}
+		if !strings.HasSuffix(e.Name(), ".tf") {
+			continue
+		}
+
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %s", err)
+		}
+		raw, diags := hclwrite.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse file: %s", diags)
+		}
+
+		for _, b := range tfwrite.NewFile(raw).Blocks() {
+			switch block := b.(type) {
+			case *tfwrite.Resource:
+				m.resources = append(m.resources, newConfigResource(block, prefix))
+			case *tfwrite.Variable:
+				if v, ok := evaluateAttribute(block.GetAttribute("default"), nil); ok {
+					m.variables[block.SchemaType()] = v
+				}
+			case *tfwrite.Locals:
+				for name, attr := range block.Raw().Body().Attributes() {
+					m.locals[name] = tfwrite.NewAttribute(attr)
+				}
+			case *tfwrite.Module:
+				m.modules = append(m.modules, block)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// newConfigResource returns a new instance of configResource.
+func newConfigResource(block *tfwrite.Resource, prefix string) *configResource {
+	expansion := ""
+	switch {
+	case block.Count() != nil:
+		expansion = "count"
+	case block.ForEach() != nil:
+		expansion = "for_each"
+	}
+
+	return &configResource{
+		address:      prefix + block.SchemaType() + "." + block.Name(),
+		module:       prefix,
+		resourceType: block.SchemaType(),
+		name:         block.Name(),
+		expansion:    expansion,
+		block:        block,
+		values:       map[string]cty.Value{},
+	}
+}
+
+// evaluateModule evaluates locals and resource attributes in a given
+// module as much as possible, and returns an evaluation context for the
+// module. Since they can refer to each other, we repeat evaluation until no
+// more value is known.
+func (c *Config) evaluateModule(m *configModule) *hcl.EvalContext {
+	locals := map[string]cty.Value{}
+	for {
+		ctx := c.newEvalContext(m, locals)
+		changed := false
+
+		for name, attr := range m.locals {
+			if _, ok := locals[name]; ok {
+				continue
+			}
+			if v, ok := evaluateAttribute(attr, ctx); ok {
+				locals[name] = v
+				changed = true
+			}
+		}
+
+		for _, r := range m.resources {
+			for name, attr := range r.block.Raw().Body().Attributes() {
+				if _, ok := r.values[name]; ok {
+					continue
+				}
+				if v, ok := evaluateAttribute(tfwrite.NewAttribute(attr), ctx); ok {
+					r.values[name] = v
+					changed = true
+				}
+			}
+		}
+
+		if !changed {
+			return ctx
+		}
+	}
+}
+
+// newEvalContext returns an evaluation context which contains known
+// values of variables, locals and resources in a given module.
+// The id of a resource is assumed to be the same as its import ID, which is
+// true for most resource types.
+func (c *Config) newEvalContext(m *configModule, locals map[string]cty.Value) *hcl.EvalContext {
+	resources := map[string]map[string]cty.Value{}
+	for _, r := range m.resources {
+		// An expanded resource must be referenced with an instance key, which
+		// is unknown statically.
+		if r.expansion != "" {
+			continue
+		}
+
+		values := map[string]cty.Value{}
+		for k, v := range r.values {
+			values[k] = v
+		}
+		if _, ok := values["id"]; !ok {
+			if id, err := c.dictionary.ImportID(r.resourceType, r.schemaResource()); err == nil {
+				values["id"] = cty.StringVal(id)
+			}
+		}
+
+		if _, ok := resources[r.resourceType]; !ok {
+			resources[r.resourceType] = map[string]cty.Value{}
+		}
+		resources[r.resourceType][r.name] = cty.ObjectVal(values)
+	}
+
+	vars := map[string]cty.Value{
+		"var":   cty.ObjectVal(m.variables),
+		"local": cty.ObjectVal(locals),
+	}
+	for t, rs := range resources {
+		vars[t] = cty.ObjectVal(rs)
+	}
+
+	return &hcl.EvalContext{
+		Variables: vars,
+	}
+}
+
+// evaluateAttribute evaluates a given attribute with a given context.
+// It returns false if the value cannot be known statically.
+func evaluateAttribute(attr *tfwrite.Attribute, ctx *hcl.EvalContext) (cty.Value, bool) {
+	if attr == nil {
+		return cty.NilVal, false
+	}
+
+	expr, diags := hclsyntax.ParseExpression(attr.ValueAsTokens().Bytes(), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+
+	v, diags := expr.Value(ctx)
+	if diags.HasErrors() || !v.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+
+	return v, true
+}
+
+// isLocalModuleSource returns true if a given module source is a local path.
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// schemaResource returns statically known attributes of the resource as a
+// schema.Resource.
+func (r *configResource) schemaResource() schema.Resource {
+	values := map[string]cty.Value{}
+	for k, v := range r.values {
+		if !v.IsNull() {
+			values[k] = v
+		}
+	}
+	obj := cty.ObjectVal(values)
+	b, err := ctyjson.Marshal(obj, obj.Type())
+	if err != nil {
+		return schema.Resource{}
+	}
+
+	var ret map[string]interface{}
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return schema.Resource{}
+	}
+	return schema.Resource(ret)
+}
+
+// signature returns a string which identifies the body of the resource
+// regardless of formatting. It's used for finding the same resource between
+// configurations. Statically known attributes are compared by value, so that
+// a literal replaced with a variable of the same value is still the same.
+func (r *configResource) signature() string {
+	lines := []string{}
+	for name, attr := range r.block.Raw().Body().Attributes() {
+		if v, ok := r.values[name]; ok {
+			b, err := ctyjson.Marshal(v, v.Type())
+			if err == nil {
+				lines = append(lines, name+"="+string(b))
+				continue
+			}
+		}
+		lines = append(lines, name+"~"+stripSpaces(attr.Expr().BuildTokens(nil).Bytes()))
+	}
+	for _, b := range r.block.NestedBlocks() {
+		lines = append(lines, b.Type()+"{"+stripSpaces(b.Raw().Body().BuildTokens(nil).Bytes())+"}")
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// stripSpaces returns a given source as a string without whitespace.
+func stripSpaces(src []byte) string {
+	return strings.Join(strings.Fields(string(src)), "")
+}
+
+// argumentNames returns a set of names of top-level attributes and nested
+// blocks of the resource.
+func (r *configResource) argumentNames() map[string]bool {
+	ret := map[string]bool{}
+	for name := range r.block.Raw().Body().Attributes() {
+		ret[name] = true
+	}
+	for _, b := range r.block.NestedBlocks() {
+		ret[b.Type()] = true
+	}
+	return ret
+}
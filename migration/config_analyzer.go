@@ -0,0 +1,314 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+// ConfigAnalyzer is an interface that abstracts the analysis rules of
+// configuration diff.
+type ConfigAnalyzer interface {
+	// Analyze compares given configurations before and after refactoring, and
+	// generates a state migration so that the state matches the configuration
+	// after refactoring.
+	// The dir is set to a dir attribute in a migration file.
+	Analyze(before *Config, after *Config, dir string) (*StateMigration, error)
+}
+
+// defaultConfigAnalyzer is a default implementation for ConfigAnalyzer.
+// This is a predefined rule-based analyzer. Since it doesn't call Terraform,
+// it only detects typical refactoring patterns such as renaming, moving into
+// modules, splitting and changing resource types. Anything that can't be
+// derived statically is reported as a warning.
+type defaultConfigAnalyzer struct {
+	// A dictionary for provider schema.
+	dictionary *schema.Dictionary
+	// If true, the only removed and added resources of the same type in the
+	// same module are moved as renamed even if their arguments differ.
+	// Otherwise, they are only reported as a warning.
+	guessRenames bool
+}
+
+var _ ConfigAnalyzer = (*defaultConfigAnalyzer)(nil)
+
+// NewDefaultConfigAnalyzer returns a new instance of defaultConfigAnalyzer.
+func NewDefaultConfigAnalyzer(d *schema.Dictionary) ConfigAnalyzer {
+	return NewDefaultConfigAnalyzerWithRenameGuess(d, false)
+}
+
+// NewDefaultConfigAnalyzerWithRenameGuess returns a new instance of
+// defaultConfigAnalyzer. If guessRenames is true, the only removed and added
+// resources of the same type in the same module are moved as renamed even if
+// their arguments differ. A wrong guess moves the state of a resource to
+// another, so it's disabled by default and only reported as a warning.
+func NewDefaultConfigAnalyzerWithRenameGuess(d *schema.Dictionary, guessRenames bool) ConfigAnalyzer {
+	return &defaultConfigAnalyzer{
+		dictionary:   d,
+		guessRenames: guessRenames,
+	}
+}
+
+// configDiff is a working set for analyzing configuration diff.
+type configDiff struct {
+	before *Config
+	after  *Config
+	// A list of resources which only exist in before and are not resolved yet.
+	removed []*configResource
+	// A list of resources which only exist in after and are not resolved yet.
+	added []*configResource
+	// A map of addresses in after indexed by addresses in before.
+	// It contains resources which exist in both and moved resources.
+	moved map[string]string
+	// A state migration to be generated.
+	migration *StateMigration
+}
+
+// Analyze compares given configurations before and after refactoring, and
+// generates a state migration so that the state matches the configuration
+// after refactoring.
+// The dir is set to a dir attribute in a migration file.
+func (a *defaultConfigAnalyzer) Analyze(before *Config, after *Config, dir string) (*StateMigration, error) {
+	d := &configDiff{
+		before:    before,
+		after:     after,
+		removed:   []*configResource{},
+		added:     []*configResource{},
+		moved:     map[string]string{},
+		migration: NewStateMigration("fromconfig", dir),
+	}
+
+	seen := map[string]bool{}
+	for _, w := range append(before.warnings, after.warnings...) {
+		if !seen[w] {
+			d.migration.AppendWarnings(w)
+			seen[w] = true
+		}
+	}
+
+	for _, addr := range before.Addresses() {
+		if _, ok := after.resources[addr]; ok {
+			d.moved[addr] = addr
+			continue
+		}
+		d.removed = append(d.removed, before.resources[addr])
+	}
+	for _, addr := range after.Addresses() {
+		if _, ok := before.resources[addr]; !ok {
+			d.added = append(d.added, after.resources[addr])
+		}
+	}
+
+	// Renamed or moved into modules without changes.
+	for _, p := range d.matchUnique(func(r *configResource, c *configResource) bool {
+		return r.resourceType == c.resourceType && r.signature() == c.signature()
+	}) {
+		d.mv(p[0], p[1])
+	}
+
+	// Moved into modules with changes.
+	for _, p := range d.matchUnique(func(r *configResource, c *configResource) bool {
+		return r.resourceType == c.resourceType && r.name == c.name
+	}) {
+		d.mv(p[0], p[1])
+	}
+
+	// Renamed with changes. It's a guess, so it's moved only if opted in.
+	// Otherwise, let the user know and leave it to them.
+	for _, p := range d.matchUnique(func(r *configResource, c *configResource) bool {
+		return r.resourceType == c.resourceType && r.module == c.module
+	}) {
+		if !a.guessRenames {
+			d.migration.AppendWarnings(fmt.Sprintf("%s seems to be renamed to %s because they are the only removed and added resources of the type, but it's not moved because their arguments differ. Please move it by yourself if it's correct.", p[0].address, p[1].address))
+			continue
+		}
+		d.migration.AppendWarnings(fmt.Sprintf("%s is assumed to be renamed to %s because they are the only removed and added resources of the type. Please make sure it's correct.", p[0].address, p[1].address))
+		d.mv(p[0], p[1])
+	}
+
+	// Resource type changed. A resource cannot be moved to another type, so
+	// remove it from the state and import it as the new type.
+	for _, p := range d.matchUnique(func(r *configResource, c *configResource) bool {
+		return r.resourceType != c.resourceType && r.module == c.module && r.name == c.name
+	}) {
+		if id, ok := a.importID(d, p[1]); ok {
+			d.migration.AppendGroupedActions(p[0].address,
+				NewStateRmAction([]string{p[0].address}),
+				NewStateImportAction(p[1].address, id),
+			)
+		}
+	}
+
+	// Split from an existing resource.
+	remained := []*configResource{}
+	for _, r := range d.added {
+		parent := d.splitParent(r)
+		if parent == "" {
+			remained = append(remained, r)
+			continue
+		}
+		if id, ok := a.importID(d, r); ok {
+			d.migration.AppendGroupedActions(parent, NewStateImportAction(r.address, id))
+		}
+	}
+	d.added = remained
+
+	for _, r := range d.removed {
+		d.migration.AppendWarnings(fmt.Sprintf("%s was removed from the configuration and will be destroyed.", r.address))
+	}
+	for _, r := range d.added {
+		d.migration.AppendWarnings(fmt.Sprintf("%s was added to the configuration and will be created.", r.address))
+	}
+
+	d.migration.SortActions()
+
+	return d.migration, nil
+}
+
+// matchUnique returns pairs of removed and added resources which match
+// only each other with a given rule, and removes them from the working set.
+func (d *configDiff) matchUnique(match func(r *configResource, c *configResource) bool) [][2]*configResource {
+	candidates := func(r *configResource, rs []*configResource, reverse bool) []*configResource {
+		ret := []*configResource{}
+		for _, c := range rs {
+			if (!reverse && match(r, c)) || (reverse && match(c, r)) {
+				ret = append(ret, c)
+			}
+		}
+		return ret
+	}
+
+	pairs := [][2]*configResource{}
+	matched := map[*configResource]bool{}
+	for _, r := range d.removed {
+		cs := candidates(r, d.added, false)
+		if len(cs) != 1 {
+			continue
+		}
+		if rs := candidates(cs[0], d.removed, true); len(rs) != 1 {
+			continue
+		}
+		pairs = append(pairs, [2]*configResource{r, cs[0]})
+		matched[r] = true
+		matched[cs[0]] = true
+	}
+
+	d.removed = excludeConfigResources(d.removed, matched)
+	d.added = excludeConfigResources(d.added, matched)
+	return pairs
+}
+
+// excludeConfigResources returns a list of resources without given ones.
+func excludeConfigResources(rs []*configResource, excluded map[*configResource]bool) []*configResource {
+	ret := []*configResource{}
+	for _, r := range rs {
+		if !excluded[r] {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+// mv appends a mv action for given resources.
+// If the count or for_each differs, instance keys cannot be moved as they
+// are, so it reports a warning instead.
+func (d *configDiff) mv(from *configResource, to *configResource) {
+	if from.expansion != to.expansion {
+		d.migration.AppendWarnings(fmt.Sprintf("%s seems to be moved to %s, but the count or for_each differs, so it cannot be moved statically.", from.address, to.address))
+		return
+	}
+	d.moved[from.address] = to.address
+	d.migration.AppendActions(NewStateMvAction(from.address, to.address))
+}
+
+// splitParent returns an address of a resource in after from which a given
+// added resource is split, or an empty string if not found.
+// An added resource is considered to be split if it refers to an existing
+// resource which lost some arguments.
+func (d *configDiff) splitParent(r *configResource) string {
+	sources := map[string]string{}
+	for from, to := range d.moved {
+		sources[to] = from
+	}
+
+	for _, ref := range r.block.References() {
+		parts := strings.Split(ref, ".")
+		if len(parts) < 2 {
+			continue
+		}
+		name, _, _ := strings.Cut(parts[1], "[")
+		address := r.module + parts[0] + "." + name
+
+		parent, ok := d.after.resources[address]
+		if !ok {
+			continue
+		}
+		source, ok := sources[address]
+		if !ok {
+			continue
+		}
+
+		current := parent.argumentNames()
+		for arg := range d.before.resources[source].argumentNames() {
+			if !current[arg] {
+				return address
+			}
+		}
+	}
+
+	return ""
+}
+
+// importID returns an import ID for a given resource derived from
+// statically known values. If it cannot be derived, it reports a warning and
+// returns false.
+func (a *defaultConfigAnalyzer) importID(d *configDiff, r *configResource) (string, bool) {
+	if r.expansion != "" {
+		d.migration.AppendWarnings(fmt.Sprintf("%s needs to be imported, but it uses %s, so the import IDs of instances cannot be derived statically.", r.address, r.expansion))
+		return "", false
+	}
+
+	id, err := a.dictionary.ImportID(r.resourceType, r.schemaResource())
+	if err != nil {
+		d.migration.AppendWarnings(fmt.Sprintf("%s needs to be imported, but the import ID cannot be derived statically: %s", r.address, err))
+		return "", false
+	}
+
+	return id, true
+}
+
+// GenerateFromConfig returns bytes of a migration file generated by comparing
+// configurations in given directories before and after refactoring.
+// It also returns a list of warnings for changes which cannot be derived
+// statically. They are written as comments in the migration file too, but
+// nothing is written when no action is found.
+func GenerateFromConfig(beforeDir string, afterDir string, o *GenerateOption) ([]byte, []string, error) {
+	dictionary := o.Dictionary
+	if dictionary == nil {
+		dictionary = NewDefaultDictionary()
+	}
+
+	before, err := LoadConfig(beforeDir, dictionary)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := LoadConfig(afterDir, dictionary)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	analyzer := NewDefaultConfigAnalyzerWithRenameGuess(dictionary, o.GuessRenames)
+	migration, err := analyzer.Analyze(before, after, o.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	migration.GroupComments = o.GroupComments
+
+	output, err := RenderMigration(migration, o.Mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output, migration.Warnings, nil
+}
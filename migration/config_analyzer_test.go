@@ -0,0 +1,153 @@
+package migration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateFromConfig(t *testing.T) {
+	cases := []struct {
+		desc         string
+		fixture      string
+		mode         GenerateMode
+		guessRenames bool
+		ok           bool
+		want         string
+		warnings     []string
+	}{
+		{
+			desc:    "rename",
+			fixture: "rename",
+			ok:      true,
+			want: `migration "state" "fromconfig" {
+  actions = [
+    "mv aws_s3_bucket.example aws_s3_bucket.renamed",
+  ]
+}
+`,
+			warnings: nil,
+		},
+		{
+			desc:    "rename with changes",
+			fixture: "rename_with_changes",
+			ok:      true,
+			want:    "",
+			warnings: []string{
+				"aws_s3_bucket.example seems to be renamed to aws_s3_bucket.renamed because they are the only removed and added resources of the type, but it's not moved because their arguments differ. Please move it by yourself if it's correct.",
+			},
+		},
+		{
+			desc:         "guess rename with changes",
+			fixture:      "rename_with_changes",
+			guessRenames: true,
+			ok:           true,
+			want: `# WARNING: aws_s3_bucket.example is assumed to be renamed to aws_s3_bucket.renamed because they are the only removed and added resources of the type. Please make sure it's correct.
+migration "state" "fromconfig" {
+  actions = [
+    "mv aws_s3_bucket.example aws_s3_bucket.renamed",
+  ]
+}
+`,
+			warnings: []string{
+				"aws_s3_bucket.example is assumed to be renamed to aws_s3_bucket.renamed because they are the only removed and added resources of the type. Please make sure it's correct.",
+			},
+		},
+		{
+			desc:    "move into module",
+			fixture: "module",
+			ok:      true,
+			want: `migration "state" "fromconfig" {
+  actions = [
+    "mv aws_s3_bucket.example module.bucket.aws_s3_bucket.this",
+  ]
+}
+`,
+			warnings: nil,
+		},
+		{
+			desc:    "split",
+			fixture: "split",
+			ok:      true,
+			want: `migration "state" "fromconfig" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+			warnings: nil,
+		},
+		{
+			desc:    "type changed",
+			fixture: "type_changed",
+			ok:      true,
+			want: `migration "state" "fromconfig" {
+  actions = [
+    "rm aws_s3_bucket_object.example",
+    "import aws_s3_object.example tfedit-test/foo.txt",
+  ]
+}
+`,
+			warnings: nil,
+		},
+		{
+			desc:    "type changed in config mode",
+			fixture: "type_changed",
+			mode:    GenerateModeConfig,
+			ok:      true,
+			want: `removed {
+  from = aws_s3_bucket_object.example
+
+  lifecycle {
+    destroy = false
+  }
+}
+
+import {
+  to = aws_s3_object.example
+  id = "tfedit-test/foo.txt"
+}
+`,
+			warnings: nil,
+		},
+		{
+			desc:    "unresolved",
+			fixture: "unresolved",
+			ok:      true,
+			want:    "",
+			warnings: []string{
+				"The source of module.remote is not a local path, so it cannot be analyzed statically: example/bucket/aws",
+				"aws_s3_bucket_acl.example needs to be imported, but the import ID cannot be derived statically: failed to cast bucket = <nil> to string as an element of import ID",
+				"aws_s3_bucket.old was removed from the configuration and will be destroyed.",
+				"aws_s3_bucket_policy.new was added to the configuration and will be created.",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := filepath.Join("test-fixtures", "fromconfig", tc.fixture)
+			o := &GenerateOption{
+				Mode:         tc.mode,
+				GuessRenames: tc.guessRenames,
+			}
+			output, warnings, err := GenerateFromConfig(filepath.Join(dir, "before"), filepath.Join(dir, "after"), o)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+			if diff := cmp.Diff(warnings, tc.warnings); diff != "" {
+				t.Errorf("got warnings: %#v, want: %#v, diff:\n%s", warnings, tc.warnings, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cases := []struct {
+		desc      string
+		dir       string
+		ok        bool
+		addresses []string
+		values    map[string]schema.Resource
+	}{
+		{
+			desc:      "locals and references",
+			dir:       "test-fixtures/fromconfig/split/after",
+			ok:        true,
+			addresses: []string{"aws_s3_bucket.example", "aws_s3_bucket_acl.example"},
+			values: map[string]schema.Resource{
+				"aws_s3_bucket.example":     {"bucket": "tfedit-test"},
+				"aws_s3_bucket_acl.example": {"bucket": "tfedit-test", "acl": "private"},
+			},
+		},
+		{
+			desc:      "local module",
+			dir:       "test-fixtures/fromconfig/module/after",
+			ok:        true,
+			addresses: []string{"module.bucket.aws_s3_bucket.this"},
+			values: map[string]schema.Resource{
+				"module.bucket.aws_s3_bucket.this": {"bucket": "tfedit-test"},
+			},
+		},
+		{
+			desc:      "unknown variable",
+			dir:       "test-fixtures/fromconfig/unresolved/after",
+			ok:        true,
+			addresses: []string{"aws_s3_bucket.example", "aws_s3_bucket_acl.example", "aws_s3_bucket_policy.new"},
+			values: map[string]schema.Resource{
+				"aws_s3_bucket.example":     {},
+				"aws_s3_bucket_acl.example": {"acl": "private"},
+				"aws_s3_bucket_policy.new":  {"bucket": "tfedit-new", "policy": "{}"},
+			},
+		},
+		{
+			desc: "not found",
+			dir:  "test-fixtures/fromconfig/not_found",
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := LoadConfig(tc.dir, NewDefaultDictionary())
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error")
+				}
+				return
+			}
+
+			if diff := cmp.Diff(c.Addresses(), tc.addresses); diff != "" {
+				t.Errorf("got addresses: %#v, want: %#v, diff:\n%s", c.Addresses(), tc.addresses, diff)
+			}
+
+			for addr, want := range tc.values {
+				got := c.resources[addr].schemaResource()
+				if diff := cmp.Diff(got, want); diff != "" {
+					t.Errorf("got values of %s: %#v, want: %#v, diff:\n%s", addr, got, want, diff)
+				}
+			}
+		})
+	}
+}
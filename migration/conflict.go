@@ -2,6 +2,7 @@ package migration
 
 import (
 	"fmt"
+	"strconv"
 
 	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/minamijoyo/tfedit/migration/schema"
@@ -72,3 +73,78 @@ func (c *Conflict) ResourceAfter() (schema.Resource, error) {
 
 	return schema.Resource(after), nil
 }
+
+// ResourceAfterMasked returns a planned resource after change in which
+// sensitive values are replaced with placeholders.
+// The mask function is called with a path of each sensitive attribute
+// (e.g. password, foo.0.bar) and returns its placeholder.
+func (c *Conflict) ResourceAfterMasked(mask func(path string) string) (schema.Resource, error) {
+	resource, err := c.ResourceAfter()
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive := c.rc.Change.AfterSensitive
+	// If the whole object is sensitive, mask all attributes.
+	if b, ok := sensitive.(bool); ok && b {
+		all := make(map[string]interface{}, len(resource))
+		for k := range resource {
+			all[k] = true
+		}
+		sensitive = all
+	}
+
+	masked, ok := maskSensitiveValue(map[string]interface{}(resource), sensitive, "", mask).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to mask sensitive values: %#v", resource)
+	}
+
+	return schema.Resource(masked), nil
+}
+
+// maskSensitiveValue is a helper function for ResourceAfterMasked.
+// It traverses a value and a structure of after_sensitive in parallel and
+// returns a copy of the value whose sensitive parts are replaced.
+func maskSensitiveValue(value interface{}, sensitive interface{}, path string, mask func(path string) string) interface{} {
+	switch s := sensitive.(type) {
+	case bool:
+		if s && value != nil {
+			return mask(path)
+		}
+		return value
+	case map[string]interface{}:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		ret := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			ret[k] = maskSensitiveValue(e, s[k], joinAttributePath(path, k), mask)
+		}
+		return ret
+	case []interface{}:
+		v, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		ret := make([]interface{}, len(v))
+		for i, e := range v {
+			var es interface{}
+			if i < len(s) {
+				es = s[i]
+			}
+			ret[i] = maskSensitiveValue(e, es, joinAttributePath(path, strconv.Itoa(i)), mask)
+		}
+		return ret
+	default:
+		return value
+	}
+}
+
+// joinAttributePath returns a path of nested attribute joined by dots.
+func joinAttributePath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
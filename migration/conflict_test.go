@@ -274,3 +274,107 @@ func TestConflictResourceAfter(t *testing.T) {
 		})
 	}
 }
+
+func TestConflictResourceAfterMasked(t *testing.T) {
+	cases := []struct {
+		desc      string
+		after     interface{}
+		sensitive interface{}
+		ok        bool
+		want      schema.Resource
+	}{
+		{
+			desc: "no sensitive",
+			after: map[string]interface{}{
+				"name": "foo",
+			},
+			sensitive: map[string]interface{}{},
+			ok:        true,
+			want: schema.Resource(map[string]interface{}{
+				"name": "foo",
+			}),
+		},
+		{
+			desc: "nested",
+			after: map[string]interface{}{
+				"name":     "foo",
+				"password": "secret",
+				"token":    nil,
+				"auth": []interface{}{
+					map[string]interface{}{
+						"user": "bar",
+						"key":  "secret",
+					},
+				},
+			},
+			sensitive: map[string]interface{}{
+				"password": true,
+				"token":    true,
+				"auth": []interface{}{
+					map[string]interface{}{
+						"key": true,
+					},
+				},
+			},
+			ok: true,
+			want: schema.Resource(map[string]interface{}{
+				"name":     "foo",
+				"password": "<sensitive:password>",
+				"token":    nil,
+				"auth": []interface{}{
+					map[string]interface{}{
+						"user": "bar",
+						"key":  "<sensitive:auth.0.key>",
+					},
+				},
+			}),
+		},
+		{
+			desc: "whole object",
+			after: map[string]interface{}{
+				"name": "foo",
+			},
+			sensitive: true,
+			ok:        true,
+			want: schema.Resource(map[string]interface{}{
+				"name": "<sensitive:name>",
+			}),
+		},
+		{
+			desc:      "type cast error",
+			after:     nil,
+			sensitive: false,
+			ok:        false,
+			want:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := &Conflict{
+				rc: &tfjson.ResourceChange{
+					Change: &tfjson.Change{
+						Actions: tfjson.Actions{
+							"create",
+						},
+						After:          tc.after,
+						AfterSensitive: tc.sensitive,
+					},
+				},
+			}
+
+			got, err := c.ResourceAfterMasked(sensitivePlaceholder)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// DriftReport is a report of an import which will produce a non-empty plan
+// because the object to be imported was changed outside of Terraform.
+type DriftReport struct {
+	// An address of the resource to be imported. (e.g. aws_s3_bucket_acl.example)
+	Address string
+	// An address of the drifted resource. (e.g. aws_s3_bucket.example)
+	DriftAddress string
+	// A name of the drifted attribute. (e.g. acl)
+	Attribute string
+	// True if the drifted attribute is listed in the relevant_attributes of
+	// the plan, which means that it also contributed to other planned changes.
+	Relevant bool
+}
+
+// String returns a human-readable message of the report.
+func (r *DriftReport) String() string {
+	msg := fmt.Sprintf("The import of %s will produce a non-empty plan because %s of %s was changed outside of Terraform.", r.Address, r.Attribute, r.DriftAddress)
+	if r.Relevant {
+		msg += " The changed value is also referred to by other planned changes."
+	}
+	return msg
+}
+
+// DriftAnalyzer is an interface that abstracts the analysis rules of drift.
+type DriftAnalyzer interface {
+	// Analyze cross-checks the resource_drift in a given plan against the
+	// planned changes and returns a list of imports which will produce a
+	// non-empty plan.
+	Analyze(plan *Plan) ([]*DriftReport, error)
+}
+
+// s3DriftAnalyzer is an implementation of DriftAnalyzer for aws_s3_bucket.
+// The arguments of aws_s3_bucket were split into separated resources in AWS
+// provider v4. If an argument was changed outside of Terraform, the split
+// resource imports the current value, which differs from the configuration.
+type s3DriftAnalyzer struct{}
+
+var _ DriftAnalyzer = (*s3DriftAnalyzer)(nil)
+
+// NewS3DriftAnalyzer returns a new instance of DriftAnalyzer for aws_s3_bucket.
+func NewS3DriftAnalyzer() DriftAnalyzer {
+	return &s3DriftAnalyzer{}
+}
+
+// s3SplitAttribute describes which split resource corresponds to an argument
+// of aws_s3_bucket in AWS provider v3.
+type s3SplitAttribute struct {
+	// A resource type of the split resource.
+	resourceType string
+	// An attribute name of the split resource which has the same value as the
+	// argument of aws_s3_bucket. It's empty if they are not comparable, such
+	// as nested blocks whose structure has been changed.
+	attribute string
+	// A function for comparing the values. If nil, equalDriftValue is used.
+	equal func(x interface{}, y interface{}) bool
+	// A function which returns true if the drift doesn't affect a given
+	// planned split resource. If nil, it's always affected.
+	ignore func(planned map[string]interface{}) bool
+}
+
+// s3SplitAttributes is a mapping table from arguments of aws_s3_bucket to
+// the split resources.
+var s3SplitAttributes = map[string]s3SplitAttribute{
+	"acceleration_status":                  {resourceType: "aws_s3_bucket_accelerate_configuration", attribute: "status"},
+	"acl":                                  {resourceType: "aws_s3_bucket_acl", attribute: "acl"},
+	"cors_rule":                            {resourceType: "aws_s3_bucket_cors_configuration"},
+	"grant":                                {resourceType: "aws_s3_bucket_acl", ignore: isCannedACL},
+	"lifecycle_rule":                       {resourceType: "aws_s3_bucket_lifecycle_configuration"},
+	"logging":                              {resourceType: "aws_s3_bucket_logging"},
+	"object_lock_configuration":            {resourceType: "aws_s3_bucket_object_lock_configuration"},
+	"policy":                               {resourceType: "aws_s3_bucket_policy", attribute: "policy", equal: equalJSONString},
+	"replication_configuration":            {resourceType: "aws_s3_bucket_replication_configuration"},
+	"request_payer":                        {resourceType: "aws_s3_bucket_request_payment_configuration", attribute: "payer"},
+	"server_side_encryption_configuration": {resourceType: "aws_s3_bucket_server_side_encryption_configuration"},
+	"versioning":                           {resourceType: "aws_s3_bucket_versioning"},
+	"website":                              {resourceType: "aws_s3_bucket_website_configuration"},
+}
+
+// Analyze cross-checks the resource_drift in a given plan against the
+// planned changes and returns a list of imports which will produce a
+// non-empty plan.
+// Note that the relevant_attributes of the plan is not used for filtering
+// reports. A split resource refers to the bucket only by its id, so a drifted
+// argument such as acl is never relevant to the planned changes, even though
+// the import of the split resource will produce a non-empty plan. Instead, a
+// report is marked as relevant if the drifted attribute also contributed to
+// other planned changes.
+func (a *s3DriftAnalyzer) Analyze(plan *Plan) ([]*DriftReport, error) {
+	relevant := relevantTopLevelAttributes(plan)
+	reports := []*DriftReport{}
+	for _, drift := range plan.ResourceDrift() {
+		if drift.Type != "aws_s3_bucket" || drift.Change == nil || !drift.Change.Actions.Update() {
+			continue
+		}
+
+		before, ok := drift.Change.Before.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to cast the ResourceChange.Change.Before object of %s: %#v", drift.Address, drift.Change.Before)
+		}
+		after, ok := drift.Change.After.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to cast the ResourceChange.Change.After object of %s: %#v", drift.Address, drift.Change.After)
+		}
+
+		for _, attr := range driftedAttributes(before, after) {
+			split, ok := s3SplitAttributes[attr]
+			if !ok {
+				continue
+			}
+
+			for _, rc := range findS3SplitResourceChanges(plan, split.resourceType, after["bucket"]) {
+				planned, ok := rc.Change.After.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				if split.ignore != nil && split.ignore(planned) {
+					continue
+				}
+
+				if split.attribute != "" && split.isEqual(after[attr], planned[split.attribute]) {
+					// The configuration has already been updated to match
+					// the current value. It will produce an empty plan.
+					continue
+				}
+
+				reports = append(reports, &DriftReport{
+					Address:      rc.Address,
+					DriftAddress: drift.Address,
+					Attribute:    attr,
+					Relevant:     relevant[drift.Address][attr],
+				})
+			}
+		}
+	}
+
+	return reports, nil
+}
+
+// relevantTopLevelAttributes returns a set of top-level attribute names in
+// the relevant_attributes of a given plan indexed by resource address.
+func relevantTopLevelAttributes(plan *Plan) map[string]map[string]bool {
+	ret := map[string]map[string]bool{}
+	for _, ra := range plan.RelevantAttributes() {
+		if len(ra.Attribute) == 0 {
+			continue
+		}
+		// The first step of a path is an attribute name.
+		var name string
+		if err := json.Unmarshal(ra.Attribute[0], &name); err != nil {
+			continue
+		}
+		if _, ok := ret[ra.Resource]; !ok {
+			ret[ra.Resource] = map[string]bool{}
+		}
+		ret[ra.Resource][name] = true
+	}
+	return ret
+}
+
+// isEqual returns true if given values are equal.
+func (s s3SplitAttribute) isEqual(x interface{}, y interface{}) bool {
+	if s.equal != nil {
+		return s.equal(x, y)
+	}
+	return equalDriftValue(x, y)
+}
+
+// isCannedACL returns true if a given aws_s3_bucket_acl uses a canned ACL.
+// In this case, the grant is computed by the provider and a drift of grant
+// doesn't affect the plan.
+func isCannedACL(planned map[string]interface{}) bool {
+	acl, ok := planned["acl"].(string)
+	return ok && acl != ""
+}
+
+// driftedAttributes returns a sorted list of top-level attribute names whose
+// values differ between before and after.
+func driftedAttributes(before map[string]interface{}, after map[string]interface{}) []string {
+	attrs := []string{}
+	for k, v := range after {
+		if !equalDriftValue(before[k], v) {
+			attrs = append(attrs, k)
+		}
+	}
+	for k, v := range before {
+		if _, ok := after[k]; !ok && !equalDriftValue(v, nil) {
+			attrs = append(attrs, k)
+		}
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+// equalDriftValue returns true if given values are semantically equal.
+// The resource_drift contains some noises caused by normalization in the
+// provider, such as null vs an empty string or an empty list, so we ignore
+// differences between null and zero values.
+func equalDriftValue(x interface{}, y interface{}) bool {
+	return reflect.DeepEqual(normalizeDriftValue(x), normalizeDriftValue(y))
+}
+
+// normalizeDriftValue returns a copy of a given value in which zero values
+// are replaced with nil recursively.
+func normalizeDriftValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		if t == "" {
+			return nil
+		}
+	case bool:
+		if !t {
+			return nil
+		}
+	case float64:
+		if t == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			return nil
+		}
+		ret := make([]interface{}, len(t))
+		for i, e := range t {
+			ret[i] = normalizeDriftValue(e)
+		}
+		return ret
+	case map[string]interface{}:
+		ret := map[string]interface{}{}
+		for k, e := range t {
+			if n := normalizeDriftValue(e); n != nil {
+				ret[k] = n
+			}
+		}
+		if len(ret) == 0 {
+			return nil
+		}
+		return ret
+	}
+	return v
+}
+
+// findS3SplitResourceChanges returns a list of planned create actions of a
+// given split resource type for a given bucket.
+func findS3SplitResourceChanges(plan *Plan, resourceType string, bucket interface{}) []*tfjson.ResourceChange {
+	ret := []*tfjson.ResourceChange{}
+	for _, rc := range plan.ResourceChanges() {
+		if rc.Type != resourceType || rc.Change == nil || !rc.Change.Actions.Create() {
+			continue
+		}
+
+		after, ok := rc.Change.After.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if after["bucket"] == bucket {
+			ret = append(ret, rc)
+		}
+	}
+	return ret
+}
+
+// equalJSONString returns true if given values are JSON strings which are
+// semantically equal.
+func equalJSONString(x interface{}, y interface{}) bool {
+	xs, xok := x.(string)
+	ys, yok := y.(string)
+	if !xok || !yok || xs == "" || ys == "" {
+		return equalDriftValue(x, y)
+	}
+
+	var xv, yv interface{}
+	if err := json.Unmarshal([]byte(xs), &xv); err != nil {
+		return xs == ys
+	}
+	if err := json.Unmarshal([]byte(ys), &yv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(xv, yv)
+}
@@ -0,0 +1,226 @@
+package migration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+func TestS3DriftAnalyzerAnalyze(t *testing.T) {
+	cases := []struct {
+		desc     string
+		drift    []*tfjson.ResourceChange
+		changes  []*tfjson.ResourceChange
+		relevant []tfjson.ResourceAttribute
+		ok       bool
+		want     []*DriftReport
+	}{
+		{
+			desc: "acl changed",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{"bucket": "tfedit-test", "acl": "private"},
+					map[string]interface{}{"bucket": "tfedit-test", "acl": "public-read"},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_acl", "example", map[string]interface{}{"bucket": "tfedit-test", "acl": "private"}),
+			},
+			ok: true,
+			want: []*DriftReport{
+				{
+					Address:      "aws_s3_bucket_acl.example",
+					DriftAddress: "aws_s3_bucket.example",
+					Attribute:    "acl",
+				},
+			},
+		},
+		{
+			desc: "relevant attribute",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{"bucket": "tfedit-test", "acl": "private"},
+					map[string]interface{}{"bucket": "tfedit-test", "acl": "public-read"},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_acl", "example", map[string]interface{}{"bucket": "tfedit-test", "acl": "private"}),
+			},
+			relevant: []tfjson.ResourceAttribute{
+				{Resource: "aws_s3_bucket.example", Attribute: []json.RawMessage{json.RawMessage(`"acl"`)}},
+			},
+			ok: true,
+			want: []*DriftReport{
+				{
+					Address:      "aws_s3_bucket_acl.example",
+					DriftAddress: "aws_s3_bucket.example",
+					Attribute:    "acl",
+					Relevant:     true,
+				},
+			},
+		},
+		{
+			desc: "config already matches",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{"bucket": "tfedit-test", "acl": "private"},
+					map[string]interface{}{"bucket": "tfedit-test", "acl": "public-read"},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_acl", "example", map[string]interface{}{"bucket": "tfedit-test", "acl": "public-read"}),
+			},
+			ok:   true,
+			want: []*DriftReport{},
+		},
+		{
+			desc: "nested block changed",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{
+						"bucket":     "tfedit-test",
+						"versioning": []interface{}{map[string]interface{}{"enabled": true, "mfa_delete": false}},
+					},
+					map[string]interface{}{
+						"bucket":     "tfedit-test",
+						"versioning": []interface{}{map[string]interface{}{"enabled": false, "mfa_delete": false}},
+					},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_versioning", "example", map[string]interface{}{"bucket": "tfedit-test"}),
+				newTestCreateChange("aws_s3_bucket_versioning", "other", map[string]interface{}{"bucket": "tfedit-other"}),
+			},
+			ok: true,
+			want: []*DriftReport{
+				{
+					Address:      "aws_s3_bucket_versioning.example",
+					DriftAddress: "aws_s3_bucket.example",
+					Attribute:    "versioning",
+				},
+			},
+		},
+		{
+			desc: "normalization noises",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{
+						"bucket":         "tfedit-test",
+						"policy":         nil,
+						"lifecycle_rule": []interface{}{map[string]interface{}{"id": "foo", "tags": nil, "priority": nil}},
+					},
+					map[string]interface{}{
+						"bucket":         "tfedit-test",
+						"policy":         "",
+						"lifecycle_rule": []interface{}{map[string]interface{}{"id": "foo", "tags": map[string]interface{}{}, "priority": 0.0}},
+					},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_policy", "example", map[string]interface{}{"bucket": "tfedit-test", "policy": `{"Version":"2012-10-17"}`}),
+				newTestCreateChange("aws_s3_bucket_lifecycle_configuration", "example", map[string]interface{}{"bucket": "tfedit-test"}),
+			},
+			ok:   true,
+			want: []*DriftReport{},
+		},
+		{
+			desc: "policy changed",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{"bucket": "tfedit-test", "policy": `{"Version":"2012-10-17","Statement":[]}`},
+					map[string]interface{}{"bucket": "tfedit-test", "policy": `{"Version": "2012-10-17"}`},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_policy", "example", map[string]interface{}{"bucket": "tfedit-test", "policy": `{"Version":"2012-10-17"}`}),
+			},
+			ok:   true,
+			want: []*DriftReport{},
+		},
+		{
+			desc: "grant with canned acl",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{"bucket": "tfedit-test", "grant": []interface{}{}},
+					map[string]interface{}{"bucket": "tfedit-test", "grant": []interface{}{map[string]interface{}{"type": "CanonicalUser", "permissions": []interface{}{"FULL_CONTROL"}}}},
+				),
+			},
+			changes: []*tfjson.ResourceChange{
+				newTestCreateChange("aws_s3_bucket_acl", "example", map[string]interface{}{"bucket": "tfedit-test", "acl": "private"}),
+			},
+			ok:   true,
+			want: []*DriftReport{},
+		},
+		{
+			desc: "invalid drift",
+			drift: []*tfjson.ResourceChange{
+				newTestS3BucketDrift(
+					map[string]interface{}{"bucket": "tfedit-test"},
+					nil,
+				),
+			},
+			changes: []*tfjson.ResourceChange{},
+			ok:      false,
+			want:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			plan := &Plan{
+				raw: tfjson.Plan{
+					ResourceChanges:    tc.changes,
+					RelevantAttributes: tc.relevant,
+				},
+				ext: planExtension{
+					ResourceDrift: tc.drift,
+				},
+			}
+
+			a := NewS3DriftAnalyzer()
+			got, err := a.Analyze(plan)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func newTestS3BucketDrift(before map[string]interface{}, after map[string]interface{}) *tfjson.ResourceChange {
+	var a interface{}
+	if after != nil {
+		a = after
+	}
+	return &tfjson.ResourceChange{
+		Address: "aws_s3_bucket.example",
+		Type:    "aws_s3_bucket",
+		Name:    "example",
+		Change: &tfjson.Change{
+			Actions: tfjson.Actions{"update"},
+			Before:  before,
+			After:   a,
+		},
+	}
+}
+
+func newTestCreateChange(resourceType string, name string, after map[string]interface{}) *tfjson.ResourceChange {
+	return &tfjson.ResourceChange{
+		Address: resourceType + "." + name,
+		Type:    resourceType,
+		Name:    name,
+		Change: &tfjson.Change{
+			Actions: tfjson.Actions{"create"},
+			After:   after,
+		},
+	}
+}
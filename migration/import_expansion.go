@@ -0,0 +1,449 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// planConfig is the configuration and input variables in a plan. It's used
+// for writing an import block which iterates over the same collection as the
+// for_each or count of the resource.
+type planConfig struct {
+	// The configuration which generated the plan.
+	config *tfjson.Config
+	// Values of input variables of the root module.
+	variables map[string]*tfjson.PlanVariable
+}
+
+// newPlanConfig returns a new instance of planConfig for a given plan.
+// It returns nil if the plan doesn't have configuration.
+func newPlanConfig(plan *Plan) *planConfig {
+	if plan.Config() == nil || plan.Config().RootModule == nil {
+		return nil
+	}
+	return &planConfig{
+		config:    plan.Config(),
+		variables: plan.Variables(),
+	}
+}
+
+// importExpansion is a collection for for_each of an import block, which
+// expands into the same instances as the resource to be imported.
+type importExpansion struct {
+	// Tokens of an expression for for_each.
+	forEach hclwrite.Tokens
+	// Values of each.value indexed by instance keys formatted as string.
+	values map[string]cty.Value
+}
+
+// expansions returns candidates of collections for for_each of an import
+// block for a given resource address without an instance key.
+// If count is true, the count expression is used instead of for_each.
+// The collection is taken from a constant value or a reference to an input
+// variable of the root module in the expression, which is evaluated with the
+// values in the plan. Note that the expression itself is not available in the
+// plan, so the caller must verify that the candidate expands into the same
+// instances.
+func (c *planConfig) expansions(address string, count bool) ([]*importExpansion, error) {
+	r, inRoot, err := c.findResource(address)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := "for_each"
+	expr := r.ForEachExpression
+	if count {
+		meta = "count"
+		expr = r.CountExpression
+	}
+	if expr == nil || expr.ExpressionData == nil {
+		return nil, fmt.Errorf("%s expression of %s is not found in the plan", meta, address)
+	}
+
+	ret := []*importExpansion{}
+	if expr.ConstantValue != nil && expr.ConstantValue != tfjson.UnknownConstantValue {
+		v, err := ctyValueFromJSON(expr.ConstantValue)
+		if err != nil {
+			return nil, err
+		}
+		if e, err := newImportExpansion(hclwrite.TokensForValue(v), v, count); err == nil {
+			ret = append(ret, e)
+		}
+	}
+
+	for _, ref := range expr.References {
+		// Input variables of child modules are not available in the plan.
+		if !inRoot || !strings.HasPrefix(ref, "var.") {
+			continue
+		}
+		tokens, err := tfwrite.ParseExpressionAsTokens(ref)
+		if err != nil {
+			continue
+		}
+		v, err := c.evaluateVariable(ref)
+		if err != nil {
+			continue
+		}
+		if e, err := newImportExpansion(tokens, v, count); err == nil {
+			ret = append(ret, e)
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("%s expression of %s is neither a constant nor a reference to an input variable of the root module", meta, address)
+	}
+	return ret, nil
+}
+
+// findResource returns a resource in the configuration for a given address
+// without an instance key. It also returns true if the resource is in the
+// root module.
+func (c *planConfig) findResource(address string) (*tfjson.ConfigResource, bool, error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, false, fmt.Errorf("failed to parse address %s: %s", address, diags)
+	}
+
+	names := []string{}
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		}
+		// Instance keys of modules are ignored because all instances share the
+		// same configuration.
+	}
+
+	module := c.config.RootModule
+	inRoot := true
+	for len(names) > 2 && names[0] == "module" {
+		call, ok := module.ModuleCalls[names[1]]
+		if !ok || call.Module == nil {
+			return nil, false, fmt.Errorf("configuration of %s is not found in the plan", address)
+		}
+		module = call.Module
+		inRoot = false
+		names = names[2:]
+	}
+
+	if len(names) == 2 {
+		for _, r := range module.Resources {
+			if r.Mode == tfjson.ManagedResourceMode && r.Type == names[0] && r.Name == names[1] {
+				return r, inRoot, nil
+			}
+		}
+	}
+	return nil, false, fmt.Errorf("configuration of %s is not found in the plan", address)
+}
+
+// evaluateVariable returns a value of a given reference to an input variable
+// with the values in the plan, such as `var.foo` or `var.foo.bar`.
+func (c *planConfig) evaluateVariable(ref string) (cty.Value, error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(ref), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() || len(traversal) < 2 {
+		return cty.NilVal, fmt.Errorf("failed to parse reference %s: %s", ref, diags)
+	}
+
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return cty.NilVal, fmt.Errorf("failed to parse reference %s", ref)
+	}
+	variable, ok := c.variables[attr.Name]
+	if !ok {
+		return cty.NilVal, fmt.Errorf("variable %s is not found in the plan", attr.Name)
+	}
+	v, err := ctyValueFromJSON(variable.Value)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{attr.Name: v}),
+		},
+	}
+	ret, diags := traversal.TraverseAbs(ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("failed to evaluate reference %s: %s", ref, diags)
+	}
+	return ret, nil
+}
+
+// ctyValueFromJSON converts a given value decoded from JSON to cty.Value.
+// Since the type is implied from JSON, a list or set is a tuple and a map is
+// an object.
+func ctyValueFromJSON(v interface{}) (cty.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to encode value: %s", err)
+	}
+	t, err := ctyjson.ImpliedType(b)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to decode value: %s", err)
+	}
+	ret, err := ctyjson.Unmarshal(b, t)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("failed to decode value: %s", err)
+	}
+	return ret, nil
+}
+
+// newImportExpansion returns a new instance of importExpansion for a given
+// expression and its value.
+// For count, a number is iterated with range(), and a list with tolist(),
+// so that each.key is an index.
+// For for_each, a map is iterated as is, and a list of strings with toset(),
+// because a set is also implied as a tuple from JSON.
+func newImportExpansion(tokens hclwrite.Tokens, v cty.Value, count bool) (*importExpansion, error) {
+	if v.IsNull() || !v.IsWhollyKnown() {
+		return nil, fmt.Errorf("the value is not known")
+	}
+
+	src := strings.TrimSpace(string(tokens.Bytes()))
+	values := map[string]cty.Value{}
+	ty := v.Type()
+	switch {
+	case count && ty == cty.Number:
+		n, acc := v.AsBigFloat().Int64()
+		if acc != big.Exact || n < 0 {
+			return nil, fmt.Errorf("count is not a non-negative integer")
+		}
+		for i := int64(0); i < n; i++ {
+			values[fmt.Sprintf("%d", i)] = cty.NumberIntVal(i)
+		}
+		src = "range(" + src + ")"
+
+	case count && (ty.IsTupleType() || ty.IsListType()):
+		i := 0
+		for it := v.ElementIterator(); it.Next(); i++ {
+			_, e := it.Element()
+			values[fmt.Sprintf("%d", i)] = e
+		}
+		src = "tolist(" + src + ")"
+
+	case !count && (ty.IsObjectType() || ty.IsMapType()):
+		for it := v.ElementIterator(); it.Next(); {
+			k, e := it.Element()
+			values[k.AsString()] = e
+		}
+
+	case !count && (ty.IsTupleType() || ty.IsListType() || ty.IsSetType()):
+		for it := v.ElementIterator(); it.Next(); {
+			_, e := it.Element()
+			if !e.Type().Equals(cty.String) || e.IsNull() {
+				return nil, fmt.Errorf("for_each is not a set of strings")
+			}
+			values[e.AsString()] = e
+		}
+		if !ty.IsSetType() {
+			src = "toset(" + src + ")"
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", ty.FriendlyName())
+	}
+
+	forEach, err := tfwrite.ParseExpressionAsTokens(src)
+	if err != nil {
+		return nil, err
+	}
+	return &importExpansion{forEach: forEach, values: values}, nil
+}
+
+// instanceKeyString returns an instance key formatted as string, which is
+// used as a key of importExpansion.values.
+func instanceKeyString(key cty.Value) string {
+	if key.Type() == cty.Number {
+		return key.AsBigFloat().Text('f', -1)
+	}
+	return key.AsString()
+}
+
+// matches returns true if the expansion expands into exactly the given
+// instance keys.
+func (e *importExpansion) matches(keys []cty.Value) bool {
+	if len(e.values) != len(keys) {
+		return false
+	}
+	for _, k := range keys {
+		if _, ok := e.values[instanceKeyString(k)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// idExpression returns tokens of an expression for the id of an import
+// block, which is a template in terms of each.key or each.value and
+// reproduces given import IDs of given instance keys.
+// It returns an error if no such template is found.
+func (e *importExpansion) idExpression(keys []cty.Value, ids []string) (hclwrite.Tokens, error) {
+	for _, c := range e.idCandidates(keys) {
+		parts, ok := matchIDTemplate(ids, c.values)
+		if !ok {
+			continue
+		}
+		if len(parts) == 2 && parts[0] == "" && parts[1] == "" {
+			return tfwrite.ParseExpressionAsTokens(c.expr)
+		}
+
+		escaped := make([]string, len(parts))
+		for i, p := range parts {
+			lit := string(hclwrite.TokensForValue(cty.StringVal(p)).Bytes())
+			escaped[i] = lit[1 : len(lit)-1]
+		}
+		return tfwrite.ParseExpressionAsTokens(`"` + strings.Join(escaped, "${"+c.expr+"}") + `"`)
+	}
+	return nil, fmt.Errorf("import IDs cannot be written in terms of each.key or each.value")
+}
+
+// idCandidate is a candidate of an expression interpolated into import IDs.
+type idCandidate struct {
+	// An expression. (e.g. each.value)
+	expr string
+	// Values of the expression for each instance in order of keys.
+	values []string
+}
+
+// idCandidates returns candidates of expressions interpolated into import
+// IDs: each.value, each.value.<attribute> and each.key in this order.
+func (e *importExpansion) idCandidates(keys []cty.Value) []idCandidate {
+	values := make([]cty.Value, len(keys))
+	for i, k := range keys {
+		values[i] = e.values[instanceKeyString(k)]
+	}
+
+	ret := []idCandidate{}
+	if c, ok := newIDCandidate("each.value", values); ok {
+		ret = append(ret, c)
+	}
+
+	// Since values are implied from JSON, a map is always an object.
+	if values[0].Type().IsObjectType() {
+		names := []string{}
+		for name := range values[0].Type().AttributeTypes() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !hclsyntax.ValidIdentifier(name) {
+				continue
+			}
+			attrs := make([]cty.Value, len(values))
+			for i, v := range values {
+				if !v.Type().IsObjectType() || !v.Type().HasAttribute(name) {
+					attrs = nil
+					break
+				}
+				attrs[i] = v.GetAttr(name)
+			}
+			if attrs == nil {
+				continue
+			}
+			if c, ok := newIDCandidate("each.value."+name, attrs); ok {
+				ret = append(ret, c)
+			}
+		}
+	}
+
+	if c, ok := newIDCandidate("each.key", keys); ok {
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// newIDCandidate returns a new idCandidate if all given values can be
+// converted to non-empty strings.
+func newIDCandidate(expr string, values []cty.Value) (idCandidate, bool) {
+	c := idCandidate{expr: expr, values: make([]string, len(values))}
+	for i, v := range values {
+		if v == cty.NilVal || v.IsNull() || !v.IsKnown() {
+			return idCandidate{}, false
+		}
+		s, err := convert.Convert(v, cty.String)
+		if err != nil || s.AsString() == "" {
+			return idCandidate{}, false
+		}
+		c.values[i] = s.AsString()
+	}
+	return c, true
+}
+
+// matchIDTemplate returns literal parts of a template which reproduces all
+// given IDs by joining the parts with given values, such as
+// ["tfedit-", ",private"] for "tfedit-a,private" and "a".
+// An occurrence of the value in the first ID is tried one by one, and then
+// all occurrences, because the value may appear in the literal by accident.
+func matchIDTemplate(ids []string, values []string) ([]string, bool) {
+	first := values[0]
+	candidates := [][]string{}
+	for i := 0; i+len(first) <= len(ids[0]); i++ {
+		if strings.HasPrefix(ids[0][i:], first) {
+			candidates = append(candidates, []string{ids[0][:i], ids[0][i+len(first):]})
+		}
+	}
+	candidates = append(candidates, strings.Split(ids[0], first))
+
+	for _, parts := range candidates {
+		if len(parts) < 2 {
+			continue
+		}
+		ok := true
+		for i, id := range ids {
+			if strings.Join(parts, values[i]) != id {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return parts, true
+		}
+	}
+	return nil, false
+}
+
+// resolveExpansion sets an expression for for_each of the import block, which
+// iterates over the same collection as the for_each or count of the resource,
+// and a template of the import ID in terms of each.key or each.value.
+// It returns an error if the expression cannot be used, and then the group is
+// rendered with a literal collection of import IDs instead.
+func (g *importGroup) resolveExpansion(c *planConfig) error {
+	if c == nil {
+		return fmt.Errorf("the configuration is not available")
+	}
+
+	count := g.keys[0].Type() == cty.Number
+	expansions, err := c.expansions(g.address, count)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range expansions {
+		if !e.matches(g.keys) {
+			continue
+		}
+		id, err := e.idExpression(g.keys, g.ids)
+		if err != nil {
+			return err
+		}
+		g.forEach = e.forEach
+		g.id = id
+		return nil
+	}
+	return fmt.Errorf("the collection doesn't match the instances in the plan")
+}
@@ -0,0 +1,132 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestImportExpansionIDExpression(t *testing.T) {
+	cases := []struct {
+		desc    string
+		value   cty.Value
+		count   bool
+		keys    []cty.Value
+		ids     []string
+		ok      bool
+		forEach string
+		id      string
+	}{
+		{
+			desc: "each.value",
+			value: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("tfedit-a"),
+				"b": cty.StringVal("tfedit-b"),
+			}),
+			keys:    []cty.Value{cty.StringVal("b"), cty.StringVal("a")},
+			ids:     []string{"tfedit-b", "tfedit-a"},
+			ok:      true,
+			forEach: "var.buckets",
+			id:      "each.value",
+		},
+		{
+			desc: "template with each.value",
+			value: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("tfedit-a"),
+				"b": cty.StringVal("tfedit-b"),
+			}),
+			keys:    []cty.Value{cty.StringVal("a"), cty.StringVal("b")},
+			ids:     []string{"tfedit-a,private", "tfedit-b,private"},
+			ok:      true,
+			forEach: "var.buckets",
+			id:      `"${each.value},private"`,
+		},
+		{
+			desc: "attribute of each.value",
+			value: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.ObjectVal(map[string]cty.Value{"acl": cty.StringVal("private"), "name": cty.StringVal("tfedit-a")}),
+				"b": cty.ObjectVal(map[string]cty.Value{"acl": cty.StringVal("private"), "name": cty.StringVal("tfedit-b")}),
+			}),
+			keys:    []cty.Value{cty.StringVal("a"), cty.StringVal("b")},
+			ids:     []string{"tfedit-a,private", "tfedit-b,private"},
+			ok:      true,
+			forEach: "var.buckets",
+			id:      `"${each.value.name},private"`,
+		},
+		{
+			desc:    "set of strings",
+			value:   cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			keys:    []cty.Value{cty.StringVal("a"), cty.StringVal("b")},
+			ids:     []string{"tfedit-a", "tfedit-b"},
+			ok:      true,
+			forEach: "toset(var.buckets)",
+			id:      `"tfedit-${each.value}"`,
+		},
+		{
+			desc:    "escape a literal",
+			value:   cty.NumberIntVal(2),
+			count:   true,
+			keys:    []cty.Value{cty.NumberIntVal(0), cty.NumberIntVal(1)},
+			ids:     []string{"${0}", "${1}"},
+			ok:      true,
+			forEach: "range(var.buckets)",
+			id:      `"$${${each.value}}"`,
+		},
+		{
+			desc:    "count over a list",
+			value:   cty.TupleVal([]cty.Value{cty.StringVal("tfedit-a"), cty.StringVal("tfedit-b")}),
+			count:   true,
+			keys:    []cty.Value{cty.NumberIntVal(0), cty.NumberIntVal(1)},
+			ids:     []string{"tfedit-a", "tfedit-b"},
+			ok:      true,
+			forEach: "tolist(var.buckets)",
+			id:      "each.value",
+		},
+		{
+			desc: "no template",
+			value: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("tfedit-a"),
+				"b": cty.StringVal("tfedit-b"),
+			}),
+			keys: []cty.Value{cty.StringVal("a"), cty.StringVal("b")},
+			ids:  []string{"foo", "bar"},
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tokens, err := tfwrite.ParseExpressionAsTokens("var.buckets")
+			if err != nil {
+				t.Fatalf("failed to parse expression: %s", err)
+			}
+			e, err := newImportExpansion(tokens, tc.value, tc.count)
+			if err != nil {
+				t.Fatalf("failed to create an expansion: %s", err)
+			}
+			if !e.matches(tc.keys) {
+				t.Fatalf("expected to match keys: %#v", tc.keys)
+			}
+
+			id, err := e.idExpression(tc.keys, tc.ids)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error, got: %s", id.Bytes())
+				}
+				return
+			}
+
+			if got := strings.TrimSpace(string(e.forEach.Bytes())); got != tc.forEach {
+				t.Errorf("got for_each = %s, but want = %s", got, tc.forEach)
+			}
+			if got := strings.TrimSpace(string(id.Bytes())); got != tc.id {
+				t.Errorf("got id = %s, but want = %s", got, tc.id)
+			}
+		})
+	}
+}
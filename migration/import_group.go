@@ -0,0 +1,224 @@
+package migration
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// importGroup is a set of import actions for instances of the same resource
+// which uses count or for_each. It's rendered as a single import block with
+// for_each instead of an import block per instance.
+type importGroup struct {
+	// An address of the resource without an instance key.
+	// (e.g. aws_s3_bucket_acl.example)
+	address string
+	// A list of instance keys. The type is either string or number.
+	keys []cty.Value
+	// A list of import IDs corresponding to the keys.
+	ids []string
+	// A list of original actions corresponding to the keys.
+	actions []StateAction
+	// Tokens of an expression for for_each, which iterates over the same
+	// collection as the resource. If nil, a literal collection of import IDs
+	// is used instead.
+	forEach hclwrite.Tokens
+	// Tokens of a template of the import ID corresponding to the forEach.
+	id hclwrite.Tokens
+}
+
+var _ configBlocker = (*importGroup)(nil)
+
+// ConfigBlock returns an import block with for_each equivalent to the group.
+// If an expansion is resolved, it iterates over the same collection as the
+// resource and the id is a template in terms of each.key or each.value.
+// Otherwise, a group of for_each instances iterates over a map of import IDs
+// indexed by instance keys, and a group of count instances iterates over a
+// tuple of import IDs. It requires Terraform v1.7+.
+func (g *importGroup) ConfigBlock() (tfwrite.Block, error) {
+	b := tfwrite.NewEmptyImport()
+	if g.forEach != nil {
+		b.SetAttributeRaw("for_each", g.forEach)
+		if err := b.SetToEachKey(g.address); err != nil {
+			return nil, err
+		}
+		b.SetAttributeRaw("id", g.id)
+		return b, nil
+	}
+
+	forEach, err := g.forEachValue()
+	if err != nil {
+		return nil, err
+	}
+
+	b.SetForEach(forEach)
+	if err := b.SetToEachKey(g.address); err != nil {
+		return nil, err
+	}
+	b.SetIDEachValue()
+	return b, nil
+}
+
+// forEachValue returns a collection value of import IDs for for_each.
+func (g *importGroup) forEachValue() (cty.Value, error) {
+	if g.keys[0].Type() == cty.String {
+		m := make(map[string]cty.Value, len(g.keys))
+		for i, k := range g.keys {
+			m[k.AsString()] = cty.StringVal(g.ids[i])
+		}
+		return cty.MapVal(m), nil
+	}
+
+	indexes, err := g.countIndexes()
+	if err != nil {
+		return cty.NilVal, err
+	}
+	l := make([]cty.Value, len(g.keys))
+	for i, index := range indexes {
+		l[index] = cty.StringVal(g.ids[i])
+	}
+	return cty.TupleVal(l), nil
+}
+
+// splitInstanceKey splits a given resource instance address into an address
+// of the resource and an instance key. If the address doesn't have an
+// instance key, ok is false.
+func splitInstanceKey(address string) (string, cty.Value, bool) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() || len(traversal) == 0 {
+		return "", cty.NilVal, false
+	}
+
+	index, ok := traversal[len(traversal)-1].(hcl.TraverseIndex)
+	if !ok {
+		return "", cty.NilVal, false
+	}
+
+	return address[:index.SrcRange.Start.Byte], index.Key, true
+}
+
+// groupImportActions returns a list of items rendered as configuration
+// blocks. Import actions without comments for two or more instances of the
+// same resource are merged into an importGroup at the position of the first
+// instance. Other actions are returned as is.
+// It returns an error if an action cannot be rendered as a configuration
+// block, such as a custom action returned by a resolver.
+func groupImportActions(actions []StateAction) ([]configBlocker, error) {
+	groups := map[string]*importGroup{}
+	for _, a := range actions {
+		if _, ok := a.(configBlocker); !ok {
+			return nil, fmt.Errorf("the action is not supported in config mode: %s", a.MigrationAction())
+		}
+
+		address, key, ok := groupableImportAction(a)
+		if !ok {
+			continue
+		}
+
+		g, ok := groups[address]
+		if !ok {
+			g = &importGroup{address: address}
+			groups[address] = g
+		}
+		g.keys = append(g.keys, key)
+		g.ids = append(g.ids, a.(*StateImportAction).id)
+		g.actions = append(g.actions, a)
+	}
+
+	ret := []configBlocker{}
+	rendered := map[string]bool{}
+	for _, a := range actions {
+		address, _, ok := groupableImportAction(a)
+		if !ok || !groups[address].isGroupable() {
+			ret = append(ret, a.(configBlocker))
+			continue
+		}
+
+		if !rendered[address] {
+			ret = append(ret, groups[address])
+			rendered[address] = true
+		}
+	}
+
+	return ret, nil
+}
+
+// groupableImportAction returns an address of the resource and an instance
+// key if a given action is an import action which can be grouped.
+func groupableImportAction(a StateAction) (string, cty.Value, bool) {
+	ia, ok := a.(*StateImportAction)
+	if !ok || ia.comment != "" {
+		return "", cty.NilVal, false
+	}
+	return splitInstanceKey(ia.address)
+}
+
+// isGroupable returns true if the group has two or more instances and all
+// keys have the same type. Since each.key of a tuple is an index, count
+// indexes must also be contiguous from zero.
+func (g *importGroup) isGroupable() bool {
+	if len(g.keys) < 2 {
+		return false
+	}
+	for _, k := range g.keys[1:] {
+		if !k.Type().Equals(g.keys[0].Type()) {
+			return false
+		}
+	}
+	if g.keys[0].Type() == cty.Number {
+		_, err := g.countIndexes()
+		return err == nil
+	}
+	return true
+}
+
+// countIndexes returns a list of count indexes of the keys.
+// It returns an error if they are not contiguous from zero.
+func (g *importGroup) countIndexes() ([]int, error) {
+	seen := make([]bool, len(g.keys))
+	indexes := make([]int, len(g.keys))
+	for i, k := range g.keys {
+		index, acc := k.AsBigFloat().Int64()
+		if acc != big.Exact || index < 0 || index >= int64(len(seen)) || seen[index] {
+			return nil, fmt.Errorf("failed to group imports of %s: count indexes are not contiguous from zero", g.address)
+		}
+		seen[index] = true
+		indexes[i] = int(index)
+	}
+	return indexes, nil
+}
+
+// group returns a common source resource of the original actions with a
+// given function for getting a source resource of each action.
+// Since each instance usually belongs to the corresponding instance of the
+// source resource, instance keys are ignored if they are different.
+// It returns an empty string if not found.
+func (g *importGroup) group(groupOf func(StateAction) string) string {
+	first := groupOf(g.actions[0])
+	same := true
+	for _, a := range g.actions[1:] {
+		if groupOf(a) != first {
+			same = false
+			break
+		}
+	}
+	if same {
+		return first
+	}
+
+	base, _, ok := splitInstanceKey(first)
+	if !ok {
+		return ""
+	}
+	for _, a := range g.actions[1:] {
+		if b, _, ok := splitInstanceKey(groupOf(a)); !ok || b != base {
+			return ""
+		}
+	}
+	return base
+}
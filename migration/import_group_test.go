@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSplitInstanceKey(t *testing.T) {
+	cases := []struct {
+		desc    string
+		address string
+		ok      bool
+		base    string
+		key     cty.Value
+	}{
+		{
+			desc:    "for_each",
+			address: `foo_bar.example["foo"]`,
+			ok:      true,
+			base:    "foo_bar.example",
+			key:     cty.StringVal("foo"),
+		},
+		{
+			desc:    "count",
+			address: "foo_bar.example[1]",
+			ok:      true,
+			base:    "foo_bar.example",
+			key:     cty.NumberIntVal(1),
+		},
+		{
+			desc:    "module instance",
+			address: `module.foo["a"].foo_bar.example["b[0]"]`,
+			ok:      true,
+			base:    `module.foo["a"].foo_bar.example`,
+			key:     cty.StringVal("b[0]"),
+		},
+		{
+			desc:    "no instance key",
+			address: `module.foo["a"].foo_bar.example`,
+			ok:      false,
+		},
+		{
+			desc:    "invalid address",
+			address: "foo_bar.example[",
+			ok:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			base, key, ok := splitInstanceKey(tc.address)
+			if ok != tc.ok {
+				t.Fatalf("got ok = %t, but want = %t", ok, tc.ok)
+			}
+			if !tc.ok {
+				return
+			}
+
+			if base != tc.base {
+				t.Errorf("got base = %s, but want = %s", base, tc.base)
+			}
+			if !key.RawEquals(tc.key) {
+				t.Errorf("got key = %#v, but want = %#v", key, tc.key)
+			}
+		})
+	}
+}
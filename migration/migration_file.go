@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// migrationFile is a type which corresponds to a tfmigrate's migration file.
+// We define only what we need for reading a migration file here.
+type migrationFile struct {
+	// A migration block. tfmigrate allows only one block per file.
+	Migration migrationBlock `hcl:"migration,block"`
+}
+
+// migrationBlock corresponds to config.MigrationBlock in minamijoyo/tfmigrate.
+type migrationBlock struct {
+	// A type of migration. (e.g. state, multi_state)
+	Type string `hcl:"type,label"`
+	// A name of migration.
+	Name string `hcl:"name,label"`
+	// The rest of the block depends on the type.
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// stateMigrationBlock corresponds to tfmigrate.StateMigratorConfig in
+// minamijoyo/tfmigrate.
+type stateMigrationBlock struct {
+	// A working directory for executing terraform command.
+	Dir string `hcl:"dir,optional"`
+	// A workspace name.
+	Workspace string `hcl:"workspace,optional"`
+	// A list of state action.
+	Actions []string `hcl:"actions"`
+	// A flag for allowing a plan with changes after migration.
+	Force bool `hcl:"force,optional"`
+	// A flag for skipping a plan after migration.
+	SkipPlan bool `hcl:"skip_plan,optional"`
+}
+
+// ParseStateMigration parses a tfmigrate's migration file and returns a new
+// instance of StateMigration.
+// Only the state migration with import, mv and rm actions is supported.
+func ParseStateMigration(src []byte, filename string) (*StateMigration, error) {
+	parser := hclparse.NewParser()
+	file, diags := parser.ParseHCL(src, filename)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse migration file: %s", diags)
+	}
+
+	var f migrationFile
+	diags = gohcl.DecodeBody(file.Body, nil, &f)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode migration file: %s", diags)
+	}
+
+	if f.Migration.Type != "state" {
+		return nil, fmt.Errorf("unsupported migration type: %s", f.Migration.Type)
+	}
+
+	var b stateMigrationBlock
+	diags = gohcl.DecodeBody(f.Migration.Remain, nil, &b)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode migration block: %s", diags)
+	}
+
+	m := NewStateMigration(f.Migration.Name, b.Dir)
+	for _, cmdStr := range b.Actions {
+		action, err := NewStateActionFromString(cmdStr)
+		if err != nil {
+			return nil, err
+		}
+		m.AppendActions(action)
+	}
+
+	return m, nil
+}
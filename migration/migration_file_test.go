@@ -0,0 +1,86 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseStateMigration(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		ok   bool
+		want *StateMigration
+	}{
+		{
+			desc: "simple",
+			src: `
+migration "state" "fromplan" {
+  dir = "foo"
+  actions = [
+    "import 'foo_bar.example[\"foo\"]' test-foo",
+    "mv foo_bar.example1 foo_bar.example2",
+    "rm foo_bar.example3 foo_bar.example4",
+  ]
+}
+`,
+			ok: true,
+			want: &StateMigration{
+				Name: "fromplan",
+				Dir:  "foo",
+				Actions: []StateAction{
+					&StateImportAction{
+						address: `foo_bar.example["foo"]`,
+						id:      "test-foo",
+					},
+					&StateMvAction{
+						source:      "foo_bar.example1",
+						destination: "foo_bar.example2",
+					},
+					&StateRmAction{
+						addresses: []string{"foo_bar.example3", "foo_bar.example4"},
+					},
+				},
+			},
+		},
+		{
+			desc: "multi_state",
+			src: `
+migration "multi_state" "test" {
+  from_dir = "foo"
+  to_dir   = "bar"
+  actions  = []
+}
+`,
+			ok:   false,
+			want: nil,
+		},
+		{
+			desc: "syntax error",
+			src: `
+migration "state" "test" {
+`,
+			ok:   false,
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ParseStateMigration([]byte(tc.src), "test.hcl")
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+
+			opts := cmp.AllowUnexported(StateMigration{}, StateImportAction{}, StateMvAction{}, StateRmAction{})
+			if diff := cmp.Diff(got, tc.want, opts); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
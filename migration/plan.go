@@ -4,32 +4,195 @@ import (
 	"encoding/json"
 	"fmt"
 
+	version "github.com/hashicorp/go-version"
 	tfjson "github.com/hashicorp/terraform-json"
 )
 
+// supportedPlanFormatVersions is a version constraint of the JSON plan format
+// which we can handle knowingly.
+// The format version 0.2 was introduced in Terraform v0.15.0 with
+// after_sensitive, which we rely on. The major version bump means breaking
+// changes, so we reject it explicitly.
+var supportedPlanFormatVersions = version.MustConstraints(version.NewConstraint(">= 0.2, < 2.0"))
+
+// supportedTerraformVersions is a version constraint of Terraform which
+// generated the JSON plan.
+// OpenTofu also reports its version in the terraform_version field, and it is
+// compatible with Terraform v1.x in this respect.
+var supportedTerraformVersions = version.MustConstraints(version.NewConstraint(">= 0.15.0"))
+
 // Plan is a type which wraps Plan of terraform-json and exposes some
 // operations which we need.
 type Plan struct {
 	raw tfjson.Plan
+	// Fields which were added in newer versions of the JSON plan format, but
+	// are not supported by terraform-json we are using.
+	ext planExtension
+}
+
+// planExtension is a set of fields in the JSON plan format which are not
+// supported by terraform-json we are using.
+type planExtension struct {
+	// Changes detected outside of Terraform since the last apply.
+	ResourceDrift []*tfjson.ResourceChange `json:"resource_drift,omitempty"`
+	// Results of checkable objects such as preconditions and check blocks.
+	Checks []*PlanCheck `json:"checks,omitempty"`
+	// Changes deferred to a later plan/apply round.
+	DeferredChanges []*PlanDeferredChange `json:"deferred_changes,omitempty"`
+	// A flag indicating that the plan is incomplete because of errors.
+	Errored bool `json:"errored,omitempty"`
+}
+
+// PlanCheck is a status of a checkable object in plan.
+type PlanCheck struct {
+	// An address of a checkable object.
+	Address PlanCheckAddress `json:"address"`
+	// A status of the check, one of pass, fail, error or unknown.
+	Status string `json:"status"`
+}
+
+// PlanCheckAddress is an address of a checkable object.
+type PlanCheckAddress struct {
+	// A kind of a checkable object. (e.g. resource, output_value, check)
+	Kind string `json:"kind"`
+	// A human-readable address. (e.g. aws_s3_bucket.example)
+	ToDisplay string `json:"to_display"`
+}
+
+// PlanDeferredChange is a change deferred to a later plan/apply round.
+type PlanDeferredChange struct {
+	// A reason why the change was deferred.
+	Reason string `json:"reason"`
+	// A deferred resource change.
+	ResourceChange *tfjson.ResourceChange `json:"resource_change"`
+}
+
+// planVersion is a minimal set of fields for validating compatibility before
+// parsing the whole plan.
+type planVersion struct {
+	FormatVersion    string `json:"format_version"`
+	TerraformVersion string `json:"terraform_version"`
 }
 
 // NewPlan parses a plan file in JSON format and creates a new instance of
 // Plan.
 func NewPlan(planJSON []byte) (*Plan, error) {
-	var raw tfjson.Plan
+	var v planVersion
+	if err := json.Unmarshal(planJSON, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %s", err)
+	}
+
+	if err := validatePlanVersion(v); err != nil {
+		return nil, err
+	}
 
+	var raw tfjson.Plan
 	if err := json.Unmarshal(planJSON, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse plan file: %s", err)
 	}
 
+	var ext planExtension
+	if err := json.Unmarshal(planJSON, &ext); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %s", err)
+	}
+
+	if ext.Errored {
+		return nil, fmt.Errorf("the plan is incomplete because of errors during planning, fix them and re-generate the plan file")
+	}
+
 	plan := &Plan{
 		raw: raw,
+		ext: ext,
 	}
 
 	return plan, nil
 }
 
+// validatePlanVersion checks that the plan was generated by a compatible
+// version of Terraform and reports a clear error if not.
+func validatePlanVersion(v planVersion) error {
+	if v.FormatVersion == "" {
+		return fmt.Errorf("failed to detect a format version of plan file, the format_version is missing")
+	}
+
+	formatVersion, err := version.NewVersion(v.FormatVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse a format version of plan file: %s", err)
+	}
+
+	// Version constraints never match prerelease versions, so compare the core
+	// version. (e.g. 1.10.0-beta1 => 1.10.0)
+	if !supportedPlanFormatVersions.Check(formatVersion.Core()) {
+		return fmt.Errorf("unsupported plan format version: %s (terraform_version = %q), supported format versions are %q", v.FormatVersion, v.TerraformVersion, supportedPlanFormatVersions.String())
+	}
+
+	if v.TerraformVersion == "" {
+		return fmt.Errorf("failed to detect a Terraform version of plan file, the terraform_version is missing")
+	}
+
+	terraformVersion, err := version.NewVersion(v.TerraformVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse a Terraform version of plan file: %s", err)
+	}
+
+	if !supportedTerraformVersions.Check(terraformVersion.Core()) {
+		return fmt.Errorf("unsupported Terraform version: %s, supported versions are %q", v.TerraformVersion, supportedTerraformVersions.String())
+	}
+
+	return nil
+}
+
+// FormatVersion returns a version of the JSON plan format.
+func (p *Plan) FormatVersion() string {
+	return p.raw.FormatVersion
+}
+
+// TerraformVersion returns a version of Terraform which generated the plan.
+func (p *Plan) TerraformVersion() string {
+	return p.raw.TerraformVersion
+}
+
+// Config returns the configuration which generated the plan.
+// It may be nil for a plan without configuration.
+func (p *Plan) Config() *tfjson.Config {
+	return p.raw.Config
+}
+
+// Variables returns values of input variables of the root module.
+func (p *Plan) Variables() map[string]*tfjson.PlanVariable {
+	return p.raw.Variables
+}
+
 // ResourceChanges returns a list of changes in plan.
 func (p *Plan) ResourceChanges() []*tfjson.ResourceChange {
 	return p.raw.ResourceChanges
 }
+
+// PriorState returns the state prior to the plan operation.
+func (p *Plan) PriorState() *tfjson.State {
+	return p.raw.PriorState
+}
+
+// ResourceDrift returns a list of changes detected outside of Terraform since
+// the last apply. It's available in Terraform v0.15.4+.
+func (p *Plan) ResourceDrift() []*tfjson.ResourceChange {
+	return p.ext.ResourceDrift
+}
+
+// RelevantAttributes returns a list of resource attributes which may have
+// contributed to the planned changes. It's available in Terraform v1.2+.
+func (p *Plan) RelevantAttributes() []tfjson.ResourceAttribute {
+	return p.raw.RelevantAttributes
+}
+
+// Checks returns a list of statuses of checkable objects.
+// It's available in Terraform v1.5+.
+func (p *Plan) Checks() []*PlanCheck {
+	return p.ext.Checks
+}
+
+// DeferredChanges returns a list of changes deferred to a later plan/apply
+// round. It's available in Terraform v1.9+ with deferred actions enabled.
+func (p *Plan) DeferredChanges() []*PlanDeferredChange {
+	return p.ext.DeferredChanges
+}
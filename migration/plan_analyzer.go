@@ -1,8 +1,13 @@
 package migration
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/minamijoyo/tfedit/migration/schema"
 	"github.com/minamijoyo/tfedit/migration/schema/aws"
+	"github.com/minamijoyo/tfedit/migration/schema/azurerm"
+	"github.com/minamijoyo/tfedit/migration/schema/google"
 )
 
 // PlanAnalyzer is an interface that abstracts the analysis rules of plan.
@@ -20,6 +25,8 @@ type defaultPlanAnalyzer struct {
 	dictionary *schema.Dictionary
 	// A list of rules used for analysis.
 	resolvers []Resolver
+	// A list of rules used for detecting drift which affects the migration.
+	driftAnalyzers []DriftAnalyzer
 }
 
 var _ PlanAnalyzer = (*defaultPlanAnalyzer)(nil)
@@ -27,11 +34,22 @@ var _ PlanAnalyzer = (*defaultPlanAnalyzer)(nil)
 // NewDefaultPlanAnalyzer returns a new instance of defaultPlanAnalyzer.
 // The current implementation only supports import, but allows us to compose
 // multiple resolvers for future extension.
-func NewDefaultPlanAnalyzer(d *schema.Dictionary) PlanAnalyzer {
+// The sensitive is a policy for import IDs derived from sensitive values.
+func NewDefaultPlanAnalyzer(d *schema.Dictionary, sensitive SensitiveMode) PlanAnalyzer {
+	return NewDefaultPlanAnalyzerWithResolvers(d, sensitive)
+}
+
+// NewDefaultPlanAnalyzerWithResolvers returns a new instance of
+// defaultPlanAnalyzer with additional resolvers. They are applied in order
+// after the built-in resolvers to the conflicts which remain unresolved.
+func NewDefaultPlanAnalyzerWithResolvers(d *schema.Dictionary, sensitive SensitiveMode, resolvers ...Resolver) PlanAnalyzer {
 	return &defaultPlanAnalyzer{
 		dictionary: d,
-		resolvers: []Resolver{
-			NewStateImportResolver(d),
+		resolvers: append([]Resolver{
+			NewStateImportResolver(d, sensitive),
+		}, resolvers...),
+		driftAnalyzers: []DriftAnalyzer{
+			NewS3DriftAnalyzer(),
 		},
 	}
 }
@@ -40,44 +58,214 @@ func NewDefaultPlanAnalyzer(d *schema.Dictionary) PlanAnalyzer {
 // the plan results in no changes.
 // The dir is set to a dir attribute in a migration file.
 func (a *defaultPlanAnalyzer) Analyze(plan *Plan, dir string) (*StateMigration, error) {
+	// The deferred changes are not included in the resource changes, so we
+	// cannot generate a complete migration for them. Make it explicit rather
+	// than generating a partial migration silently.
+	if deferred := plan.DeferredChanges(); len(deferred) > 0 {
+		reasons := []string{}
+		for _, d := range deferred {
+			address := ""
+			if d.ResourceChange != nil {
+				address = d.ResourceChange.Address
+			}
+			reasons = append(reasons, fmt.Sprintf("%s (%s)", address, d.Reason))
+		}
+		return nil, fmt.Errorf("the plan contains deferred changes, which cannot be migrated until they are planned: %s", strings.Join(reasons, ", "))
+	}
+
 	subject := NewSubject(plan)
 
 	migration := NewStateMigration("fromplan", dir)
+	migration.config = newPlanConfig(plan)
+
+	// Failed checks don't prevent us from generating a migration, but the plan
+	// in tfmigrate will report them again. Let the user know in advance.
+	for _, c := range plan.Checks() {
+		switch c.Status {
+		case "fail", "error":
+			migration.AppendWarnings(fmt.Sprintf("The check for %s has a status of %s in the plan.", c.Address.ToDisplay, c.Status))
+		}
+	}
+
+	groups := s3SplitGroups(plan)
 	current := subject
 	for _, r := range a.resolvers {
 		next, actions, err := r.Resolve(current)
 		if err != nil {
 			return nil, err
 		}
-		migration.AppendActions(actions...)
+		for _, action := range actions {
+			migration.AppendGroupedActions(groups[actionAddress(action)], action)
+		}
 		current = next
 	}
+	migration.SortActions()
+
+	// Changes made outside of Terraform are imported as they are, which
+	// results in a non-empty plan after migration. Report them as warnings so
+	// that we can catch them before running tfmigrate apply.
+	for _, d := range a.driftAnalyzers {
+		reports, err := d.Analyze(plan)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reports {
+			migration.AppendWarnings(r.String())
+		}
+	}
 
 	return migration, nil
 }
 
+// s3SplitGroups returns a map of addresses of resources split from
+// aws_s3_bucket in AWS provider v4 indexed by addresses of the new resources,
+// which is used for grouping actions by the original aws_s3_bucket.
+// A new resource is considered to be split from an aws_s3_bucket in the same
+// module which has the same bucket name.
+func s3SplitGroups(plan *Plan) map[string]string {
+	buckets := map[string]string{}
+	for _, rc := range plan.ResourceChanges() {
+		if rc.Type != "aws_s3_bucket" || rc.Change == nil {
+			continue
+		}
+		if bucket, ok := planBucketName(rc.Change.After); ok {
+			buckets[rc.ModuleAddress+"\x00"+bucket] = rc.Address
+		}
+	}
+
+	groups := map[string]string{}
+	for _, rc := range plan.ResourceChanges() {
+		if !strings.HasPrefix(rc.Type, "aws_s3_bucket_") || rc.Change == nil {
+			continue
+		}
+		bucket, ok := planBucketName(rc.Change.After)
+		if !ok {
+			continue
+		}
+		if parent, ok := buckets[rc.ModuleAddress+"\x00"+bucket]; ok {
+			groups[rc.Address] = parent
+		}
+	}
+
+	return groups
+}
+
+// planBucketName returns a bucket name in a given planned value.
+func planBucketName(value interface{}) (string, bool) {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	bucket, ok := values["bucket"].(string)
+	if !ok || bucket == "" {
+		return "", false
+	}
+	return bucket, true
+}
+
+// GenerateMode is a format of output for resolved state migration actions.
+type GenerateMode string
+
+const (
+	// GenerateModeMigration generates a tfmigrate's migration file. It's default.
+	GenerateModeMigration GenerateMode = "migration"
+	// GenerateModeConfig generates Terraform configuration blocks such as
+	// import, moved and removed for config-driven refactoring.
+	GenerateModeConfig GenerateMode = "config"
+)
+
+// GenerateOption is a set of options for generating a migration file.
+type GenerateOption struct {
+	// Dir is set to a dir attribute in a migration file.
+	Dir string
+	// Dictionary is a dictionary for provider schema.
+	// If nil, the default built-in dictionary is used.
+	Dictionary *schema.Dictionary
+	// Sensitive is a policy for import IDs derived from sensitive values.
+	// If empty, SensitiveModeError is used.
+	Sensitive SensitiveMode
+	// Mode is a format of output.
+	// If empty, GenerateModeMigration is used.
+	Mode GenerateMode
+	// GroupComments adds comments which group actions by the original
+	// resource, such as an aws_s3_bucket split into multiple resources.
+	GroupComments bool
+	// Resolvers is a list of additional resolvers applied after the built-in
+	// ones. It's only used for generating from a plan.
+	Resolvers []Resolver
+	// GuessRenames moves the only removed and added resources of the same
+	// type in the same module as renamed even if their arguments differ.
+	// It's only used for generating from configuration.
+	GuessRenames bool
+}
+
 // GenerateFromPlan returns bytes of a migration file which reverts a given
 // planned changes.
 // The dir is set to a dir attribute in a migration file.
 func GenerateFromPlan(planJSON []byte, dir string) ([]byte, error) {
+	o := &GenerateOption{
+		Dir: dir,
+	}
+	return GenerateFromPlanWithOption(planJSON, o)
+}
+
+// GenerateFromPlanWithOption returns bytes of a migration file which reverts
+// a given planned changes with a given option.
+func GenerateFromPlanWithOption(planJSON []byte, o *GenerateOption) ([]byte, error) {
+	migration, err := AnalyzePlan(planJSON, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return RenderMigration(migration, o.Mode)
+}
+
+// AnalyzePlan returns a state migration which reverts a given planned
+// changes with a given option. Unlike GenerateFromPlanWithOption, it returns
+// the result before rendering, so that callers can inspect actions and
+// warnings.
+func AnalyzePlan(planJSON []byte, o *GenerateOption) (*StateMigration, error) {
 	plan, err := NewPlan(planJSON)
 	if err != nil {
 		return nil, err
 	}
 
-	dictionary := NewDefaultDictionary()
-	analyzer := NewDefaultPlanAnalyzer(dictionary)
-	migration, err := analyzer.Analyze(plan, dir)
+	dictionary := o.Dictionary
+	if dictionary == nil {
+		dictionary = NewDefaultDictionary()
+	}
+	sensitive, err := NewSensitiveMode(string(o.Sensitive))
 	if err != nil {
 		return nil, err
 	}
+	analyzer := NewDefaultPlanAnalyzerWithResolvers(dictionary, sensitive, o.Resolvers...)
+	migration, err := analyzer.Analyze(plan, o.Dir)
+	if err != nil {
+		return nil, err
+	}
+	migration.GroupComments = o.GroupComments
+
+	return migration, nil
+}
 
-	return migration.Render()
+// RenderMigration converts a given state migration to bytes in a given mode.
+// If the mode is empty, GenerateModeMigration is used.
+func RenderMigration(migration *StateMigration, mode GenerateMode) ([]byte, error) {
+	switch mode {
+	case "", GenerateModeMigration:
+		return migration.Render()
+	case GenerateModeConfig:
+		return migration.RenderConfig()
+	default:
+		return nil, fmt.Errorf("unknown generate mode: %s, valid values are migration or config", mode)
+	}
 }
 
 // NewDefaultDictionary returns a default built-in Dictionary.
 func NewDefaultDictionary() *schema.Dictionary {
 	d := schema.NewDictionary()
 	aws.RegisterSchema(d)
+	azurerm.RegisterSchema(d)
+	google.RegisterSchema(d)
 	return d
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/tfedit/migration/schema"
 )
 
 func TestGenerateFromPlan(t *testing.T) {
@@ -49,7 +50,6 @@ func TestGenerateFromPlan(t *testing.T) {
   actions = [
     "import aws_s3_bucket_accelerate_configuration.example tfedit-test",
     "import aws_s3_bucket_acl.example tfedit-test,private",
-    "import aws_s3_bucket_acl.log tfedit-log,log-delivery-write",
     "import aws_s3_bucket_cors_configuration.example tfedit-test",
     "import aws_s3_bucket_lifecycle_configuration.example tfedit-test",
     "import aws_s3_bucket_logging.example tfedit-test",
@@ -60,10 +60,32 @@ func TestGenerateFromPlan(t *testing.T) {
     "import aws_s3_bucket_server_side_encryption_configuration.example tfedit-test",
     "import aws_s3_bucket_versioning.example tfedit-test",
     "import aws_s3_bucket_website_configuration.example tfedit-test",
+    "import aws_s3_bucket_acl.log tfedit-log,log-delivery-write",
+  ]
+}
+`,
+		},
+		{
+			desc:     "newer fields",
+			planFile: "test-fixtures/newer_fields.tfplan.json",
+			dir:      "",
+			ok:       true,
+			want: `# WARNING: The check for check.health has a status of fail in the plan.
+# WARNING: The import of aws_s3_bucket_acl.example will produce a non-empty plan because acl of aws_s3_bucket.example was changed outside of Terraform. The changed value is also referred to by other planned changes.
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
   ]
 }
 `,
 		},
+		{
+			desc:     "deferred changes",
+			planFile: "test-fixtures/deferred.tfplan.json",
+			dir:      "",
+			ok:       false,
+			want:     "",
+		},
 	}
 
 	for _, tc := range cases {
@@ -89,3 +111,180 @@ func TestGenerateFromPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateFromPlanWithOption(t *testing.T) {
+	cases := []struct {
+		desc          string
+		planFile      string
+		schema        string
+		mode          GenerateMode
+		groupComments bool
+		ok            bool
+		want          string
+	}{
+		{
+			desc:     "override built-in schema",
+			planFile: "test-fixtures/import_simple.tfplan.json",
+			schema: `
+import_id "aws_s3_bucket_acl" {
+  format {
+    template = "{bucket}/{acl}"
+  }
+}
+`,
+			ok: true,
+			want: `migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test/private",
+  ]
+}
+`,
+		},
+		{
+			desc:     "unknown attribute in template",
+			planFile: "test-fixtures/import_simple.tfplan.json",
+			schema: `
+import_id "aws_s3_bucket_acl" {
+  format {
+    template = "{foo}"
+  }
+}
+`,
+			ok:   false,
+			want: "",
+		},
+		{
+			desc:     "config mode",
+			planFile: "test-fixtures/import_simple.tfplan.json",
+			mode:     GenerateModeConfig,
+			ok:       true,
+			want: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+		},
+		{
+			desc:          "group comments",
+			planFile:      "test-fixtures/import_full.tfplan.json",
+			groupComments: true,
+			ok:            true,
+			want: `migration "state" "fromplan" {
+  actions = [
+    # Source: aws_s3_bucket.example
+    "import aws_s3_bucket_accelerate_configuration.example tfedit-test",
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+    "import aws_s3_bucket_cors_configuration.example tfedit-test",
+    "import aws_s3_bucket_lifecycle_configuration.example tfedit-test",
+    "import aws_s3_bucket_logging.example tfedit-test",
+    "import aws_s3_bucket_object_lock_configuration.example tfedit-test",
+    "import aws_s3_bucket_policy.example tfedit-test",
+    "import aws_s3_bucket_replication_configuration.example tfedit-test",
+    "import aws_s3_bucket_request_payment_configuration.example tfedit-test",
+    "import aws_s3_bucket_server_side_encryption_configuration.example tfedit-test",
+    "import aws_s3_bucket_versioning.example tfedit-test",
+    "import aws_s3_bucket_website_configuration.example tfedit-test",
+    # Source: aws_s3_bucket.log
+    "import aws_s3_bucket_acl.log tfedit-log,log-delivery-write",
+  ]
+}
+`,
+		},
+		{
+			desc:     "config mode with for_each",
+			planFile: "test-fixtures/import_for_each.tfplan.json",
+			mode:     GenerateModeConfig,
+			ok:       true,
+			want: `import {
+  for_each = var.buckets
+  to       = aws_s3_bucket_acl.example[each.key]
+  id       = "${each.value},private"
+}
+`,
+		},
+		{
+			desc:     "config mode with count",
+			planFile: "test-fixtures/import_count.tfplan.json",
+			mode:     GenerateModeConfig,
+			ok:       true,
+			want: `import {
+  for_each = range(2)
+  to       = aws_s3_bucket_acl.example[each.key]
+  id       = "tfedit-${each.value},private"
+}
+`,
+		},
+		{
+			desc:     "config mode with for_each over a local value",
+			planFile: "test-fixtures/import_for_each_local.tfplan.json",
+			mode:     GenerateModeConfig,
+			ok:       true,
+			want: `# WARNING: The import IDs of aws_s3_bucket_acl.example are written as a literal collection because the for_each or count expression cannot be used: for_each expression of aws_s3_bucket_acl.example is neither a constant nor a reference to an input variable of the root module
+
+import {
+  for_each = {
+    a = "tfedit-a,private"
+    b = "tfedit-b,private"
+  }
+  to = aws_s3_bucket_acl.example[each.key]
+  id = each.value
+}
+`,
+		},
+		{
+			desc:          "group comments in config mode",
+			planFile:      "test-fixtures/import_simple.tfplan.json",
+			mode:          GenerateModeConfig,
+			groupComments: true,
+			ok:            true,
+			want: `# Source: aws_s3_bucket.example
+import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+		},
+		{
+			desc:     "unknown mode",
+			planFile: "test-fixtures/import_simple.tfplan.json",
+			mode:     GenerateMode("foo"),
+			ok:       false,
+			want:     "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			planJSON, err := os.ReadFile(tc.planFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %s", err)
+			}
+
+			d := NewDefaultDictionary()
+			m, err := schema.ParseImportIDConfig([]byte(tc.schema), "schema.hcl")
+			if err != nil {
+				t.Fatalf("failed to parse schema: %s", err)
+			}
+			d.RegisterImportIDFuncMap(m)
+
+			o := &GenerateOption{
+				Dictionary:    d,
+				Mode:          tc.mode,
+				GroupComments: tc.groupComments,
+			}
+			output, err := GenerateFromPlanWithOption(planJSON, o)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
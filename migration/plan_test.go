@@ -26,6 +26,41 @@ func TestNewPlan(t *testing.T) {
 			planFile: "test-fixtures/unknown_format_version.tfplan.json",
 			ok:       false,
 		},
+		{
+			desc:     "unsupported format version",
+			planFile: "test-fixtures/unsupported_format_version.tfplan.json",
+			ok:       false,
+		},
+		{
+			desc:     "unknown terraform version",
+			planFile: "test-fixtures/unknown_terraform_version.tfplan.json",
+			ok:       false,
+		},
+		{
+			desc:     "unsupported terraform version",
+			planFile: "test-fixtures/unsupported_terraform_version.tfplan.json",
+			ok:       false,
+		},
+		{
+			desc:     "prerelease terraform version",
+			planFile: "test-fixtures/prerelease_terraform_version.tfplan.json",
+			ok:       true,
+		},
+		{
+			desc:     "dev terraform version",
+			planFile: "test-fixtures/dev_terraform_version.tfplan.json",
+			ok:       true,
+		},
+		{
+			desc:     "errored",
+			planFile: "test-fixtures/errored.tfplan.json",
+			ok:       false,
+		},
+		{
+			desc:     "newer fields",
+			planFile: "test-fixtures/newer_fields.tfplan.json",
+			ok:       true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -46,3 +81,38 @@ func TestNewPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanNewerFields(t *testing.T) {
+	planJSON, err := os.ReadFile("test-fixtures/newer_fields.tfplan.json")
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+
+	plan, err := NewPlan(planJSON)
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	if got := plan.TerraformVersion(); got != "1.9.0" {
+		t.Errorf("unexpected terraform version. got = %s", got)
+	}
+
+	drift := plan.ResourceDrift()
+	if len(drift) != 1 || drift[0].Address != "aws_s3_bucket.example" {
+		t.Errorf("unexpected resource drift. got = %#v", drift)
+	}
+
+	relevant := plan.RelevantAttributes()
+	if len(relevant) != 1 || relevant[0].Resource != "aws_s3_bucket.example" {
+		t.Errorf("unexpected relevant attributes. got = %#v", relevant)
+	}
+
+	checks := plan.Checks()
+	if len(checks) != 1 || checks[0].Address.ToDisplay != "check.health" || checks[0].Status != "fail" {
+		t.Errorf("unexpected checks. got = %#v", checks)
+	}
+
+	if deferred := plan.DeferredChanges(); len(deferred) != 0 {
+		t.Errorf("unexpected deferred changes. got = %#v", deferred)
+	}
+}
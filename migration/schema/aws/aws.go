@@ -3,6 +3,22 @@ package aws
 import "github.com/minamijoyo/tfedit/migration/schema"
 
 // RegisterSchema defines calculation functions of import ID for each resource type.
+// Note that most of resource IDs are generated by AWS and they are unknown
+// until apply, so we only define resource types whose import ID can be
+// calculated from attributes known at plan time.
 func RegisterSchema(d *schema.Dictionary) {
+	registerCloudWatchSchema(d)
+	registerDynamoDBSchema(d)
+	registerEC2Schema(d)
+	registerECRSchema(d)
+	registerELBSchema(d)
+	registerIAMSchema(d)
+	registerKMSSchema(d)
+	registerLambdaSchema(d)
+	registerRDSSchema(d)
+	registerRoute53Schema(d)
 	registerS3Schema(d)
+	registerSNSSchema(d)
+	registerSQSSchema(d)
+	registerSSMSchema(d)
 }
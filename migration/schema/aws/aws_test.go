@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestRegisterSchema(t *testing.T) {
+	cases := []struct {
+		desc         string
+		resourceType string
+		resource     string
+		ok           bool
+		want         string
+	}{
+		{
+			desc:         "iam role policy attachment",
+			resourceType: "aws_iam_role_policy_attachment",
+			resource: `
+{
+  "role": "test-role",
+  "policy_arn": "arn:aws:iam::xxxxxxxxxxxx:policy/test-policy"
+}
+`,
+			ok:   true,
+			want: "test-role/arn:aws:iam::xxxxxxxxxxxx:policy/test-policy",
+		},
+		{
+			desc:         "cloudwatch log subscription filter",
+			resourceType: "aws_cloudwatch_log_subscription_filter",
+			resource: `
+{
+  "log_group_name": "/aws/lambda/example_lambda_name",
+  "name": "test_lambdafunction_logfilter"
+}
+`,
+			ok:   true,
+			want: "/aws/lambda/example_lambda_name|test_lambdafunction_logfilter",
+		},
+		{
+			desc:         "db instance",
+			resourceType: "aws_db_instance",
+			resource: `
+{
+  "identifier": "mydb-rds-instance"
+}
+`,
+			ok:   true,
+			want: "mydb-rds-instance",
+		},
+		{
+			desc:         "lb cookie stickiness policy",
+			resourceType: "aws_lb_cookie_stickiness_policy",
+			resource: `
+{
+  "load_balancer": "my-elb",
+  "lb_port": 80,
+  "name": "my-policy"
+}
+`,
+			ok:   true,
+			want: "my-elb:80:my-policy",
+		},
+		{
+			desc:         "s3 split resource",
+			resourceType: "aws_s3_bucket_versioning",
+			resource: `
+{
+  "bucket": "tfedit-test"
+}
+`,
+			ok:   true,
+			want: "tfedit-test",
+		},
+		{
+			desc:         "computed id",
+			resourceType: "aws_iam_role",
+			resource: `
+{
+  "name_prefix": "test-"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			d := schema.NewDictionary()
+			RegisterSchema(d)
+			got, err := d.ImportID(tc.resourceType, r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
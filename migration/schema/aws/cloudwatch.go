@@ -0,0 +1,57 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerCloudWatchSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_cloudwatch_composite_alarm":         schema.ImportIDFuncByAttribute("alarm_name"),
+		"aws_cloudwatch_dashboard":               schema.ImportIDFuncByAttribute("dashboard_name"),
+		"aws_cloudwatch_event_bus":               schema.ImportIDFuncByAttribute("name"),
+		"aws_cloudwatch_event_permission":        importIDFuncAWSCloudWatchEventPermission,
+		"aws_cloudwatch_event_rule":              importIDFuncAWSCloudWatchEventRule,
+		"aws_cloudwatch_event_target":            importIDFuncAWSCloudWatchEventTarget,
+		"aws_cloudwatch_log_destination":         schema.ImportIDFuncByAttribute("name"),
+		"aws_cloudwatch_log_destination_policy":  schema.ImportIDFuncByAttribute("destination_name"),
+		"aws_cloudwatch_log_group":               schema.ImportIDFuncByAttribute("name"),
+		"aws_cloudwatch_log_metric_filter":       schema.ImportIDFuncByTemplate("{log_group_name}:{name}"),
+		"aws_cloudwatch_log_resource_policy":     schema.ImportIDFuncByAttribute("policy_name"),
+		"aws_cloudwatch_log_stream":              schema.ImportIDFuncByTemplate("{log_group_name}:{name}"),
+		"aws_cloudwatch_log_subscription_filter": schema.ImportIDFuncByTemplate("{log_group_name}|{name}"),
+		"aws_cloudwatch_metric_alarm":            schema.ImportIDFuncByAttribute("alarm_name"),
+	})
+}
+
+// isDefaultEventBus returns true if a given resource belongs to the default event bus.
+func isDefaultEventBus(r schema.Resource) bool {
+	return isEmptyAttribute(r, "event_bus_name") || r["event_bus_name"] == "default"
+}
+
+// importIDFuncAWSCloudWatchEventPermission is an implementation of importIDFunc for aws_cloudwatch_event_permission.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/cloudwatch_event_permission#import
+func importIDFuncAWSCloudWatchEventPermission(r schema.Resource) (string, error) {
+	if isDefaultEventBus(r) {
+		return schema.ImportIDFuncByAttribute("statement_id")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{event_bus_name}/{statement_id}")(r)
+}
+
+// importIDFuncAWSCloudWatchEventRule is an implementation of importIDFunc for aws_cloudwatch_event_rule.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/cloudwatch_event_rule#import
+func importIDFuncAWSCloudWatchEventRule(r schema.Resource) (string, error) {
+	if isDefaultEventBus(r) {
+		return schema.ImportIDFuncByAttribute("name")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{event_bus_name}/{name}")(r)
+}
+
+// importIDFuncAWSCloudWatchEventTarget is an implementation of importIDFunc for aws_cloudwatch_event_target.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/cloudwatch_event_target#import
+func importIDFuncAWSCloudWatchEventTarget(r schema.Resource) (string, error) {
+	if isDefaultEventBus(r) {
+		return schema.ImportIDFuncByTemplate("{rule}/{target_id}")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{event_bus_name}/{rule}/{target_id}")(r)
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAWSCloudWatchEventTarget(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "default event bus",
+			resource: `
+{
+  "event_bus_name": "default",
+  "rule": "rule-name",
+  "target_id": "target-id"
+}
+`,
+			ok:   true,
+			want: "rule-name/target-id",
+		},
+		{
+			desc: "custom event bus",
+			resource: `
+{
+  "event_bus_name": "event-bus",
+  "rule": "rule-name",
+  "target_id": "target-id"
+}
+`,
+			ok:   true,
+			want: "event-bus/rule-name/target-id",
+		},
+		{
+			desc: "unknown target id",
+			resource: `
+{
+  "event_bus_name": null,
+  "rule": "rule-name"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAWSCloudWatchEventTarget(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerDynamoDBSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_dynamodb_kinesis_streaming_destination": schema.ImportIDFuncByTemplate("{table_name},{stream_arn}"),
+		"aws_dynamodb_table":                         schema.ImportIDFuncByAttribute("name"),
+		"aws_dynamodb_tag":                           schema.ImportIDFuncByTemplate("{resource_arn},{key}"),
+	})
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func registerEC2Schema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_ami_launch_permission":                       schema.ImportIDFuncByTemplate("{account_id}/{image_id}"),
+		"aws_ec2_tag":                                     schema.ImportIDFuncByTemplate("{resource_id},{key}"),
+		"aws_ec2_transit_gateway_route":                   schema.ImportIDFuncByTemplate("{transit_gateway_route_table_id}_{destination_cidr_block}"),
+		"aws_ec2_transit_gateway_route_table_association": schema.ImportIDFuncByTemplate("{transit_gateway_route_table_id}_{transit_gateway_attachment_id}"),
+		"aws_ec2_transit_gateway_route_table_propagation": schema.ImportIDFuncByTemplate("{transit_gateway_route_table_id}_{transit_gateway_attachment_id}"),
+		"aws_internet_gateway_attachment":                 schema.ImportIDFuncByTemplate("{internet_gateway_id}:{vpc_id}"),
+		"aws_key_pair":                                    schema.ImportIDFuncByAttribute("key_name"),
+		"aws_network_interface_sg_attachment":             schema.ImportIDFuncByTemplate("{network_interface_id}_{security_group_id}"),
+		"aws_placement_group":                             schema.ImportIDFuncByAttribute("name"),
+		"aws_route":                                       importIDFuncAWSRoute,
+		"aws_route_table_association":                     importIDFuncAWSRouteTableAssociation,
+		"aws_security_group_rule":                         importIDFuncAWSSecurityGroupRule,
+		"aws_volume_attachment":                           schema.ImportIDFuncByTemplate("{device_name}:{volume_id}:{instance_id}"),
+		"aws_vpc_dhcp_options_association":                schema.ImportIDFuncByAttribute("vpc_id"),
+		"aws_vpc_endpoint_route_table_association":        schema.ImportIDFuncByTemplate("{vpc_endpoint_id}/{route_table_id}"),
+		"aws_vpc_endpoint_subnet_association":             schema.ImportIDFuncByTemplate("{vpc_endpoint_id}/{subnet_id}"),
+	})
+}
+
+// importIDFuncAWSRoute is an implementation of importIDFunc for aws_route.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/route#import
+func importIDFuncAWSRoute(r schema.Resource) (string, error) {
+	// The destination is one of the following arguments.
+	for _, key := range []string{"destination_cidr_block", "destination_ipv6_cidr_block", "destination_prefix_list_id"} {
+		if !isEmptyAttribute(r, key) {
+			return schema.ImportIDFuncByTemplate("{route_table_id}_{" + key + "}")(r)
+		}
+	}
+
+	return "", fmt.Errorf("failed to detect an ID of aws_route resource for import: %#v", r)
+}
+
+// importIDFuncAWSRouteTableAssociation is an implementation of importIDFunc for aws_route_table_association.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/route_table_association#import
+func importIDFuncAWSRouteTableAssociation(r schema.Resource) (string, error) {
+	// The subnet_id argument conflicts with gateway_id
+	switch {
+	case !isEmptyAttribute(r, "subnet_id"):
+		return schema.ImportIDFuncByTemplate("{subnet_id}/{route_table_id}")(r)
+	case !isEmptyAttribute(r, "gateway_id"):
+		return schema.ImportIDFuncByTemplate("{gateway_id}/{route_table_id}")(r)
+	default:
+		return "", fmt.Errorf("failed to detect an ID of aws_route_table_association resource for import: %#v", r)
+	}
+}
+
+// importIDFuncAWSSecurityGroupRule is an implementation of importIDFunc for aws_security_group_rule.
+// The import ID is a list of the following elements joined by underscores:
+// security_group_id, type, protocol, from_port, to_port and sources.
+// (e.g. sg-6e616f6d69_ingress_tcp_8000_8000_10.0.3.0/24_10.0.4.0/24)
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/security_group_rule#import
+func importIDFuncAWSSecurityGroupRule(r schema.Resource) (string, error) {
+	head, err := schema.ImportIDFuncByTemplate("{security_group_id}_{type}")(r)
+	if err != nil {
+		return "", err
+	}
+
+	protocol, ok := r["protocol"].(string)
+	if !ok {
+		return "", fmt.Errorf("failed to cast protocol = %#v to string as an element of import ID", r["protocol"])
+	}
+	// The protocol -1 means all.
+	if protocol == "-1" {
+		protocol = "all"
+	}
+
+	elems := []string{head, protocol}
+	if protocol == "all" {
+		// The ports are ignored for all protocols and usually written as 0, but
+		// the import ID requires the full range.
+		// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/security_group_rule#import
+		elems = append(elems, "0", "65536")
+	} else {
+		for _, key := range []string{"from_port", "to_port"} {
+			port, ok := r[key].(float64)
+			if !ok {
+				return "", fmt.Errorf("failed to cast %s = %#v to number as an element of import ID", key, r[key])
+			}
+			elems = append(elems, strconv.FormatFloat(port, 'f', -1, 64))
+		}
+	}
+
+	sources := []string{}
+	for _, key := range []string{"cidr_blocks", "ipv6_cidr_blocks", "prefix_list_ids"} {
+		values, _ := r[key].([]interface{})
+		for _, v := range values {
+			s, ok := v.(string)
+			if !ok {
+				return "", fmt.Errorf("failed to cast an element of %s = %#v to string as an element of import ID", key, r[key])
+			}
+			sources = append(sources, s)
+		}
+	}
+	if self, ok := r["self"].(bool); ok && self {
+		sources = append(sources, "self")
+	}
+	if sg, ok := r["source_security_group_id"].(string); ok && sg != "" {
+		sources = append(sources, sg)
+	}
+
+	if len(sources) == 0 {
+		return "", fmt.Errorf("failed to detect a source of aws_security_group_rule resource for import: %#v", r)
+	}
+	elems = append(elems, sources...)
+
+	return strings.Join(elems, "_"), nil
+}
@@ -0,0 +1,308 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAWSRoute(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "ipv4",
+			resource: `
+{
+  "route_table_id": "rtb-656c65616e6f72",
+  "destination_cidr_block": "10.42.0.0/16",
+  "destination_ipv6_cidr_block": null,
+  "destination_prefix_list_id": null
+}
+`,
+			ok:   true,
+			want: "rtb-656c65616e6f72_10.42.0.0/16",
+		},
+		{
+			desc: "ipv6",
+			resource: `
+{
+  "route_table_id": "rtb-656c65616e6f72",
+  "destination_cidr_block": "",
+  "destination_ipv6_cidr_block": "2620:0:2d0:200::8/125",
+  "destination_prefix_list_id": null
+}
+`,
+			ok:   true,
+			want: "rtb-656c65616e6f72_2620:0:2d0:200::8/125",
+		},
+		{
+			desc: "prefix list",
+			resource: `
+{
+  "route_table_id": "rtb-656c65616e6f72",
+  "destination_cidr_block": null,
+  "destination_ipv6_cidr_block": null,
+  "destination_prefix_list_id": "pl-0570a1d2d725c16be"
+}
+`,
+			ok:   true,
+			want: "rtb-656c65616e6f72_pl-0570a1d2d725c16be",
+		},
+		{
+			desc: "destination not found",
+			resource: `
+{
+  "route_table_id": "rtb-656c65616e6f72",
+  "destination_cidr_block": null,
+  "destination_ipv6_cidr_block": null,
+  "destination_prefix_list_id": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAWSRoute(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportIDFuncAWSRouteTableAssociation(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "subnet",
+			resource: `
+{
+  "route_table_id": "rtb-4176657279",
+  "subnet_id": "subnet-6777656e646f6c796e",
+  "gateway_id": null
+}
+`,
+			ok:   true,
+			want: "subnet-6777656e646f6c796e/rtb-4176657279",
+		},
+		{
+			desc: "gateway",
+			resource: `
+{
+  "route_table_id": "rtb-4176657279",
+  "subnet_id": null,
+  "gateway_id": "igw-01b3a60780f8d034a"
+}
+`,
+			ok:   true,
+			want: "igw-01b3a60780f8d034a/rtb-4176657279",
+		},
+		{
+			desc: "invalid",
+			resource: `
+{
+  "route_table_id": "rtb-4176657279",
+  "subnet_id": null,
+  "gateway_id": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAWSRouteTableAssociation(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportIDFuncAWSSecurityGroupRule(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "cidr blocks",
+			resource: `
+{
+  "security_group_id": "sg-4973616163",
+  "type": "ingress",
+  "protocol": "tcp",
+  "from_port": 100,
+  "to_port": 121,
+  "cidr_blocks": ["10.1.0.0/16", "10.2.0.0/16"],
+  "ipv6_cidr_blocks": ["2001:db8::/48"],
+  "prefix_list_ids": [],
+  "self": false,
+  "source_security_group_id": null
+}
+`,
+			ok:   true,
+			want: "sg-4973616163_ingress_tcp_100_121_10.1.0.0/16_10.2.0.0/16_2001:db8::/48",
+		},
+		{
+			desc: "self with all protocols",
+			resource: `
+{
+  "security_group_id": "sg-6777656e646f6c796e",
+  "type": "ingress",
+  "protocol": "-1",
+  "from_port": 0,
+  "to_port": 0,
+  "cidr_blocks": null,
+  "ipv6_cidr_blocks": null,
+  "prefix_list_ids": null,
+  "self": true,
+  "source_security_group_id": null
+}
+`,
+			ok:   true,
+			want: "sg-6777656e646f6c796e_ingress_all_0_65536_self",
+		},
+		{
+			desc: "source security group",
+			resource: `
+{
+  "security_group_id": "sg-6e616f6d69",
+  "type": "ingress",
+  "protocol": "tcp",
+  "from_port": 8000,
+  "to_port": 8000,
+  "cidr_blocks": null,
+  "ipv6_cidr_blocks": null,
+  "prefix_list_ids": null,
+  "self": false,
+  "source_security_group_id": "sg-6777656e646f6c796e"
+}
+`,
+			ok:   true,
+			want: "sg-6e616f6d69_ingress_tcp_8000_8000_sg-6777656e646f6c796e",
+		},
+		{
+			desc: "prefix list",
+			resource: `
+{
+  "security_group_id": "sg-62726f6479",
+  "type": "egress",
+  "protocol": "tcp",
+  "from_port": 8000,
+  "to_port": 8000,
+  "cidr_blocks": null,
+  "ipv6_cidr_blocks": null,
+  "prefix_list_ids": ["pl-6469726b"],
+  "self": false,
+  "source_security_group_id": null
+}
+`,
+			ok:   true,
+			want: "sg-62726f6479_egress_tcp_8000_8000_pl-6469726b",
+		},
+		{
+			desc: "source not found",
+			resource: `
+{
+  "security_group_id": "sg-62726f6479",
+  "type": "egress",
+  "protocol": "tcp",
+  "from_port": 8000,
+  "to_port": 8000,
+  "cidr_blocks": null,
+  "ipv6_cidr_blocks": null,
+  "prefix_list_ids": null,
+  "self": false,
+  "source_security_group_id": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+		{
+			desc: "unknown port",
+			resource: `
+{
+  "security_group_id": "sg-62726f6479",
+  "type": "egress",
+  "protocol": "tcp",
+  "to_port": 8000,
+  "cidr_blocks": ["10.1.0.0/16"],
+  "ipv6_cidr_blocks": null,
+  "prefix_list_ids": null,
+  "self": false,
+  "source_security_group_id": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAWSSecurityGroupRule(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerECRSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_ecr_lifecycle_policy":  schema.ImportIDFuncByAttribute("repository"),
+		"aws_ecr_repository":        schema.ImportIDFuncByAttribute("name"),
+		"aws_ecr_repository_policy": schema.ImportIDFuncByAttribute("repository"),
+	})
+}
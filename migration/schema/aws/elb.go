@@ -0,0 +1,12 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerELBSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_app_cookie_stickiness_policy": schema.ImportIDFuncByTemplate("{load_balancer}:{lb_port}:{name}"),
+		"aws_elb":                          schema.ImportIDFuncByAttribute("name"),
+		"aws_lb_cookie_stickiness_policy":  schema.ImportIDFuncByTemplate("{load_balancer}:{lb_port}:{name}"),
+		"aws_lb_listener_certificate":      schema.ImportIDFuncByTemplate("{listener_arn}_{certificate_arn}"),
+	})
+}
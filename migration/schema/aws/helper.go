@@ -0,0 +1,18 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+// isEmptyAttribute returns true if a given attribute is not set.
+// Some optional attributes are planned as an empty string instead of null.
+func isEmptyAttribute(r schema.Resource, key string) bool {
+	v, ok := r[key]
+	if !ok || v == nil {
+		return true
+	}
+
+	if s, ok := v.(string); ok && s == "" {
+		return true
+	}
+
+	return false
+}
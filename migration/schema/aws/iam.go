@@ -0,0 +1,21 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerIAMSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_iam_account_alias":           schema.ImportIDFuncByAttribute("account_alias"),
+		"aws_iam_group":                   schema.ImportIDFuncByAttribute("name"),
+		"aws_iam_group_policy":            schema.ImportIDFuncByTemplate("{group}:{name}"),
+		"aws_iam_group_policy_attachment": schema.ImportIDFuncByTemplate("{group}/{policy_arn}"),
+		"aws_iam_instance_profile":        schema.ImportIDFuncByAttribute("name"),
+		"aws_iam_role":                    schema.ImportIDFuncByAttribute("name"),
+		"aws_iam_role_policy":             schema.ImportIDFuncByTemplate("{role}:{name}"),
+		"aws_iam_role_policy_attachment":  schema.ImportIDFuncByTemplate("{role}/{policy_arn}"),
+		"aws_iam_server_certificate":      schema.ImportIDFuncByAttribute("name"),
+		"aws_iam_user":                    schema.ImportIDFuncByAttribute("name"),
+		"aws_iam_user_login_profile":      schema.ImportIDFuncByAttribute("user"),
+		"aws_iam_user_policy":             schema.ImportIDFuncByTemplate("{user}:{name}"),
+		"aws_iam_user_policy_attachment":  schema.ImportIDFuncByTemplate("{user}/{policy_arn}"),
+	})
+}
@@ -0,0 +1,9 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerKMSSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_kms_alias": schema.ImportIDFuncByAttribute("name"),
+	})
+}
@@ -0,0 +1,44 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerLambdaSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_lambda_alias":                          schema.ImportIDFuncByTemplate("{function_name}/{name}"),
+		"aws_lambda_function":                       schema.ImportIDFuncByAttribute("function_name"),
+		"aws_lambda_function_event_invoke_config":   importIDFuncAWSLambdaFunctionEventInvokeConfig,
+		"aws_lambda_function_url":                   importIDFuncAWSLambdaFunctionURL,
+		"aws_lambda_permission":                     importIDFuncAWSLambdaPermission,
+		"aws_lambda_provisioned_concurrency_config": schema.ImportIDFuncByTemplate("{function_name},{qualifier}"),
+	})
+}
+
+// importIDFuncAWSLambdaFunctionEventInvokeConfig is an implementation of importIDFunc for aws_lambda_function_event_invoke_config.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/lambda_function_event_invoke_config#import
+func importIDFuncAWSLambdaFunctionEventInvokeConfig(r schema.Resource) (string, error) {
+	if !isEmptyAttribute(r, "qualifier") {
+		return schema.ImportIDFuncByTemplate("{function_name}:{qualifier}")(r)
+	}
+
+	return schema.ImportIDFuncByAttribute("function_name")(r)
+}
+
+// importIDFuncAWSLambdaFunctionURL is an implementation of importIDFunc for aws_lambda_function_url.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/lambda_function_url#import
+func importIDFuncAWSLambdaFunctionURL(r schema.Resource) (string, error) {
+	if !isEmptyAttribute(r, "qualifier") {
+		return schema.ImportIDFuncByTemplate("{function_name}/{qualifier}")(r)
+	}
+
+	return schema.ImportIDFuncByAttribute("function_name")(r)
+}
+
+// importIDFuncAWSLambdaPermission is an implementation of importIDFunc for aws_lambda_permission.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/lambda_permission#import
+func importIDFuncAWSLambdaPermission(r schema.Resource) (string, error) {
+	if !isEmptyAttribute(r, "qualifier") {
+		return schema.ImportIDFuncByTemplate("{function_name}:{qualifier}/{statement_id}")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{function_name}/{statement_id}")(r)
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAWSLambdaPermission(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "simple",
+			resource: `
+{
+  "function_name": "my_test_lambda_function",
+  "statement_id": "AllowExecutionFromCloudWatch",
+  "qualifier": null
+}
+`,
+			ok:   true,
+			want: "my_test_lambda_function/AllowExecutionFromCloudWatch",
+		},
+		{
+			desc: "qualifier",
+			resource: `
+{
+  "function_name": "my_test_lambda_function",
+  "statement_id": "AllowExecutionFromCloudWatch",
+  "qualifier": "user_alias"
+}
+`,
+			ok:   true,
+			want: "my_test_lambda_function:user_alias/AllowExecutionFromCloudWatch",
+		},
+		{
+			desc: "unknown statement id",
+			resource: `
+{
+  "function_name": "my_test_lambda_function",
+  "qualifier": ""
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAWSLambdaPermission(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerRDSSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_db_event_subscription":        schema.ImportIDFuncByAttribute("name"),
+		"aws_db_instance":                  schema.ImportIDFuncByAttribute("identifier"),
+		"aws_db_instance_role_association": schema.ImportIDFuncByTemplate("{db_instance_identifier},{role_arn}"),
+		"aws_db_option_group":              schema.ImportIDFuncByAttribute("name"),
+		"aws_db_parameter_group":           schema.ImportIDFuncByAttribute("name"),
+		"aws_db_proxy":                     schema.ImportIDFuncByAttribute("name"),
+		"aws_db_snapshot":                  schema.ImportIDFuncByAttribute("db_snapshot_identifier"),
+		"aws_db_subnet_group":              schema.ImportIDFuncByAttribute("name"),
+		"aws_rds_cluster":                  schema.ImportIDFuncByAttribute("cluster_identifier"),
+		"aws_rds_cluster_endpoint":         schema.ImportIDFuncByAttribute("cluster_endpoint_identifier"),
+		"aws_rds_cluster_instance":         schema.ImportIDFuncByAttribute("identifier"),
+		"aws_rds_cluster_parameter_group":  schema.ImportIDFuncByAttribute("name"),
+		"aws_rds_cluster_role_association": schema.ImportIDFuncByTemplate("{db_cluster_identifier},{role_arn}"),
+		"aws_rds_global_cluster":           schema.ImportIDFuncByAttribute("global_cluster_identifier"),
+	})
+}
@@ -0,0 +1,25 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerRoute53Schema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_route53_hosted_zone_dnssec":            schema.ImportIDFuncByAttribute("hosted_zone_id"),
+		"aws_route53_key_signing_key":               schema.ImportIDFuncByTemplate("{hosted_zone_id},{name}"),
+		"aws_route53_record":                        importIDFuncAWSRoute53Record,
+		"aws_route53_vpc_association_authorization": schema.ImportIDFuncByTemplate("{zone_id}:{vpc_id}"),
+		"aws_route53_zone_association":              schema.ImportIDFuncByTemplate("{zone_id}:{vpc_id}"),
+	})
+}
+
+// importIDFuncAWSRoute53Record is an implementation of importIDFunc for aws_route53_record.
+// Note that the name should be a fully qualified domain name.
+// https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/route53_record#import
+func importIDFuncAWSRoute53Record(r schema.Resource) (string, error) {
+	// A set_identifier is required only for routing policies.
+	if !isEmptyAttribute(r, "set_identifier") {
+		return schema.ImportIDFuncByTemplate("{zone_id}_{name}_{type}_{set_identifier}")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{zone_id}_{name}_{type}")(r)
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAWSRoute53Record(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "simple",
+			resource: `
+{
+  "zone_id": "Z4KAPRWWNC7JR",
+  "name": "dev.example.com",
+  "type": "NS",
+  "set_identifier": null
+}
+`,
+			ok:   true,
+			want: "Z4KAPRWWNC7JR_dev.example.com_NS",
+		},
+		{
+			desc: "set identifier",
+			resource: `
+{
+  "zone_id": "Z4KAPRWWNC7JR",
+  "name": "dev.example.com",
+  "type": "NS",
+  "set_identifier": "dev"
+}
+`,
+			ok:   true,
+			want: "Z4KAPRWWNC7JR_dev.example.com_NS_dev",
+		},
+		{
+			desc: "unknown zone",
+			resource: `
+{
+  "name": "dev.example.com",
+  "type": "NS",
+  "set_identifier": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAWSRoute53Record(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
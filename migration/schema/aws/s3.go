@@ -8,18 +8,27 @@ import (
 
 func registerS3Schema(d *schema.Dictionary) {
 	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_s3_bucket":                                      schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_accelerate_configuration":             schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_acl":                                  importIDFuncAWSS3BucketACL,
+		"aws_s3_bucket_analytics_configuration":              schema.ImportIDFuncByTemplate("{bucket}:{name}"),
 		"aws_s3_bucket_cors_configuration":                   schema.ImportIDFuncByAttribute("bucket"),
+		"aws_s3_bucket_intelligent_tiering_configuration":    schema.ImportIDFuncByTemplate("{bucket}:{name}"),
+		"aws_s3_bucket_inventory":                            schema.ImportIDFuncByTemplate("{bucket}:{name}"),
 		"aws_s3_bucket_lifecycle_configuration":              schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_logging":                              schema.ImportIDFuncByAttribute("bucket"),
+		"aws_s3_bucket_metric":                               schema.ImportIDFuncByTemplate("{bucket}:{name}"),
+		"aws_s3_bucket_notification":                         schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_object_lock_configuration":            schema.ImportIDFuncByAttribute("bucket"),
+		"aws_s3_bucket_ownership_controls":                   schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_policy":                               schema.ImportIDFuncByAttribute("bucket"),
+		"aws_s3_bucket_public_access_block":                  schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_replication_configuration":            schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_request_payment_configuration":        schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_server_side_encryption_configuration": schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_versioning":                           schema.ImportIDFuncByAttribute("bucket"),
 		"aws_s3_bucket_website_configuration":                schema.ImportIDFuncByAttribute("bucket"),
+		"aws_s3_object":                                      schema.ImportIDFuncByTemplate("{bucket}/{key}"),
 	})
 }
 
@@ -0,0 +1,9 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerSNSSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_sns_topic_policy": schema.ImportIDFuncByAttribute("arn"),
+	})
+}
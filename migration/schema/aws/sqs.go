@@ -0,0 +1,11 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerSQSSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_sqs_queue_policy":               schema.ImportIDFuncByAttribute("queue_url"),
+		"aws_sqs_queue_redrive_policy":       schema.ImportIDFuncByAttribute("queue_url"),
+		"aws_sqs_queue_redrive_allow_policy": schema.ImportIDFuncByAttribute("queue_url"),
+	})
+}
@@ -0,0 +1,10 @@
+package aws
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerSSMSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"aws_ssm_document":  schema.ImportIDFuncByAttribute("name"),
+		"aws_ssm_parameter": schema.ImportIDFuncByAttribute("name"),
+	})
+}
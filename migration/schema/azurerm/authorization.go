@@ -0,0 +1,11 @@
+package azurerm
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerAuthorizationSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_management_lock": schema.ImportIDFuncByTemplate("{scope}/providers/Microsoft.Authorization/locks/{name}"),
+		"azurerm_role_assignment": schema.ImportIDFuncByTemplate("{scope}/providers/Microsoft.Authorization/roleAssignments/{name}"),
+		"azurerm_role_definition": schema.ImportIDFuncByTemplate("{scope}/providers/Microsoft.Authorization/roleDefinitions/{role_definition_id}|{scope}"),
+	})
+}
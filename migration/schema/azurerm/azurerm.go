@@ -0,0 +1,20 @@
+package azurerm
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+// RegisterSchema defines calculation functions of import ID for each resource type.
+// Resource IDs in Azure are hierarchical paths like
+// `/subscriptions/{subscription}/resourceGroups/{group}/providers/...`.
+// Since the id attribute of a new resource is unknown at plan time, we can
+// only define import IDs which are derived from the id attributes of other
+// resources referenced as `*_id`, that is, ones for sub resources and
+// associations.
+func RegisterSchema(d *schema.Dictionary) {
+	registerAuthorizationSchema(d)
+	registerDatabaseSchema(d)
+	registerKeyVaultSchema(d)
+	registerMonitorSchema(d)
+	registerNetworkSchema(d)
+	registerStorageSchema(d)
+	registerComputeSchema(d)
+}
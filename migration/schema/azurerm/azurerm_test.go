@@ -0,0 +1,116 @@
+package azurerm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestRegisterSchema(t *testing.T) {
+	cases := []struct {
+		desc         string
+		resourceType string
+		resource     string
+		ok           bool
+		want         string
+	}{
+		{
+			desc:         "subnet association",
+			resourceType: "azurerm_subnet_network_security_group_association",
+			resource: `
+{
+  "subnet_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/mygroup1/providers/Microsoft.Network/virtualNetworks/myvnet1/subnets/mysubnet1",
+  "network_security_group_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/mygroup1/providers/Microsoft.Network/networkSecurityGroups/group1"
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/mygroup1/providers/Microsoft.Network/virtualNetworks/myvnet1/subnets/mysubnet1",
+		},
+		{
+			desc:         "network interface association",
+			resourceType: "azurerm_network_interface_security_group_association",
+			resource: `
+{
+  "network_interface_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Network/networkInterfaces/nic1",
+  "network_security_group_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Network/networkSecurityGroups/group1"
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Network/networkInterfaces/nic1|/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Network/networkSecurityGroups/group1",
+		},
+		{
+			desc:         "mssql database",
+			resourceType: "azurerm_mssql_database",
+			resource: `
+{
+  "server_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Sql/servers/server1",
+  "name": "example1"
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Sql/servers/server1/databases/example1",
+		},
+		{
+			desc:         "management lock",
+			resourceType: "azurerm_management_lock",
+			resource: `
+{
+  "scope": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1",
+  "name": "lock1"
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Authorization/locks/lock1",
+		},
+		{
+			desc:         "monitor diagnostic setting",
+			resourceType: "azurerm_monitor_diagnostic_setting",
+			resource: `
+{
+  "target_resource_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/example-resources/providers/Microsoft.KeyVault/vaults/vault1",
+  "name": "logMonitoring1"
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/example-resources/providers/Microsoft.KeyVault/vaults/vault1|logMonitoring1",
+		},
+		{
+			desc:         "computed name",
+			resourceType: "azurerm_role_assignment",
+			resource: `
+{
+  "scope": "/subscriptions/00000000-0000-0000-0000-000000000000",
+  "name": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			d := schema.NewDictionary()
+			RegisterSchema(d)
+			got, err := d.ImportID(tc.resourceType, r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
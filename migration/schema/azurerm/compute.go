@@ -0,0 +1,34 @@
+package azurerm
+
+import (
+	"fmt"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func registerComputeSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_virtual_machine_data_disk_attachment": importIDFuncAzurermVirtualMachineDataDiskAttachment,
+	})
+}
+
+// importIDFuncAzurermVirtualMachineDataDiskAttachment is an implementation of importIDFunc for azurerm_virtual_machine_data_disk_attachment.
+// https://registry.terraform.io/providers/hashicorp/azurerm/latest/docs/resources/virtual_machine_data_disk_attachment#import
+func importIDFuncAzurermVirtualMachineDataDiskAttachment(r schema.Resource) (string, error) {
+	vmID, ok := r["virtual_machine_id"].(string)
+	if !ok || vmID == "" {
+		return "", fmt.Errorf("failed to get virtual_machine_id: %#v", r)
+	}
+
+	diskID, ok := r["managed_disk_id"].(string)
+	if !ok || diskID == "" {
+		return "", fmt.Errorf("failed to get managed_disk_id: %#v", r)
+	}
+
+	diskName, err := lastSegment(diskID, "disks")
+	if err != nil {
+		return "", err
+	}
+
+	return vmID + "/dataDisks/" + diskName, nil
+}
@@ -0,0 +1,74 @@
+package azurerm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAzurermVirtualMachineDataDiskAttachment(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "simple",
+			resource: `
+{
+  "virtual_machine_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Compute/virtualMachines/machine1",
+  "managed_disk_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Compute/disks/disk1",
+  "lun": 10
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Compute/virtualMachines/machine1/dataDisks/disk1",
+		},
+		{
+			desc: "invalid disk id",
+			resource: `
+{
+  "virtual_machine_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Compute/virtualMachines/machine1",
+  "managed_disk_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+		{
+			desc: "unknown disk id",
+			resource: `
+{
+  "virtual_machine_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.Compute/virtualMachines/machine1"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAzurermVirtualMachineDataDiskAttachment(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package azurerm
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerDatabaseSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_mssql_database":                           schema.ImportIDFuncByTemplate("{server_id}/databases/{name}"),
+		"azurerm_mssql_firewall_rule":                      schema.ImportIDFuncByTemplate("{server_id}/firewallRules/{name}"),
+		"azurerm_mssql_virtual_network_rule":               schema.ImportIDFuncByTemplate("{server_id}/virtualNetworkRules/{name}"),
+		"azurerm_postgresql_flexible_server_configuration": schema.ImportIDFuncByTemplate("{server_id}/configurations/{name}"),
+		"azurerm_postgresql_flexible_server_database":      schema.ImportIDFuncByTemplate("{server_id}/databases/{name}"),
+		"azurerm_postgresql_flexible_server_firewall_rule": schema.ImportIDFuncByTemplate("{server_id}/firewallRules/{name}"),
+	})
+}
@@ -0,0 +1,26 @@
+package azurerm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subscriptionID returns a subscription ID from a given resource ID.
+func subscriptionID(id string) (string, error) {
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	if len(segments) < 2 || !strings.EqualFold(segments[0], "subscriptions") || segments[1] == "" {
+		return "", fmt.Errorf("failed to parse subscription in resource ID: %s", id)
+	}
+	return segments[1], nil
+}
+
+// lastSegment returns the last segment of a given resource ID.
+// It also validates that the preceding segment matches a given key.
+func lastSegment(id string, key string) (string, error) {
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	n := len(segments)
+	if n < 2 || !strings.EqualFold(segments[n-2], key) || segments[n-1] == "" {
+		return "", fmt.Errorf("failed to parse %s in resource ID: %s", key, id)
+	}
+	return segments[n-1], nil
+}
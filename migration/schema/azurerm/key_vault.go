@@ -0,0 +1,20 @@
+package azurerm
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerKeyVaultSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_key_vault_access_policy": importIDFuncAzurermKeyVaultAccessPolicy,
+	})
+}
+
+// importIDFuncAzurermKeyVaultAccessPolicy is an implementation of importIDFunc for azurerm_key_vault_access_policy.
+// https://registry.terraform.io/providers/hashicorp/azurerm/latest/docs/resources/key_vault_access_policy#import
+func importIDFuncAzurermKeyVaultAccessPolicy(r schema.Resource) (string, error) {
+	// The application_id is optional.
+	if appID, ok := r["application_id"].(string); ok && appID != "" {
+		return schema.ImportIDFuncByTemplate("{key_vault_id}/objectId/{object_id}/applicationId/{application_id}")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{key_vault_id}/objectId/{object_id}")(r)
+}
@@ -0,0 +1,9 @@
+package azurerm
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerMonitorSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_monitor_diagnostic_setting": schema.ImportIDFuncByTemplate("{target_resource_id}|{name}"),
+	})
+}
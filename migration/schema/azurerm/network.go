@@ -0,0 +1,41 @@
+package azurerm
+
+import (
+	"fmt"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func registerNetworkSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_nat_gateway_public_ip_association":                        schema.ImportIDFuncByTemplate("{nat_gateway_id}|{public_ip_address_id}"),
+		"azurerm_nat_gateway_public_ip_prefix_association":                 schema.ImportIDFuncByTemplate("{nat_gateway_id}|{public_ip_prefix_id}"),
+		"azurerm_network_interface_application_security_group_association": schema.ImportIDFuncByTemplate("{network_interface_id}|{application_security_group_id}"),
+		"azurerm_network_interface_backend_address_pool_association":       schema.ImportIDFuncByTemplate("{network_interface_id}/ipConfigurations/{ip_configuration_name}|{backend_address_pool_id}"),
+		"azurerm_network_interface_nat_rule_association":                   schema.ImportIDFuncByTemplate("{network_interface_id}/ipConfigurations/{ip_configuration_name}|{nat_rule_id}"),
+		"azurerm_network_interface_security_group_association":             schema.ImportIDFuncByTemplate("{network_interface_id}|{network_security_group_id}"),
+		"azurerm_private_dns_zone_virtual_network_link":                    importIDFuncAzurermPrivateDNSZoneVirtualNetworkLink,
+		"azurerm_subnet_nat_gateway_association":                           schema.ImportIDFuncByAttribute("subnet_id"),
+		"azurerm_subnet_network_security_group_association":                schema.ImportIDFuncByAttribute("subnet_id"),
+		"azurerm_subnet_route_table_association":                           schema.ImportIDFuncByAttribute("subnet_id"),
+	})
+}
+
+// importIDFuncAzurermPrivateDNSZoneVirtualNetworkLink is an implementation of importIDFunc for azurerm_private_dns_zone_virtual_network_link.
+// https://registry.terraform.io/providers/hashicorp/azurerm/latest/docs/resources/private_dns_zone_virtual_network_link#import
+func importIDFuncAzurermPrivateDNSZoneVirtualNetworkLink(r schema.Resource) (string, error) {
+	// The resource has no attribute for subscription.
+	// Assume that the virtual network is in the same subscription.
+	vnetID, ok := r["virtual_network_id"].(string)
+	if !ok || vnetID == "" {
+		return "", fmt.Errorf("failed to get virtual_network_id: %#v", r)
+	}
+
+	subscription, err := subscriptionID(vnetID)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl := "/subscriptions/" + subscription + "/resourceGroups/{resource_group_name}/providers/Microsoft.Network/privateDnsZones/{private_dns_zone_name}/virtualNetworkLinks/{name}"
+	return schema.ImportIDFuncByTemplate(tmpl)(r)
+}
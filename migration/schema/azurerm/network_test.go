@@ -0,0 +1,66 @@
+package azurerm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAzurermPrivateDNSZoneVirtualNetworkLink(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "simple",
+			resource: `
+{
+  "name": "test",
+  "resource_group_name": "example-resources",
+  "private_dns_zone_name": "zone1.com",
+  "virtual_network_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/network-resources/providers/Microsoft.Network/virtualNetworks/vnet1"
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/example-resources/providers/Microsoft.Network/privateDnsZones/zone1.com/virtualNetworkLinks/test",
+		},
+		{
+			desc: "unknown virtual network id",
+			resource: `
+{
+  "name": "test",
+  "resource_group_name": "example-resources",
+  "private_dns_zone_name": "zone1.com"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAzurermPrivateDNSZoneVirtualNetworkLink(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package azurerm
+
+import (
+	"fmt"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func registerStorageSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"azurerm_storage_account_network_rules": schema.ImportIDFuncByAttribute("storage_account_id"),
+		"azurerm_storage_container":             importIDFuncAzurermStorageContainer,
+	})
+}
+
+// importIDFuncAzurermStorageContainer is an implementation of importIDFunc for azurerm_storage_container.
+// https://registry.terraform.io/providers/hashicorp/azurerm/latest/docs/resources/storage_container#import
+func importIDFuncAzurermStorageContainer(r schema.Resource) (string, error) {
+	// Recent versions of provider prefer storage_account_id and use the
+	// resource manager ID.
+	if accountID, ok := r["storage_account_id"].(string); ok && accountID != "" {
+		return schema.ImportIDFuncByTemplate("{storage_account_id}/blobServices/default/containers/{name}")(r)
+	}
+
+	// Otherwise, the ID is the URL of data plane.
+	// Note that only the Azure public cloud is supported.
+	if accountName, ok := r["storage_account_name"].(string); ok && accountName != "" {
+		return schema.ImportIDFuncByTemplate("https://{storage_account_name}.blob.core.windows.net/{name}")(r)
+	}
+
+	return "", fmt.Errorf("failed to detect an ID of azurerm_storage_container resource for import: %#v", r)
+}
@@ -0,0 +1,74 @@
+package azurerm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncAzurermStorageContainer(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "storage account id",
+			resource: `
+{
+  "name": "container1",
+  "storage_account_id": "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/example-resources/providers/Microsoft.Storage/storageAccounts/myaccount",
+  "storage_account_name": null
+}
+`,
+			ok:   true,
+			want: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/example-resources/providers/Microsoft.Storage/storageAccounts/myaccount/blobServices/default/containers/container1",
+		},
+		{
+			desc: "storage account name",
+			resource: `
+{
+  "name": "container1",
+  "storage_account_name": "myaccount"
+}
+`,
+			ok:   true,
+			want: "https://myaccount.blob.core.windows.net/container1",
+		},
+		{
+			desc: "unknown storage account",
+			resource: `
+{
+  "name": "container1"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncAzurermStorageContainer(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
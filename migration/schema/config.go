@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// importIDConfig is a type which corresponds to a schema file for defining
+// import IDs of arbitrary resource types without changing the code.
+// The file can be written in HCL or JSON.
+//
+//	import_id "aws_s3_bucket_acl" {
+//	  format {
+//	    condition = acl != null
+//	    template  = "{bucket},{acl}"
+//	  }
+//	  format {
+//	    template = "{bucket}"
+//	  }
+//	}
+type importIDConfig struct {
+	// A list of import ID definitions.
+	ImportIDs []importIDBlock `hcl:"import_id,block"`
+}
+
+// importIDBlock defines an import ID for a resource type.
+type importIDBlock struct {
+	// A resource type. (e.g. aws_s3_bucket_acl)
+	ResourceType string `hcl:"resource_type,label"`
+	// A list of candidate formats. The first matching one is used.
+	Formats []importIDFormatBlock `hcl:"format,block"`
+}
+
+// importIDFormatBlock defines a candidate format of import ID.
+type importIDFormatBlock struct {
+	// An optional expression to be evaluated with attributes of resource.
+	// The format is used only when it is true. If omitted, always matches.
+	Condition hcl.Expression `hcl:"condition,optional"`
+	// A template of import ID. See ImportIDFuncByTemplate for details.
+	Template string `hcl:"template"`
+}
+
+// ParseImportIDConfig parses a schema file which defines import IDs and
+// returns a map of ImportIDFunc for each resource type.
+// The filename is used for detecting a file format by its extension and
+// for error messages. If it ends with `.json`, parse it as JSON, otherwise HCL.
+func ParseImportIDConfig(src []byte, filename string) (map[string]ImportIDFunc, error) {
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = parser.ParseJSON(src, filename)
+	} else {
+		file, diags = parser.ParseHCL(src, filename)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse schema file: %s", diags)
+	}
+
+	var config importIDConfig
+	diags = gohcl.DecodeBody(file.Body, nil, &config)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode schema file: %s", diags)
+	}
+
+	ret := make(map[string]ImportIDFunc)
+	for _, b := range config.ImportIDs {
+		if _, ok := ret[b.ResourceType]; ok {
+			return nil, fmt.Errorf("duplicate import_id block for %s in schema file: %s", b.ResourceType, filename)
+		}
+		if len(b.Formats) == 0 {
+			return nil, fmt.Errorf("import_id block for %s requires at least one format block in schema file: %s", b.ResourceType, filename)
+		}
+		ret[b.ResourceType] = importIDFuncByFormats(b.ResourceType, b.Formats)
+	}
+
+	return ret, nil
+}
+
+// importIDFuncByFormats returns an ImportIDFunc which uses the first format
+// whose condition matches a given resource.
+func importIDFuncByFormats(resourceType string, formats []importIDFormatBlock) ImportIDFunc {
+	return func(r Resource) (string, error) {
+		ctx, err := newResourceEvalContext(r)
+		if err != nil {
+			return "", err
+		}
+
+		for _, format := range formats {
+			v, diags := format.Condition.Value(ctx)
+			if diags.HasErrors() {
+				return "", fmt.Errorf("failed to evaluate a condition of import ID for %s: %s", resourceType, diags)
+			}
+
+			if !v.IsNull() {
+				if v.Type() != cty.Bool {
+					return "", fmt.Errorf("a condition of import ID for %s must be bool, but got %s", resourceType, v.Type().FriendlyName())
+				}
+				if v.False() {
+					continue
+				}
+			}
+
+			return ImportIDFuncByTemplate(format.Template)(r)
+		}
+
+		return "", fmt.Errorf("failed to detect an ID of %s resource for import: %#v", resourceType, r)
+	}
+}
+
+// newResourceEvalContext returns an hcl.EvalContext which exposes attributes
+// of a given resource as variables.
+func newResourceEvalContext(r Resource) (*hcl.EvalContext, error) {
+	// The simplest way to convert an arbitrary value decoded from JSON to
+	// cty.Value is to encode it to JSON again.
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %s", err)
+	}
+
+	ty, err := ctyjson.ImpliedType(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect a type of resource: %s", err)
+	}
+
+	v, err := ctyjson.Unmarshal(buf, ty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode resource: %s", err)
+	}
+
+	vars := v.AsValueMap()
+	if vars == nil {
+		vars = map[string]cty.Value{}
+	}
+
+	return &hcl.EvalContext{Variables: vars}, nil
+}
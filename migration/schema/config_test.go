@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseImportIDConfig(t *testing.T) {
+	cases := []struct {
+		desc         string
+		src          string
+		filename     string
+		resourceType string
+		resource     string
+		ok           bool
+		want         string
+	}{
+		{
+			desc: "simple",
+			src: `
+import_id "foo_test" {
+  format {
+    template = "{foo1}/{foo2}"
+  }
+}
+`,
+			filename:     "schema.hcl",
+			resourceType: "foo_test",
+			resource: `
+{
+  "foo1": "FOO1",
+  "foo2": "FOO2",
+  "bar": null
+}
+`,
+			ok:   true,
+			want: "FOO1/FOO2",
+		},
+		{
+			desc: "condition matched",
+			src: `
+import_id "foo_test" {
+  format {
+    condition = bar != null
+    template  = "{foo1},{bar}"
+  }
+  format {
+    template = "{foo1}"
+  }
+}
+`,
+			filename:     "schema.hcl",
+			resourceType: "foo_test",
+			resource: `
+{
+  "foo1": "FOO1",
+  "bar": "BAR"
+}
+`,
+			ok:   true,
+			want: "FOO1,BAR",
+		},
+		{
+			desc: "condition not matched",
+			src: `
+import_id "foo_test" {
+  format {
+    condition = bar != null
+    template  = "{foo1},{bar}"
+  }
+  format {
+    template = "{foo1}"
+  }
+}
+`,
+			filename:     "schema.hcl",
+			resourceType: "foo_test",
+			resource: `
+{
+  "foo1": "FOO1",
+  "bar": null
+}
+`,
+			ok:   true,
+			want: "FOO1",
+		},
+		{
+			desc: "json",
+			src: `
+{
+  "import_id": {
+    "foo_test": {
+      "format": [
+        {
+          "condition": "${bar != null}",
+          "template": "{foo1},{bar}"
+        },
+        {
+          "template": "{foo1}"
+        }
+      ]
+    }
+  }
+}
+`,
+			filename:     "schema.json",
+			resourceType: "foo_test",
+			resource: `
+{
+  "foo1": "FOO1",
+  "bar": "BAR"
+}
+`,
+			ok:   true,
+			want: "FOO1,BAR",
+		},
+		{
+			desc: "no format matched",
+			src: `
+import_id "foo_test" {
+  format {
+    condition = bar != null
+    template  = "{foo1},{bar}"
+  }
+}
+`,
+			filename:     "schema.hcl",
+			resourceType: "foo_test",
+			resource: `
+{
+  "foo1": "FOO1",
+  "bar": null
+}
+`,
+			ok:   false,
+			want: "",
+		},
+		{
+			desc: "condition is not bool",
+			src: `
+import_id "foo_test" {
+  format {
+    condition = foo1
+    template  = "{foo1}"
+  }
+}
+`,
+			filename:     "schema.hcl",
+			resourceType: "foo_test",
+			resource: `
+{
+  "foo1": "FOO1"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			m, err := ParseImportIDConfig([]byte(tc.src), tc.filename)
+			if err != nil {
+				t.Fatalf("failed to parse config: %s", err)
+			}
+
+			d := NewDictionary()
+			d.RegisterImportIDFuncMap(m)
+			got, err := d.ImportID(tc.resourceType, r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseImportIDConfigInvalid(t *testing.T) {
+	cases := []struct {
+		desc     string
+		src      string
+		filename string
+	}{
+		{
+			desc: "syntax error",
+			src: `
+import_id "foo_test" {
+`,
+			filename: "schema.hcl",
+		},
+		{
+			desc: "missing template",
+			src: `
+import_id "foo_test" {
+  format {
+  }
+}
+`,
+			filename: "schema.hcl",
+		},
+		{
+			desc: "no format",
+			src: `
+import_id "foo_test" {
+}
+`,
+			filename: "schema.hcl",
+		},
+		{
+			desc: "duplicate",
+			src: `
+import_id "foo_test" {
+  format {
+    template = "{foo}"
+  }
+}
+
+import_id "foo_test" {
+  format {
+    template = "{bar}"
+  }
+}
+`,
+			filename: "schema.hcl",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ParseImportIDConfig([]byte(tc.src), tc.filename)
+			if err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+		})
+	}
+}
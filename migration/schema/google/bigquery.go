@@ -0,0 +1,10 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerBigQuerySchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_bigquery_dataset": schema.ImportIDFuncByTemplate("projects/{project}/datasets/{dataset_id}"),
+		"google_bigquery_table":   schema.ImportIDFuncByTemplate("projects/{project}/datasets/{dataset_id}/tables/{table_id}"),
+	})
+}
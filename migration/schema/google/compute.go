@@ -0,0 +1,18 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerComputeSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_compute_address":        schema.ImportIDFuncByTemplate("projects/{project}/regions/{region}/addresses/{name}"),
+		"google_compute_disk":           schema.ImportIDFuncByTemplate("projects/{project}/zones/{zone}/disks/{name}"),
+		"google_compute_firewall":       schema.ImportIDFuncByTemplate("projects/{project}/global/firewalls/{name}"),
+		"google_compute_global_address": schema.ImportIDFuncByTemplate("projects/{project}/global/addresses/{name}"),
+		"google_compute_instance":       schema.ImportIDFuncByTemplate("projects/{project}/zones/{zone}/instances/{name}"),
+		"google_compute_network":        schema.ImportIDFuncByTemplate("projects/{project}/global/networks/{name}"),
+		"google_compute_route":          schema.ImportIDFuncByTemplate("projects/{project}/global/routes/{name}"),
+		"google_compute_router":         schema.ImportIDFuncByTemplate("projects/{project}/regions/{region}/routers/{name}"),
+		"google_compute_router_nat":     schema.ImportIDFuncByTemplate("projects/{project}/regions/{region}/routers/{router}/{name}"),
+		"google_compute_subnetwork":     schema.ImportIDFuncByTemplate("projects/{project}/regions/{region}/subnetworks/{name}"),
+	})
+}
@@ -0,0 +1,10 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerContainerSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_container_cluster":   schema.ImportIDFuncByTemplate("projects/{project}/locations/{location}/clusters/{name}"),
+		"google_container_node_pool": schema.ImportIDFuncByTemplate("{project}/{location}/{cluster}/{name}"),
+	})
+}
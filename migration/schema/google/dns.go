@@ -0,0 +1,10 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerDNSSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_dns_managed_zone": schema.ImportIDFuncByTemplate("projects/{project}/managedZones/{name}"),
+		"google_dns_record_set":   schema.ImportIDFuncByTemplate("projects/{project}/managedZones/{managed_zone}/rrsets/{name}/{type}"),
+	})
+}
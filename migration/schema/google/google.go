@@ -0,0 +1,21 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+// RegisterSchema defines calculation functions of import ID for each resource type.
+// Most of resource IDs in GCP are paths like `projects/{project}/...`, which
+// are built from multiple attributes. Note that the project attribute is
+// unknown at plan time if it is omitted in the configuration and inherited
+// from the provider.
+func RegisterSchema(d *schema.Dictionary) {
+	registerBigQuerySchema(d)
+	registerComputeSchema(d)
+	registerContainerSchema(d)
+	registerDNSSchema(d)
+	registerIAMSchema(d)
+	registerKMSSchema(d)
+	registerPubSubSchema(d)
+	registerSecretManagerSchema(d)
+	registerSQLSchema(d)
+	registerStorageSchema(d)
+}
@@ -0,0 +1,106 @@
+package google
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestRegisterSchema(t *testing.T) {
+	cases := []struct {
+		desc         string
+		resourceType string
+		resource     string
+		ok           bool
+		want         string
+	}{
+		{
+			desc:         "compute subnetwork",
+			resourceType: "google_compute_subnetwork",
+			resource: `
+{
+  "project": "my-project",
+  "region": "us-central1",
+  "name": "test-subnetwork"
+}
+`,
+			ok:   true,
+			want: "projects/my-project/regions/us-central1/subnetworks/test-subnetwork",
+		},
+		{
+			desc:         "service account",
+			resourceType: "google_service_account",
+			resource: `
+{
+  "project": "my-project",
+  "account_id": "my-sa"
+}
+`,
+			ok:   true,
+			want: "projects/my-project/serviceAccounts/my-sa@my-project.iam.gserviceaccount.com",
+		},
+		{
+			desc:         "project iam member",
+			resourceType: "google_project_iam_member",
+			resource: `
+{
+  "project": "my-project",
+  "role": "roles/viewer",
+  "member": "user:jane@example.com"
+}
+`,
+			ok:   true,
+			want: "my-project roles/viewer user:jane@example.com",
+		},
+		{
+			desc:         "storage bucket iam member",
+			resourceType: "google_storage_bucket_iam_member",
+			resource: `
+{
+  "bucket": "my-bucket",
+  "role": "roles/storage.objectViewer",
+  "member": "user:jane@example.com"
+}
+`,
+			ok:   true,
+			want: "b/my-bucket roles/storage.objectViewer user:jane@example.com",
+		},
+		{
+			desc:         "project inherited from provider",
+			resourceType: "google_pubsub_topic",
+			resource: `
+{
+  "name": "my-topic"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			d := schema.NewDictionary()
+			RegisterSchema(d)
+			got, err := d.ImportID(tc.resourceType, r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerIAMSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_project_iam_binding": schema.ImportIDFuncByTemplate("{project} {role}"),
+		"google_project_iam_member":  schema.ImportIDFuncByTemplate("{project} {role} {member}"),
+		"google_project_service":     schema.ImportIDFuncByTemplate("{project}/{service}"),
+		"google_service_account":     schema.ImportIDFuncByTemplate("projects/{project}/serviceAccounts/{account_id}@{project}.iam.gserviceaccount.com"),
+	})
+}
@@ -0,0 +1,10 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerKMSSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_kms_crypto_key": schema.ImportIDFuncByTemplate("{key_ring}/cryptoKeys/{name}"),
+		"google_kms_key_ring":   schema.ImportIDFuncByTemplate("projects/{project}/locations/{location}/keyRings/{name}"),
+	})
+}
@@ -0,0 +1,10 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerPubSubSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_pubsub_subscription": schema.ImportIDFuncByTemplate("projects/{project}/subscriptions/{name}"),
+		"google_pubsub_topic":        schema.ImportIDFuncByTemplate("projects/{project}/topics/{name}"),
+	})
+}
@@ -0,0 +1,9 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerSecretManagerSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_secret_manager_secret": schema.ImportIDFuncByTemplate("projects/{project}/secrets/{secret_id}"),
+	})
+}
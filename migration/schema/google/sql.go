@@ -0,0 +1,22 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerSQLSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_sql_database":          schema.ImportIDFuncByTemplate("projects/{project}/instances/{instance}/databases/{name}"),
+		"google_sql_database_instance": schema.ImportIDFuncByTemplate("projects/{project}/instances/{name}"),
+		"google_sql_user":              importIDFuncGoogleSQLUser,
+	})
+}
+
+// importIDFuncGoogleSQLUser is an implementation of importIDFunc for google_sql_user.
+// https://registry.terraform.io/providers/hashicorp/google/latest/docs/resources/sql_user#import
+func importIDFuncGoogleSQLUser(r schema.Resource) (string, error) {
+	// The host is only available for MySQL instances.
+	if host, ok := r["host"].(string); ok && host != "" {
+		return schema.ImportIDFuncByTemplate("{project}/{instance}/{host}/{name}")(r)
+	}
+
+	return schema.ImportIDFuncByTemplate("{project}/{instance}/{name}")(r)
+}
@@ -0,0 +1,78 @@
+package google
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+func TestImportIDFuncGoogleSQLUser(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "postgres",
+			resource: `
+{
+  "project": "my-project",
+  "instance": "main-instance",
+  "name": "me",
+  "host": null
+}
+`,
+			ok:   true,
+			want: "my-project/main-instance/me",
+		},
+		{
+			desc: "mysql",
+			resource: `
+{
+  "project": "my-project",
+  "instance": "main-instance",
+  "name": "me",
+  "host": "%"
+}
+`,
+			ok:   true,
+			want: "my-project/main-instance/%/me",
+		},
+		{
+			desc: "unknown project",
+			resource: `
+{
+  "instance": "main-instance",
+  "name": "me"
+}
+`,
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r schema.Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := importIDFuncGoogleSQLUser(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package google
+
+import "github.com/minamijoyo/tfedit/migration/schema"
+
+func registerStorageSchema(d *schema.Dictionary) {
+	d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+		"google_storage_bucket":             schema.ImportIDFuncByAttribute("name"),
+		"google_storage_bucket_iam_binding": schema.ImportIDFuncByTemplate("b/{bucket} {role}"),
+		"google_storage_bucket_iam_member":  schema.ImportIDFuncByTemplate("b/{bucket} {role} {member}"),
+		"google_storage_bucket_object":      schema.ImportIDFuncByTemplate("{bucket}/{name}"),
+	})
+}
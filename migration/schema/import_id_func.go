@@ -2,6 +2,8 @@ package schema
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -44,3 +46,37 @@ func ImportIDFuncByMultiAttributes(keys []string, sep string) ImportIDFunc {
 		return strings.Join(elems, sep), nil
 	}
 }
+
+// templatePlaceholder matches an attribute name enclosed in braces in a
+// template of import ID.
+var templatePlaceholder = regexp.MustCompile(`\{([0-9A-Za-z_]+)\}`)
+
+// ImportIDFuncByTemplate is a helper method to define an ImportIDFunc which
+// expands a template by replacing each attribute name enclosed in braces with
+// its value. (e.g. `{bucket},{acl}`)
+// The value of attribute must be a string or a number.
+func ImportIDFuncByTemplate(template string) ImportIDFunc {
+	return func(r Resource) (string, error) {
+		var err error
+		id := templatePlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+			key := m[1 : len(m)-1]
+			switch v := r[key].(type) {
+			case string:
+				return v
+			case float64:
+				// Numbers in JSON are decoded as float64.
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				if err == nil {
+					err = fmt.Errorf("failed to cast %s = %#v to string as an element of import ID", key, r[key])
+				}
+				return m
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return id, nil
+	}
+}
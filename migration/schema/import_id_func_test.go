@@ -185,3 +185,93 @@ func TestImportIDFuncByMultiAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestImportIDFuncByTemplate(t *testing.T) {
+	cases := []struct {
+		desc     string
+		resource string
+		template string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "simple",
+			resource: `
+{
+  "foo1": "FOO1",
+  "foo2": "FOO2",
+  "bar": 1,
+  "baz": null
+}
+`,
+			template: "{foo1},{foo2}",
+			ok:       true,
+			want:     "FOO1,FOO2",
+		},
+		{
+			desc: "prefix and number",
+			resource: `
+{
+  "foo1": "FOO1",
+  "foo2": "FOO2",
+  "bar": 1,
+  "baz": null
+}
+`,
+			template: "foo/{foo1}:{bar}",
+			ok:       true,
+			want:     "foo/FOO1:1",
+		},
+		{
+			desc: "found null",
+			resource: `
+{
+  "foo1": "FOO1",
+  "foo2": "FOO2",
+  "bar": 1,
+  "baz": null
+}
+`,
+			template: "{foo1},{baz}",
+			ok:       false,
+			want:     "",
+		},
+		{
+			desc: "not found",
+			resource: `
+{
+  "foo1": "FOO1",
+  "foo2": "FOO2",
+  "bar": 1,
+  "baz": null
+}
+`,
+			template: "{foo1},{qux}",
+			ok:       false,
+			want:     "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var r Resource
+			if err := json.Unmarshal([]byte(tc.resource), &r); err != nil {
+				t.Fatalf("failed to unmarshal json: %s", err)
+			}
+
+			got, err := ImportIDFuncByTemplate(tc.template)(r)
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
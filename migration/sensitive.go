@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SensitiveMode is a policy for import IDs derived from sensitive values.
+// Migration files are usually committed to a git repository, so we should not
+// write sensitive values in clear text by default.
+type SensitiveMode string
+
+const (
+	// SensitiveModeError refuses to generate a migration file. It's default.
+	SensitiveModeError SensitiveMode = "error"
+	// SensitiveModeRedact replaces sensitive values with placeholders, which
+	// should be filled in by hand before apply.
+	SensitiveModeRedact SensitiveMode = "redact"
+	// SensitiveModeWarn writes sensitive values in clear text with a warning
+	// comment.
+	SensitiveModeWarn SensitiveMode = "warn"
+)
+
+// NewSensitiveMode parses a given string and returns a SensitiveMode.
+// An empty string is treated as the default.
+func NewSensitiveMode(s string) (SensitiveMode, error) {
+	switch m := SensitiveMode(s); m {
+	case "":
+		return SensitiveModeError, nil
+	case SensitiveModeError, SensitiveModeRedact, SensitiveModeWarn:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown sensitive mode: %s, valid values are error, redact or warn", s)
+	}
+}
+
+// sensitivePlaceholderPrefix is a prefix of placeholders for sensitive values.
+const sensitivePlaceholderPrefix = "<sensitive:"
+
+// sensitivePlaceholder returns a placeholder for a sensitive value at a given path.
+func sensitivePlaceholder(path string) string {
+	return sensitivePlaceholderPrefix + path + ">"
+}
+
+// sensitiveImportID is a result of checking whether an import ID is derived
+// from sensitive values.
+type sensitiveImportID struct {
+	// An import ID in which sensitive values are replaced with placeholders.
+	// It's empty if the ID cannot be calculated without sensitive values.
+	redacted string
+	// A sorted list of paths of sensitive attributes used for the import ID.
+	paths []string
+}
+
+// detectSensitiveImportID checks whether a given import ID is derived from
+// sensitive values. It calculates the import ID again with sensitive values
+// masked and compares them. It returns nil if the import ID doesn't depend on
+// sensitive values.
+func detectSensitiveImportID(importID string, calc func(mask func(path string) string) (string, error)) *sensitiveImportID {
+	masked := []string{}
+	mask := func(path string) string {
+		masked = append(masked, path)
+		return sensitivePlaceholder(path)
+	}
+
+	redacted, err := calc(mask)
+	if err == nil && redacted == importID {
+		return nil
+	}
+
+	if err != nil {
+		// If the import ID cannot be calculated without sensitive values,
+		// we cannot know which one is used. Assume all of them are used.
+		sort.Strings(masked)
+		return &sensitiveImportID{paths: masked}
+	}
+
+	paths := []string{}
+	for _, p := range masked {
+		if strings.Contains(redacted, sensitivePlaceholder(p)) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	return &sensitiveImportID{
+		redacted: redacted,
+		paths:    paths,
+	}
+}
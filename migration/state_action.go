@@ -2,8 +2,10 @@ package migration
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -31,19 +33,41 @@ func actionEscape(raw string) string {
 	// If escaping was required, enclose it in single quotes
 	// so that a shell does not interpret double quotes.
 	// It is needed to use the result as an action in a tfmigrate's migration file.
-	if raw != escaped {
+	// Some import IDs contain spaces (e.g. google_project_iam_member) or
+	// characters which have special meanings in a shell (e.g. `|` in azurerm),
+	// which also need to be quoted so that they are parsed as a single argument.
+	if raw != escaped || strings.ContainsAny(raw, " \t|&;<>()") {
 		return "'" + escaped + "'"
 	}
 	return raw
 }
 
+// commenter is an optional interface for StateAction which has a comment to
+// be written in a migration file.
+type commenter interface {
+	// Comment returns a comment for the action. It's empty if no comment.
+	Comment() string
+}
+
+// configBlocker is an optional interface for StateAction which can be
+// rendered as a Terraform configuration block for config-driven refactoring
+// instead of tfmigrate. It's unexported so that StateAction doesn't depend on
+// the tfwrite package.
+type configBlocker interface {
+	// ConfigBlock returns a configuration block equivalent to the action.
+	ConfigBlock() (tfwrite.Block, error)
+}
+
 // StateImportAction implements the StateAction interface.
 type StateImportAction struct {
 	address string
 	id      string
+	// An optional comment written above the action in a migration file.
+	comment string
 }
 
 var _ StateAction = (*StateImportAction)(nil)
+var _ configBlocker = (*StateImportAction)(nil)
 
 // NewStateImportAction returns a new instance of StateImportAction.
 func NewStateImportAction(address string, id string) StateAction {
@@ -53,9 +77,218 @@ func NewStateImportAction(address string, id string) StateAction {
 	}
 }
 
+// NewStateImportActionWithComment returns a new instance of StateImportAction
+// with a comment.
+func NewStateImportActionWithComment(address string, id string, comment string) StateAction {
+	return &StateImportAction{
+		address: address,
+		id:      id,
+		comment: comment,
+	}
+}
+
 // MigrationAction returns a string of action for state migration.
 // It escapes special characters in HCL for use as an action in a tfmigrate's
 // migration file.
 func (a *StateImportAction) MigrationAction() string {
 	return fmt.Sprintf("import %s %s", actionEscape(a.address), actionEscape(a.id))
 }
+
+// ConfigBlock returns an import block equivalent to the action.
+// It requires Terraform v1.5+.
+func (a *StateImportAction) ConfigBlock() (tfwrite.Block, error) {
+	b := tfwrite.NewEmptyImport()
+	if err := b.SetTo(a.address); err != nil {
+		return nil, err
+	}
+	b.SetID(a.id)
+	return b, nil
+}
+
+// Comment returns a comment for the action. It's empty if no comment.
+func (a *StateImportAction) Comment() string {
+	return a.comment
+}
+
+// StateMvAction implements the StateAction interface.
+type StateMvAction struct {
+	source      string
+	destination string
+}
+
+var _ StateAction = (*StateMvAction)(nil)
+var _ configBlocker = (*StateMvAction)(nil)
+
+// NewStateMvAction returns a new instance of StateMvAction.
+func NewStateMvAction(source string, destination string) StateAction {
+	return &StateMvAction{
+		source:      source,
+		destination: destination,
+	}
+}
+
+// MigrationAction returns a string of action for state migration.
+// It escapes special characters in HCL for use as an action in a tfmigrate's
+// migration file.
+func (a *StateMvAction) MigrationAction() string {
+	return fmt.Sprintf("mv %s %s", actionEscape(a.source), actionEscape(a.destination))
+}
+
+// ConfigBlock returns a moved block equivalent to the action.
+// It requires Terraform v1.1+.
+func (a *StateMvAction) ConfigBlock() (tfwrite.Block, error) {
+	b := tfwrite.NewEmptyMoved()
+	if err := b.SetFrom(a.source); err != nil {
+		return nil, err
+	}
+	if err := b.SetTo(a.destination); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// StateRmAction implements the StateAction interface.
+type StateRmAction struct {
+	addresses []string
+}
+
+var _ StateAction = (*StateRmAction)(nil)
+var _ configBlocker = (*StateRmAction)(nil)
+
+// NewStateRmAction returns a new instance of StateRmAction.
+func NewStateRmAction(addresses []string) StateAction {
+	return &StateRmAction{
+		addresses: addresses,
+	}
+}
+
+// MigrationAction returns a string of action for state migration.
+// It escapes special characters in HCL for use as an action in a tfmigrate's
+// migration file.
+func (a *StateRmAction) MigrationAction() string {
+	escaped := make([]string, len(a.addresses))
+	for i, addr := range a.addresses {
+		escaped[i] = actionEscape(addr)
+	}
+	return "rm " + strings.Join(escaped, " ")
+}
+
+// ConfigBlock returns a removed block equivalent to the action.
+// It requires Terraform v1.7+.
+// Since a removed block can contain only one address without instance keys,
+// the action must have exactly one address of a resource or module.
+func (a *StateRmAction) ConfigBlock() (tfwrite.Block, error) {
+	if len(a.addresses) != 1 {
+		return nil, fmt.Errorf("a removed block cannot contain multiple addresses: %s", strings.Join(a.addresses, " "))
+	}
+
+	address := a.addresses[0]
+	if strings.Contains(address, "[") {
+		return nil, fmt.Errorf("a removed block cannot contain instance keys: %s", address)
+	}
+
+	b := tfwrite.NewEmptyRemoved()
+	if err := b.SetFrom(address); err != nil {
+		return nil, err
+	}
+	// The rm action only removes the object from state without destroying it.
+	b.SetDestroy(false)
+	return b, nil
+}
+
+// NewStateActionFromString parses a string of action in a tfmigrate's
+// migration file and returns a new instance of StateAction.
+// The given string is expected to be already unescaped as an HCL string.
+// Only import, mv and rm are supported.
+func NewStateActionFromString(cmdStr string) (StateAction, error) {
+	args, err := splitActionArgs(cmdStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("state action is empty: %s", cmdStr)
+	}
+
+	switch args[0] {
+	case "import":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("import action is invalid: %s", cmdStr)
+		}
+		return NewStateImportAction(args[1], args[2]), nil
+
+	case "mv":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("mv action is invalid: %s", cmdStr)
+		}
+		return NewStateMvAction(args[1], args[2]), nil
+
+	case "rm":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("rm action is invalid: %s", cmdStr)
+		}
+		return NewStateRmAction(args[1:]), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported state action: %s", cmdStr)
+	}
+}
+
+// splitActionArgs splits a given string into arguments like a shell.
+// It supports single quotes, double quotes and backslash escapes, which are
+// sufficient for actions generated by actionEscape.
+func splitActionArgs(s string) ([]string, error) {
+	args := []string{}
+	var buf strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			buf.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				buf.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				buf.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, buf.String())
+				buf.Reset()
+				inArg = false
+			}
+		default:
+			buf.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in state action: %s", s)
+	}
+
+	if inArg {
+		args = append(args, buf.String())
+	}
+
+	return args, nil
+}
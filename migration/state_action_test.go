@@ -2,6 +2,8 @@ package migration
 
 import (
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestActionEscape(t *testing.T) {
@@ -20,6 +22,16 @@ func TestActionEscape(t *testing.T) {
 			raw:  `"foo"`,
 			want: `'\"foo\"'`,
 		},
+		{
+			desc: "space",
+			raw:  "my-project roles/viewer user:jane@example.com",
+			want: "'my-project roles/viewer user:jane@example.com'",
+		},
+		{
+			desc: "pipe",
+			raw:  "foo|bar",
+			want: "'foo|bar'",
+		},
 	}
 
 	for _, tc := range cases {
@@ -71,3 +83,136 @@ func TestStateImportActionMigrationAction(t *testing.T) {
 		})
 	}
 }
+
+func TestStateMvActionMigrationAction(t *testing.T) {
+	cases := []struct {
+		desc        string
+		source      string
+		destination string
+		want        string
+	}{
+		{
+			desc:        "simple",
+			source:      "foo_bar.example1",
+			destination: "foo_bar.example2",
+			want:        "mv foo_bar.example1 foo_bar.example2",
+		},
+		{
+			desc:        "for_each",
+			source:      "foo_bar.example",
+			destination: `foo_bar.example["foo"]`,
+			want:        `mv foo_bar.example 'foo_bar.example[\"foo\"]'`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			a := NewStateMvAction(tc.source, tc.destination)
+			got := a.MigrationAction()
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStateRmActionMigrationAction(t *testing.T) {
+	cases := []struct {
+		desc      string
+		addresses []string
+		want      string
+	}{
+		{
+			desc:      "simple",
+			addresses: []string{"foo_bar.example"},
+			want:      "rm foo_bar.example",
+		},
+		{
+			desc:      "multiple",
+			addresses: []string{"foo_bar.example1", `foo_bar.example2["foo"]`},
+			want:      `rm foo_bar.example1 'foo_bar.example2[\"foo\"]'`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			a := NewStateRmAction(tc.addresses)
+			got := a.MigrationAction()
+
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewStateActionFromString(t *testing.T) {
+	cases := []struct {
+		desc   string
+		cmdStr string
+		ok     bool
+		want   StateAction
+	}{
+		{
+			desc:   "import",
+			cmdStr: "import foo_bar.example test",
+			ok:     true,
+			want:   &StateImportAction{address: "foo_bar.example", id: "test"},
+		},
+		{
+			desc:   "import quoted",
+			cmdStr: `import 'foo_bar.example["foo"]' 'my-project roles/viewer user:jane@example.com'`,
+			ok:     true,
+			want:   &StateImportAction{address: `foo_bar.example["foo"]`, id: "my-project roles/viewer user:jane@example.com"},
+		},
+		{
+			desc:   "mv",
+			cmdStr: `mv foo_bar.example1 "foo_bar.example2[\"foo\"]"`,
+			ok:     true,
+			want:   &StateMvAction{source: "foo_bar.example1", destination: `foo_bar.example2["foo"]`},
+		},
+		{
+			desc:   "rm",
+			cmdStr: "rm foo_bar.example1  foo_bar.example2",
+			ok:     true,
+			want:   &StateRmAction{addresses: []string{"foo_bar.example1", "foo_bar.example2"}},
+		},
+		{
+			desc:   "invalid arguments",
+			cmdStr: "import foo_bar.example",
+			ok:     false,
+			want:   nil,
+		},
+		{
+			desc:   "unterminated quote",
+			cmdStr: "import 'foo_bar.example test",
+			ok:     false,
+			want:   nil,
+		},
+		{
+			desc:   "unsupported",
+			cmdStr: "replace-provider foo bar",
+			ok:     false,
+			want:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := NewStateActionFromString(tc.cmdStr)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+
+			opts := cmp.AllowUnexported(StateImportAction{}, StateMvAction{}, StateRmAction{})
+			if diff := cmp.Diff(got, tc.want, opts); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package migration
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/minamijoyo/tfedit/migration/schema"
 )
 
@@ -8,14 +11,17 @@ import (
 type StateImportResolver struct {
 	// A dictionary for provider schema.
 	dictionary *schema.Dictionary
+	// A policy for import IDs derived from sensitive values.
+	sensitive SensitiveMode
 }
 
 var _ Resolver = (*StateImportResolver)(nil)
 
 // NewStateImportResolver returns a new instance of StateImportResolver.
-func NewStateImportResolver(d *schema.Dictionary) Resolver {
+func NewStateImportResolver(d *schema.Dictionary, sensitive SensitiveMode) Resolver {
 	return &StateImportResolver{
 		dictionary: d,
+		sensitive:  sensitive,
 	}
 }
 
@@ -37,7 +43,10 @@ func (r *StateImportResolver) Resolve(s *Subject) (*Subject, []StateAction, erro
 				return nil, nil, err
 			}
 
-			action := NewStateImportAction(c.Address(), importID)
+			action, err := r.newImportAction(c, importID)
+			if err != nil {
+				return nil, nil, err
+			}
 			actions = append(actions, action)
 			c.MarkAsResolved()
 		}
@@ -45,3 +54,37 @@ func (r *StateImportResolver) Resolve(s *Subject) (*Subject, []StateAction, erro
 
 	return s, actions, nil
 }
+
+// newImportAction returns a new import action for a given conflict.
+// If the import ID is derived from sensitive values, it's handled according
+// to the sensitive mode.
+func (r *StateImportResolver) newImportAction(c *Conflict, importID string) (StateAction, error) {
+	detected := detectSensitiveImportID(importID, func(mask func(path string) string) (string, error) {
+		masked, err := c.ResourceAfterMasked(mask)
+		if err != nil {
+			return "", err
+		}
+		return r.dictionary.ImportID(c.ResourceType(), masked)
+	})
+	if detected == nil {
+		return NewStateImportAction(c.Address(), importID), nil
+	}
+
+	attrs := strings.Join(detected.paths, ", ")
+	switch r.sensitive {
+	case SensitiveModeRedact:
+		redacted := detected.redacted
+		if redacted == "" {
+			redacted = sensitivePlaceholder("id")
+		}
+		comment := fmt.Sprintf("TODO: The import ID is derived from sensitive values (%s). Replace placeholders before apply.", attrs)
+		return NewStateImportActionWithComment(c.Address(), redacted, comment), nil
+
+	case SensitiveModeWarn:
+		comment := fmt.Sprintf("WARNING: The import ID contains sensitive values (%s) in clear text.", attrs)
+		return NewStateImportActionWithComment(c.Address(), importID, comment), nil
+
+	default:
+		return nil, fmt.Errorf("the import ID of %s is derived from sensitive values (%s). Refused to write it to a migration file", c.Address(), attrs)
+	}
+}
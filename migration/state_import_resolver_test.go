@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/minamijoyo/tfedit/migration/schema"
 )
 
 func TestStateImportResolver(t *testing.T) {
@@ -146,7 +147,7 @@ func TestStateImportResolver(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			d := NewDefaultDictionary()
-			r := NewStateImportResolver(d)
+			r := NewStateImportResolver(d, SensitiveModeError)
 			subject, actions, err := r.Resolve(tc.s)
 			if tc.ok && err != nil {
 				t.Fatalf("unexpected err = %s", err)
@@ -167,3 +168,108 @@ func TestStateImportResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestStateImportResolverSensitive(t *testing.T) {
+	cases := []struct {
+		desc      string
+		template  string
+		sensitive SensitiveMode
+		ok        bool
+		want      []StateAction
+	}{
+		{
+			desc:      "not derived from sensitive values",
+			template:  "{name}",
+			sensitive: SensitiveModeError,
+			ok:        true,
+			want: []StateAction{
+				&StateImportAction{
+					address: "foo_test.example",
+					id:      "foo",
+				},
+			},
+		},
+		{
+			desc:      "error",
+			template:  "{name}:{password}",
+			sensitive: SensitiveModeError,
+			ok:        false,
+			want:      nil,
+		},
+		{
+			desc:      "redact",
+			template:  "{name}:{password}",
+			sensitive: SensitiveModeRedact,
+			ok:        true,
+			want: []StateAction{
+				&StateImportAction{
+					address: "foo_test.example",
+					id:      "foo:<sensitive:password>",
+					comment: "TODO: The import ID is derived from sensitive values (password). Replace placeholders before apply.",
+				},
+			},
+		},
+		{
+			desc:      "warn",
+			template:  "{name}:{password}",
+			sensitive: SensitiveModeWarn,
+			ok:        true,
+			want: []StateAction{
+				&StateImportAction{
+					address: "foo_test.example",
+					id:      "foo:secret",
+					comment: "WARNING: The import ID contains sensitive values (password) in clear text.",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &Subject{
+				conflicts: []*Conflict{
+					{
+						rc: &tfjson.ResourceChange{
+							Address: "foo_test.example",
+							Type:    "foo_test",
+							Change: &tfjson.Change{
+								Actions: tfjson.Actions{
+									"create",
+								},
+								Before: nil,
+								After: map[string]interface{}{
+									"name":     "foo",
+									"password": "secret",
+								},
+								AfterSensitive: map[string]interface{}{
+									"password": true,
+								},
+							},
+						},
+						resolved: false,
+					},
+				},
+			}
+
+			d := schema.NewDictionary()
+			d.RegisterImportIDFuncMap(map[string]schema.ImportIDFunc{
+				"foo_test": schema.ImportIDFuncByTemplate(tc.template),
+			})
+			r := NewStateImportResolver(d, tc.sensitive)
+			_, actions, err := r.Resolve(s)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", actions)
+			}
+
+			if tc.ok {
+				if diff := cmp.Diff(actions, tc.want, cmp.AllowUnexported(StateImportAction{})); diff != "" {
+					t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", actions, tc.want, diff)
+				}
+			}
+		})
+	}
+}
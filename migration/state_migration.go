@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/tfedit/tfwrite"
 )
 
 // StateMigration is a type which corresponds to tfmigrate.StateMigratorConfig
@@ -17,21 +21,50 @@ type StateMigration struct {
 	Dir string
 	// A list of state action.
 	Actions []StateAction
+	// A list of warnings written as comments at the top of a migration file.
+	Warnings []string
+	// If true, a comment of source resource is written above the first action
+	// of each group, such as split resources of an aws_s3_bucket.
+	GroupComments bool
+	// A map of source resources of grouped actions.
+	groups map[StateAction]string
+	// The configuration in the plan, which is used for rendering import
+	// blocks with for_each. It's nil if not generated from a plan.
+	config *planConfig
 }
 
-var migrationTemplate = `migration "state" "{{ .Name }}" {
+var migrationTemplate = `{{ range .Warnings }}# WARNING: {{ . }}
+{{ end }}migration "state" "{{ .Name }}" {
 {{- if ne .Dir "" }}
   dir = "{{ .Dir }}"
 {{- end }}
   actions = [
-  {{- range .Actions }}
-    "{{ .MigrationAction }}",
+  {{- range $i, $a := .Actions }}
+  {{- with header $i }}
+    # {{ . }}
+  {{- end }}
+  {{- with comment $a }}
+    # {{ . }}
+  {{- end }}
+    "{{ $a.MigrationAction }}",
   {{- end }}
   ]
 }
 `
 
-var compiledMigrationTemplate = template.Must(template.New("migration").Parse(migrationTemplate))
+var compiledMigrationTemplate = template.Must(template.New("migration").Funcs(template.FuncMap{
+	"comment": actionComment,
+	// The header is replaced for each StateMigration at rendering.
+	"header": func(int) string { return "" },
+}).Parse(migrationTemplate))
+
+// actionComment returns a comment for a given action if any.
+func actionComment(a interface{}) string {
+	if c, ok := a.(commenter); ok {
+		return c.Comment()
+	}
+	return ""
+}
 
 // NewStateMigration returns a new instance of StateMigration.
 func NewStateMigration(name string, dir string) *StateMigration {
@@ -46,6 +79,42 @@ func (m *StateMigration) AppendActions(actions ...StateAction) {
 	m.Actions = append(m.Actions, actions...)
 }
 
+// AppendGroupedActions appends a list of actions which belong to a given
+// source resource to migration. (e.g. aws_s3_bucket.example)
+func (m *StateMigration) AppendGroupedActions(group string, actions ...StateAction) {
+	if m.groups == nil {
+		m.groups = make(map[StateAction]string)
+	}
+	for _, a := range actions {
+		m.groups[a] = group
+	}
+	m.AppendActions(actions...)
+}
+
+// Group returns a source resource of a given action.
+// It's empty if the action doesn't belong to any group.
+func (m *StateMigration) Group(a StateAction) string {
+	return m.groups[a]
+}
+
+// groupHeader returns a comment written above the i-th action if it's the
+// first action of a group and GroupComments is enabled.
+func (m *StateMigration) groupHeader(i int) string {
+	if !m.GroupComments {
+		return ""
+	}
+	group := m.Group(m.Actions[i])
+	if group == "" || (i > 0 && m.Group(m.Actions[i-1]) == group) {
+		return ""
+	}
+	return "Source: " + group
+}
+
+// AppendWarnings appends a list of warnings to migration.
+func (m *StateMigration) AppendWarnings(warnings ...string) {
+	m.Warnings = append(m.Warnings, warnings...)
+}
+
 // Render converts a state migration config to bytes.
 // Return an empty slice when no action without error.
 // Encoding StateMigratorConfig directly with gohcl has some problems.
@@ -60,10 +129,86 @@ func (m *StateMigration) Render() ([]byte, error) {
 		return []byte{}, nil
 	}
 
+	t, err := compiledMigrationTemplate.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render migration file: %s", err)
+	}
+	t.Funcs(template.FuncMap{"header": m.groupHeader})
+
 	var output bytes.Buffer
-	if err := compiledMigrationTemplate.Execute(&output, m); err != nil {
+	if err := t.Execute(&output, m); err != nil {
 		return nil, fmt.Errorf("failed to render migration file: %s", err)
 	}
 
 	return output.Bytes(), nil
 }
+
+// RenderConfig converts a state migration to Terraform configuration blocks
+// such as import, moved and removed, instead of a tfmigrate's migration file.
+// Return an empty slice when no action without error.
+func (m *StateMigration) RenderConfig() ([]byte, error) {
+	// Return an empty slice when no action without error.
+	if len(m.Actions) == 0 {
+		return []byte{}, nil
+	}
+
+	items, err := groupImportActions(m.Actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render configuration: %s", err)
+	}
+	warnings := append([]string{}, m.Warnings...)
+	for _, item := range items {
+		if g, ok := item.(*importGroup); ok {
+			if err := g.resolveExpansion(m.config); err != nil {
+				warnings = append(warnings, fmt.Sprintf("The import IDs of %s are written as a literal collection because the for_each or count expression cannot be used: %s", g.address, err))
+			}
+		}
+	}
+
+	f := tfwrite.NewEmptyFile()
+	body := f.Raw().Body()
+	for _, w := range warnings {
+		body.AppendUnstructuredTokens(commentTokens("WARNING: " + w))
+	}
+
+	prevGroup := ""
+	for i, a := range items {
+		block, err := a.ConfigBlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to render configuration: %s", err)
+		}
+
+		if i > 0 || len(warnings) > 0 {
+			body.AppendNewline()
+		}
+
+		var group string
+		switch item := a.(type) {
+		case StateAction:
+			group = m.Group(item)
+		case *importGroup:
+			group = item.group(m.Group)
+		}
+		if m.GroupComments && group != "" && group != prevGroup {
+			body.AppendUnstructuredTokens(commentTokens("Source: " + group))
+		}
+		prevGroup = group
+
+		if c := actionComment(a); c != "" {
+			body.AppendUnstructuredTokens(commentTokens(c))
+		}
+		body.AppendBlock(block.Raw())
+	}
+
+	return hclwrite.Format(f.Raw().Bytes()), nil
+}
+
+// commentTokens returns tokens of a single line comment.
+func commentTokens(comment string) hclwrite.Tokens {
+	return hclwrite.Tokens{
+		{
+			Type:  hclsyntax.TokenComment,
+			Bytes: []byte("# " + comment + "\n"),
+		},
+	}
+}
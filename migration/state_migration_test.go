@@ -115,6 +115,31 @@ func TestStateMigrationRender(t *testing.T) {
     "import 'foo_bar.example[\"bar\"]' test-bar",
   ]
 }
+`,
+		},
+		{
+			desc: "comment",
+			name: "mytest",
+			dir:  "",
+			actions: []StateAction{
+				&StateImportAction{
+					address: "foo_bar.example1",
+					id:      "test1",
+				},
+				&StateImportAction{
+					address: "foo_bar.example2",
+					id:      "test2",
+					comment: "this is a comment",
+				},
+			},
+			ok: true,
+			want: `migration "state" "mytest" {
+  actions = [
+    "import foo_bar.example1 test1",
+    # this is a comment
+    "import foo_bar.example2 test2",
+  ]
+}
 `,
 		},
 	}
@@ -139,3 +164,206 @@ func TestStateMigrationRender(t *testing.T) {
 		})
 	}
 }
+
+// customAction is a StateAction which cannot be rendered as a configuration
+// block, such as an action returned by a custom resolver.
+type customAction struct{}
+
+func (a *customAction) MigrationAction() string {
+	return "xmv foo_bar.* foo_bar.new_$1"
+}
+
+func TestStateMigrationRenderConfig(t *testing.T) {
+	cases := []struct {
+		desc     string
+		actions  []StateAction
+		warnings []string
+		ok       bool
+		want     string
+	}{
+		{
+			desc: "simple",
+			actions: []StateAction{
+				NewStateImportAction("foo_bar.example1", "test1"),
+				NewStateMvAction("foo_bar.example2", "foo_bar.example3"),
+				NewStateRmAction([]string{"foo_bar.example4"}),
+			},
+			ok: true,
+			want: `import {
+  to = foo_bar.example1
+  id = "test1"
+}
+
+moved {
+  from = foo_bar.example2
+  to   = foo_bar.example3
+}
+
+removed {
+  from = foo_bar.example4
+
+  lifecycle {
+    destroy = false
+  }
+}
+`,
+		},
+		{
+			desc:    "empty",
+			actions: []StateAction{},
+			ok:      true,
+			want:    "",
+		},
+		{
+			desc: "custom action",
+			actions: []StateAction{
+				NewStateImportAction("foo_bar.example1", "test1"),
+				&customAction{},
+			},
+			ok:   false,
+			want: "",
+		},
+		{
+			desc: "for_each",
+			actions: []StateAction{
+				NewStateImportAction("foo_bar.example[\"foo\"]", "test-foo"),
+			},
+			ok: true,
+			want: `import {
+  to = foo_bar.example["foo"]
+  id = "test-foo"
+}
+`,
+		},
+		{
+			desc: "group for_each instances",
+			actions: []StateAction{
+				NewStateImportAction("foo_bar.example[\"foo\"]", "test-foo"),
+				NewStateImportAction("foo_bar.other", "test-other"),
+				NewStateImportAction("foo_bar.example[\"bar\"]", "test-bar"),
+			},
+			ok: true,
+			want: `# WARNING: The import IDs of foo_bar.example are written as a literal collection because the for_each or count expression cannot be used: the configuration is not available
+
+import {
+  for_each = {
+    bar = "test-bar"
+    foo = "test-foo"
+  }
+  to = foo_bar.example[each.key]
+  id = each.value
+}
+
+import {
+  to = foo_bar.other
+  id = "test-other"
+}
+`,
+		},
+		{
+			desc: "group count instances",
+			actions: []StateAction{
+				NewStateImportAction("module.foo.foo_bar.example[1]", "test-1"),
+				NewStateImportAction("module.foo.foo_bar.example[0]", "test-0"),
+			},
+			ok: true,
+			want: `# WARNING: The import IDs of module.foo.foo_bar.example are written as a literal collection because the for_each or count expression cannot be used: the configuration is not available
+
+import {
+  for_each = ["test-0", "test-1"]
+  to       = module.foo.foo_bar.example[each.key]
+  id       = each.value
+}
+`,
+		},
+		{
+			desc: "count indexes are not contiguous",
+			actions: []StateAction{
+				NewStateImportAction("foo_bar.example[0]", "test-0"),
+				NewStateImportAction("foo_bar.example[2]", "test-2"),
+			},
+			ok: true,
+			want: `import {
+  to = foo_bar.example[0]
+  id = "test-0"
+}
+
+import {
+  to = foo_bar.example[2]
+  id = "test-2"
+}
+`,
+		},
+		{
+			desc: "instance with a comment is not grouped",
+			actions: []StateAction{
+				NewStateImportAction("foo_bar.example[\"foo\"]", "test-foo"),
+				NewStateImportActionWithComment("foo_bar.example[\"bar\"]", "test-bar", "this is a comment"),
+				NewStateImportAction("foo_bar.example[\"baz\"]", "test-baz"),
+			},
+			ok: true,
+			want: `# WARNING: The import IDs of foo_bar.example are written as a literal collection because the for_each or count expression cannot be used: the configuration is not available
+
+import {
+  for_each = {
+    baz = "test-baz"
+    foo = "test-foo"
+  }
+  to = foo_bar.example[each.key]
+  id = each.value
+}
+
+# this is a comment
+import {
+  to = foo_bar.example["bar"]
+  id = "test-bar"
+}
+`,
+		},
+		{
+			desc: "comment and warnings",
+			actions: []StateAction{
+				NewStateImportActionWithComment("foo_bar.example1", "test1", "this is a comment"),
+			},
+			warnings: []string{"this is a warning"},
+			ok:       true,
+			want: `# WARNING: this is a warning
+
+# this is a comment
+import {
+  to = foo_bar.example1
+  id = "test1"
+}
+`,
+		},
+		{
+			desc: "removed with an instance key",
+			actions: []StateAction{
+				NewStateRmAction([]string{"foo_bar.example[0]"}),
+			},
+			ok:   false,
+			want: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			m := NewStateMigration("mytest", "")
+			m.AppendActions(tc.actions...)
+			m.AppendWarnings(tc.warnings...)
+			output, err := m.RenderConfig()
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			got := string(output)
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %s", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
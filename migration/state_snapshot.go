@@ -0,0 +1,237 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// StateSnapshot is an in-memory model of Terraform state.
+// It's used for simulating state migration actions without Terraform.
+// Only managed resources are tracked.
+type StateSnapshot struct {
+	// A map of resource instances indexed by absolute address.
+	resources map[string]*stateResource
+}
+
+// stateResource is a resource instance in StateSnapshot.
+type stateResource struct {
+	// A resource type. (e.g. aws_s3_bucket)
+	resourceType string
+	// Attributes of the resource instance.
+	values map[string]interface{}
+}
+
+// rawState is a minimal set of fields of the raw state file format (v4),
+// which can be obtained by `terraform state pull`.
+type rawState struct {
+	Version   int                `json:"version"`
+	Resources []rawStateResource `json:"resources"`
+}
+
+// rawStateResource is a resource in the raw state file format.
+type rawStateResource struct {
+	Module    string                     `json:"module,omitempty"`
+	Mode      string                     `json:"mode"`
+	Type      string                     `json:"type"`
+	Name      string                     `json:"name"`
+	Instances []rawStateResourceInstance `json:"instances"`
+}
+
+// rawStateResourceInstance is a resource instance in the raw state file format.
+type rawStateResourceInstance struct {
+	IndexKey   interface{}            `json:"index_key,omitempty"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// NewStateSnapshot parses a state file in JSON format and creates a new
+// instance of StateSnapshot. It accepts both an output of
+// `terraform show -json` and a raw state file of `terraform state pull`.
+func NewStateSnapshot(stateJSON []byte) (*StateSnapshot, error) {
+	var header struct {
+		FormatVersion string `json:"format_version"`
+		Version       int    `json:"version"`
+	}
+	if err := json.Unmarshal(stateJSON, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %s", err)
+	}
+
+	switch {
+	case header.FormatVersion != "":
+		var state tfjson.State
+		if err := json.Unmarshal(stateJSON, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %s", err)
+		}
+		return NewStateSnapshotFromState(&state), nil
+
+	case header.Version == 4:
+		var state rawState
+		if err := json.Unmarshal(stateJSON, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %s", err)
+		}
+		return newStateSnapshotFromRawState(&state)
+
+	default:
+		return nil, fmt.Errorf("unsupported state file format: format_version = %q, version = %d", header.FormatVersion, header.Version)
+	}
+}
+
+// NewStateSnapshotFromState creates a new instance of StateSnapshot from a
+// given state of terraform-json. A nil state means an empty state.
+func NewStateSnapshotFromState(state *tfjson.State) *StateSnapshot {
+	s := &StateSnapshot{
+		resources: make(map[string]*stateResource),
+	}
+
+	if state == nil || state.Values == nil {
+		return s
+	}
+
+	var walk func(m *tfjson.StateModule)
+	walk = func(m *tfjson.StateModule) {
+		if m == nil {
+			return
+		}
+		for _, r := range m.Resources {
+			if r.Mode != tfjson.ManagedResourceMode {
+				continue
+			}
+			s.resources[r.Address] = &stateResource{
+				resourceType: r.Type,
+				values:       r.AttributeValues,
+			}
+		}
+		for _, c := range m.ChildModules {
+			walk(c)
+		}
+	}
+	walk(state.Values.RootModule)
+
+	return s
+}
+
+// newStateSnapshotFromRawState creates a new instance of StateSnapshot from a
+// given raw state.
+func newStateSnapshotFromRawState(state *rawState) (*StateSnapshot, error) {
+	s := &StateSnapshot{
+		resources: make(map[string]*stateResource),
+	}
+
+	for _, r := range state.Resources {
+		if r.Mode != string(tfjson.ManagedResourceMode) {
+			continue
+		}
+
+		base := r.Type + "." + r.Name
+		if r.Module != "" {
+			base = r.Module + "." + base
+		}
+
+		for _, i := range r.Instances {
+			address := base
+			switch key := i.IndexKey.(type) {
+			case nil:
+			case float64:
+				address += fmt.Sprintf("[%d]", int(key))
+			case string:
+				address += fmt.Sprintf("[%q]", key)
+			default:
+				return nil, fmt.Errorf("failed to parse index_key of %s: %#v", base, i.IndexKey)
+			}
+
+			s.resources[address] = &stateResource{
+				resourceType: r.Type,
+				values:       i.Attributes,
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// Addresses returns a sorted list of addresses of resource instances.
+func (s *StateSnapshot) Addresses() []string {
+	ret := make([]string, 0, len(s.resources))
+	for addr := range s.resources {
+		ret = append(ret, addr)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// get returns a resource instance at a given address or nil if not found.
+func (s *StateSnapshot) get(address string) *stateResource {
+	return s.resources[address]
+}
+
+// importResource adds a resource instance at a given address.
+func (s *StateSnapshot) importResource(address string, resourceType string, values map[string]interface{}) error {
+	if _, ok := s.resources[address]; ok {
+		return fmt.Errorf("resource already managed by Terraform: %s", address)
+	}
+
+	s.resources[address] = &stateResource{
+		resourceType: resourceType,
+		values:       values,
+	}
+	return nil
+}
+
+// mv moves resource instances matched with a source address to a destination
+// address. Like `terraform state mv`, the source address can be a module or
+// a resource with multiple instances.
+func (s *StateSnapshot) mv(source string, destination string) error {
+	matched := s.match(source)
+	if len(matched) == 0 {
+		return fmt.Errorf("no matching objects found: %s", source)
+	}
+
+	moved := make(map[string]*stateResource, len(matched))
+	for _, addr := range matched {
+		newAddr := destination + strings.TrimPrefix(addr, source)
+		if _, ok := s.resources[newAddr]; ok {
+			return fmt.Errorf("cannot move %s to %s: destination already exists", addr, newAddr)
+		}
+		moved[newAddr] = s.resources[addr]
+	}
+
+	for _, addr := range matched {
+		delete(s.resources, addr)
+	}
+	for addr, r := range moved {
+		s.resources[addr] = r
+	}
+
+	return nil
+}
+
+// rm removes resource instances matched with a given address.
+func (s *StateSnapshot) rm(address string) error {
+	matched := s.match(address)
+	if len(matched) == 0 {
+		return fmt.Errorf("no matching objects found: %s", address)
+	}
+
+	for _, addr := range matched {
+		delete(s.resources, addr)
+	}
+
+	return nil
+}
+
+// match returns a sorted list of addresses of resource instances which match
+// a given address. The address matches itself, instances of the resource
+// (e.g. foo.bar[0]), and resources in the module (e.g. module.foo.bar.baz).
+func (s *StateSnapshot) match(address string) []string {
+	ret := []string{}
+	for addr := range s.resources {
+		if addr == address || strings.HasPrefix(addr, address+"[") || strings.HasPrefix(addr, address+".") {
+			ret = append(ret, addr)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
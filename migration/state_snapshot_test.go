@@ -0,0 +1,240 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewStateSnapshot(t *testing.T) {
+	cases := []struct {
+		desc  string
+		state string
+		ok    bool
+		want  []string
+	}{
+		{
+			desc: "terraform show -json",
+			state: `
+{
+  "format_version": "1.0",
+  "terraform_version": "1.1.8",
+  "values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_s3_bucket.example",
+          "mode": "managed",
+          "type": "aws_s3_bucket",
+          "name": "example",
+          "values": { "bucket": "tfedit-test" }
+        },
+        {
+          "address": "data.aws_caller_identity.current",
+          "mode": "data",
+          "type": "aws_caller_identity",
+          "name": "current",
+          "values": {}
+        }
+      ],
+      "child_modules": [
+        {
+          "address": "module.foo",
+          "resources": [
+            {
+              "address": "module.foo.aws_s3_bucket.example",
+              "mode": "managed",
+              "type": "aws_s3_bucket",
+              "name": "example",
+              "values": { "bucket": "tfedit-foo" }
+            }
+          ]
+        }
+      ]
+    }
+  }
+}
+`,
+			ok:   true,
+			want: []string{"aws_s3_bucket.example", "module.foo.aws_s3_bucket.example"},
+		},
+		{
+			desc: "terraform state pull",
+			state: `
+{
+  "version": 4,
+  "terraform_version": "1.1.8",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "example",
+      "instances": [
+        { "attributes": { "bucket": "tfedit-test" } }
+      ]
+    },
+    {
+      "module": "module.foo",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "count",
+      "instances": [
+        { "index_key": 0, "attributes": { "bucket": "tfedit-0" } },
+        { "index_key": 1, "attributes": { "bucket": "tfedit-1" } }
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "for_each",
+      "instances": [
+        { "index_key": "foo", "attributes": { "bucket": "tfedit-foo" } }
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "aws_caller_identity",
+      "name": "current",
+      "instances": [
+        { "attributes": {} }
+      ]
+    }
+  ]
+}
+`,
+			ok: true,
+			want: []string{
+				"aws_s3_bucket.example",
+				"aws_s3_bucket.for_each[\"foo\"]",
+				"module.foo.aws_s3_bucket.count[0]",
+				"module.foo.aws_s3_bucket.count[1]",
+			},
+		},
+		{
+			desc:  "unknown format",
+			state: `{ "version": 3 }`,
+			ok:    false,
+			want:  nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s, err := NewStateSnapshot([]byte(tc.state))
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", s)
+			}
+
+			if tc.ok {
+				got := s.Addresses()
+				if diff := cmp.Diff(got, tc.want); diff != "" {
+					t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestStateSnapshotMvRm(t *testing.T) {
+	cases := []struct {
+		desc    string
+		actions []StateAction
+		ok      bool
+		want    []string
+	}{
+		{
+			desc: "mv resource",
+			actions: []StateAction{
+				NewStateMvAction("foo_test.a", "foo_test.b"),
+			},
+			ok:   true,
+			want: []string{"foo_test.b", "foo_test.count[0]", "foo_test.count[1]", "module.foo.foo_test.a"},
+		},
+		{
+			desc: "mv all instances",
+			actions: []StateAction{
+				NewStateMvAction("foo_test.count", "foo_test.renamed"),
+			},
+			ok:   true,
+			want: []string{"foo_test.a", "foo_test.renamed[0]", "foo_test.renamed[1]", "module.foo.foo_test.a"},
+		},
+		{
+			desc: "mv module",
+			actions: []StateAction{
+				NewStateMvAction("module.foo", "module.bar"),
+			},
+			ok:   true,
+			want: []string{"foo_test.a", "foo_test.count[0]", "foo_test.count[1]", "module.bar.foo_test.a"},
+		},
+		{
+			desc: "mv to existing",
+			actions: []StateAction{
+				NewStateMvAction("foo_test.a", "foo_test.count[0]"),
+			},
+			ok:   false,
+			want: nil,
+		},
+		{
+			desc: "rm",
+			actions: []StateAction{
+				NewStateRmAction([]string{"foo_test.a", "foo_test.count[1]"}),
+			},
+			ok:   true,
+			want: []string{"foo_test.count[0]", "module.foo.foo_test.a"},
+		},
+		{
+			desc: "rm not found",
+			actions: []StateAction{
+				NewStateRmAction([]string{"foo_test.b"}),
+			},
+			ok:   false,
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &StateSnapshot{
+				resources: map[string]*stateResource{
+					"foo_test.a":            {resourceType: "foo_test"},
+					"foo_test.count[0]":     {resourceType: "foo_test"},
+					"foo_test.count[1]":     {resourceType: "foo_test"},
+					"module.foo.foo_test.a": {resourceType: "foo_test"},
+				},
+			}
+
+			var err error
+			for _, action := range tc.actions {
+				switch a := action.(type) {
+				case *StateMvAction:
+					err = s.mv(a.source, a.destination)
+				case *StateRmAction:
+					for _, addr := range a.addresses {
+						if err = s.rm(addr); err != nil {
+							break
+						}
+					}
+				}
+			}
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", s.Addresses())
+			}
+
+			if tc.ok {
+				got := s.Addresses()
+				if diff := cmp.Diff(got, tc.want); diff != "" {
+					t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket" "log" {
+  bucket = "tfedit-log"
+}
@@ -0,0 +1,4 @@
+resource "aws_s3_bucket" "renamed" {
+  bucket        = "tfedit-test"
+  force_destroy = true
+}
@@ -0,0 +1,12 @@
+locals {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = local.bucket
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
@@ -0,0 +1,8 @@
+locals {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = local.bucket
+  acl    = "private"
+}
@@ -0,0 +1,21 @@
+variable "bucket" {
+  type = string
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = var.bucket
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+
+resource "aws_s3_bucket_policy" "new" {
+  bucket = "tfedit-new"
+  policy = "{}"
+}
+
+module "remote" {
+  source = "example/bucket/aws"
+}
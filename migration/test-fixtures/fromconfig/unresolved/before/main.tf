@@ -0,0 +1,16 @@
+variable "bucket" {
+  type = string
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = var.bucket
+  acl    = "private"
+}
+
+resource "aws_s3_bucket" "old" {
+  bucket = "tfedit-old"
+}
+
+module "remote" {
+  source = "example/bucket/aws"
+}
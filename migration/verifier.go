@@ -0,0 +1,279 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+// VerifyResult is a result of simulating a state migration.
+type VerifyResult struct {
+	// A list of problems found in state migration actions.
+	Errors []string
+	// A list of planned changes which remain after migration.
+	Changes []*VerifyChange
+}
+
+// VerifyChange is a planned change which remains after migration.
+type VerifyChange struct {
+	// A type of action. The valid values are create, update or delete.
+	Action string
+	// An absolute address. (e.g. aws_s3_bucket_acl.example)
+	Address string
+	// A sorted list of changed attributes. It's only set for update.
+	Attributes []string
+}
+
+// IsClean returns true if no problem was found and no change remains.
+func (r *VerifyResult) IsClean() bool {
+	return len(r.Errors) == 0 && len(r.Changes) == 0
+}
+
+// Render returns a human-readable report of the result.
+func (r *VerifyResult) Render() []byte {
+	var b bytes.Buffer
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "Error: %s\n", e)
+	}
+
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(&b, "No changes remain after migration.")
+		return b.Bytes()
+	}
+
+	counts := map[string]int{}
+	for _, c := range r.Changes {
+		counts[c.Action]++
+	}
+	fmt.Fprintf(&b, "Changes remain after migration: %d to add, %d to change, %d to destroy.\n", counts["create"], counts["update"], counts["delete"])
+
+	for _, c := range r.Changes {
+		switch c.Action {
+		case "create":
+			fmt.Fprintf(&b, "  + %s\n", c.Address)
+		case "update":
+			fmt.Fprintf(&b, "  ~ %s (%s)\n", c.Address, strings.Join(c.Attributes, ", "))
+		case "delete":
+			fmt.Fprintf(&b, "  - %s\n", c.Address)
+		}
+	}
+
+	return b.Bytes()
+}
+
+// Verifier simulates a state migration against a state snapshot and reports
+// which planned changes remain. It's a fast, offline approximation of
+// running terraform plan after tfmigrate apply.
+type Verifier struct {
+	// A dictionary for provider schema.
+	dictionary *schema.Dictionary
+}
+
+// NewVerifier returns a new instance of Verifier.
+func NewVerifier(d *schema.Dictionary) *Verifier {
+	return &Verifier{
+		dictionary: d,
+	}
+}
+
+// Verify applies actions of a given migration to a given state and compares
+// the result with the configuration captured in a given plan.
+// Note that the given state is modified.
+func (v *Verifier) Verify(state *StateSnapshot, plan *Plan, migration *StateMigration) (*VerifyResult, error) {
+	config := configResourceChanges(plan)
+	result := &VerifyResult{
+		Errors:  []string{},
+		Changes: []*VerifyChange{},
+	}
+
+	for _, action := range migration.Actions {
+		if err := v.apply(state, config, action); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", action.MigrationAction(), err))
+		}
+	}
+
+	addresses := make([]string, 0, len(config))
+	for addr := range config {
+		addresses = append(addresses, addr)
+	}
+	sort.Strings(addresses)
+
+	for _, addr := range addresses {
+		r := state.get(addr)
+		if r == nil {
+			result.Changes = append(result.Changes, &VerifyChange{Action: "create", Address: addr})
+			continue
+		}
+
+		attrs, err := changedAttributes(r.values, config[addr].Change)
+		if err != nil {
+			return nil, err
+		}
+		if len(attrs) > 0 {
+			result.Changes = append(result.Changes, &VerifyChange{Action: "update", Address: addr, Attributes: attrs})
+		}
+	}
+
+	for _, addr := range state.Addresses() {
+		if _, ok := config[addr]; !ok {
+			result.Changes = append(result.Changes, &VerifyChange{Action: "delete", Address: addr})
+		}
+	}
+
+	return result, nil
+}
+
+// apply simulates a given action.
+func (v *Verifier) apply(state *StateSnapshot, config map[string]*tfjson.ResourceChange, action StateAction) error {
+	switch a := action.(type) {
+	case *StateImportAction:
+		rc, ok := config[a.address]
+		if !ok {
+			return fmt.Errorf("resource not found in the configuration: %s", a.address)
+		}
+
+		after, ok := rc.Change.After.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("failed to cast the ResourceChange.Change.After object of %s: %#v", rc.Address, rc.Change.After)
+		}
+
+		// Assume that the imported object has the expected attributes only if
+		// the import ID matches. Otherwise, we cannot know what is imported.
+		values := map[string]interface{}{}
+		var idErr error
+		if strings.Contains(a.id, sensitivePlaceholderPrefix) {
+			idErr = fmt.Errorf("the import ID contains a placeholder for a sensitive value, which must be filled in before apply")
+		} else if want, err := v.dictionary.ImportID(rc.Type, schema.Resource(after)); err != nil {
+			idErr = fmt.Errorf("failed to verify the import ID: %s", err)
+		} else if a.id != want {
+			idErr = fmt.Errorf("the import ID doesn't match the expected attributes: got = %s, want = %s", a.id, want)
+		} else {
+			values = after
+		}
+
+		if err := state.importResource(a.address, rc.Type, values); err != nil {
+			return err
+		}
+		return idErr
+
+	case *StateMvAction:
+		return state.mv(a.source, a.destination)
+
+	case *StateRmAction:
+		for _, addr := range a.addresses {
+			if err := state.rm(addr); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported state action: %T", action)
+	}
+}
+
+// configResourceChanges returns a map of planned changes of managed resources
+// which exist in the configuration, indexed by address.
+func configResourceChanges(plan *Plan) map[string]*tfjson.ResourceChange {
+	ret := make(map[string]*tfjson.ResourceChange)
+	for _, rc := range plan.ResourceChanges() {
+		if rc.Mode != tfjson.ManagedResourceMode || rc.Change == nil {
+			continue
+		}
+		// A resource planned to be deleted only doesn't exist in the configuration.
+		if rc.Change.Actions.Delete() {
+			continue
+		}
+		ret[rc.Address] = rc
+	}
+	return ret
+}
+
+// changedAttributes returns a sorted list of top-level attributes whose
+// values in a state differ from the planned values.
+// Attributes which are unknown at plan time are ignored.
+func changedAttributes(values map[string]interface{}, change *tfjson.Change) ([]string, error) {
+	after, ok := change.After.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to cast the ResourceChange.Change.After object: %#v", change.After)
+	}
+	unknown, _ := change.AfterUnknown.(map[string]interface{})
+
+	attrs := []string{}
+	for k, v := range after {
+		if containsUnknown(unknown[k]) {
+			continue
+		}
+		if !equalDriftValue(values[k], v) {
+			attrs = append(attrs, k)
+		}
+	}
+	sort.Strings(attrs)
+	return attrs, nil
+}
+
+// containsUnknown returns true if a given value of after_unknown contains
+// any unknown value.
+func containsUnknown(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case []interface{}:
+		for _, e := range t {
+			if containsUnknown(e) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, e := range t {
+			if containsUnknown(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyOption is a set of options for verifying a migration file.
+type VerifyOption struct {
+	// Dictionary is a dictionary for provider schema.
+	// If nil, the default built-in dictionary is used.
+	Dictionary *schema.Dictionary
+}
+
+// VerifyMigrationFile simulates a given migration file against a given state
+// and reports which planned changes remain.
+// If the stateJSON is nil, the prior state in the plan is used.
+// The filename is used for error messages.
+func VerifyMigrationFile(stateJSON []byte, planJSON []byte, migrationSrc []byte, filename string, o *VerifyOption) (*VerifyResult, error) {
+	plan, err := NewPlan(planJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var state *StateSnapshot
+	if stateJSON == nil {
+		state = NewStateSnapshotFromState(plan.PriorState())
+	} else {
+		state, err = NewStateSnapshot(stateJSON)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	migration, err := ParseStateMigration(migrationSrc, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary := o.Dictionary
+	if dictionary == nil {
+		dictionary = NewDefaultDictionary()
+	}
+
+	return NewVerifier(dictionary).Verify(state, plan, migration)
+}
@@ -0,0 +1,224 @@
+package migration
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVerifyMigrationFile(t *testing.T) {
+	cases := []struct {
+		desc      string
+		stateFile string
+		planFile  string
+		migration string
+		ok        bool
+		want      *VerifyResult
+	}{
+		{
+			desc:      "clean",
+			stateFile: "test-fixtures/import_simple.tfstate.json",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+			ok: true,
+			want: &VerifyResult{
+				Errors:  []string{},
+				Changes: []*VerifyChange{},
+			},
+		},
+		{
+			desc:      "prior state in plan",
+			stateFile: "",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+			ok: true,
+			want: &VerifyResult{
+				Errors:  []string{},
+				Changes: []*VerifyChange{},
+			},
+		},
+		{
+			desc:      "missing import",
+			stateFile: "test-fixtures/import_simple.tfstate.json",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = []
+}
+`,
+			ok: true,
+			want: &VerifyResult{
+				Errors: []string{},
+				Changes: []*VerifyChange{
+					{Action: "create", Address: "aws_s3_bucket_acl.example"},
+				},
+			},
+		},
+		{
+			desc:      "import ID mismatch",
+			stateFile: "test-fixtures/import_simple.tfstate.json",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test",
+  ]
+}
+`,
+			ok: true,
+			want: &VerifyResult{
+				Errors: []string{
+					"import aws_s3_bucket_acl.example tfedit-test: the import ID doesn't match the expected attributes: got = tfedit-test, want = tfedit-test,private",
+				},
+				Changes: []*VerifyChange{
+					{Action: "update", Address: "aws_s3_bucket_acl.example", Attributes: []string{"acl", "bucket"}},
+				},
+			},
+		},
+		{
+			desc:      "mv and rm",
+			stateFile: "test-fixtures/import_simple.tfstate.json",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = [
+    "mv aws_s3_bucket.example aws_s3_bucket.renamed",
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+    "rm aws_s3_bucket.foo",
+  ]
+}
+`,
+			ok: true,
+			want: &VerifyResult{
+				Errors: []string{
+					"rm aws_s3_bucket.foo: no matching objects found: aws_s3_bucket.foo",
+				},
+				Changes: []*VerifyChange{
+					{Action: "create", Address: "aws_s3_bucket.example"},
+					{Action: "delete", Address: "aws_s3_bucket.renamed"},
+				},
+			},
+		},
+		{
+			desc:      "resource not found in the configuration",
+			stateFile: "test-fixtures/import_simple.tfstate.json",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.foo tfedit-test,private",
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+			ok: true,
+			want: &VerifyResult{
+				Errors: []string{
+					"import aws_s3_bucket_acl.foo tfedit-test,private: resource not found in the configuration: aws_s3_bucket_acl.foo",
+				},
+				Changes: []*VerifyChange{},
+			},
+		},
+		{
+			desc:      "unsupported action",
+			stateFile: "test-fixtures/import_simple.tfstate.json",
+			planFile:  "test-fixtures/import_simple.tfplan.json",
+			migration: `
+migration "state" "fromplan" {
+  actions = [
+    "xmv aws_s3_bucket.* aws_s3_bucket.foo_$1",
+  ]
+}
+`,
+			ok:   false,
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var stateJSON []byte
+			if tc.stateFile != "" {
+				b, err := os.ReadFile(tc.stateFile)
+				if err != nil {
+					t.Fatalf("failed to read file: %s", err)
+				}
+				stateJSON = b
+			}
+
+			planJSON, err := os.ReadFile(tc.planFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %s", err)
+			}
+
+			got, err := VerifyMigrationFile(stateJSON, planJSON, []byte(tc.migration), "test.hcl", &VerifyOption{})
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestVerifyResultRender(t *testing.T) {
+	cases := []struct {
+		desc string
+		r    *VerifyResult
+		want string
+	}{
+		{
+			desc: "clean",
+			r: &VerifyResult{
+				Errors:  []string{},
+				Changes: []*VerifyChange{},
+			},
+			want: "No changes remain after migration.\n",
+		},
+		{
+			desc: "changes",
+			r: &VerifyResult{
+				Errors: []string{"foo"},
+				Changes: []*VerifyChange{
+					{Action: "create", Address: "foo_test.a"},
+					{Action: "update", Address: "foo_test.b", Attributes: []string{"bar", "baz"}},
+					{Action: "delete", Address: "foo_test.c"},
+				},
+			},
+			want: `Error: foo
+Changes remain after migration: 1 to add, 1 to change, 1 to destroy.
+  + foo_test.a
+  ~ foo_test.b (bar, baz)
+  - foo_test.c
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := string(tc.r.Render())
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
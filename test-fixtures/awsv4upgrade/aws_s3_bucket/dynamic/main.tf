@@ -0,0 +1,33 @@
+locals {
+  versioning = [true]
+
+  lifecycle_rules = [
+    {
+      id   = "Keep previous version 30 days, then in Glacier another 60"
+      days = 90
+    },
+  ]
+}
+
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+
+  dynamic "versioning" {
+    for_each = local.versioning
+    content {
+      enabled = true
+    }
+  }
+
+  dynamic "lifecycle_rule" {
+    for_each = local.lifecycle_rules
+    content {
+      id      = lifecycle_rule.value.id
+      enabled = true
+
+      noncurrent_version_expiration {
+        days = lifecycle_rule.value.days
+      }
+    }
+  }
+}
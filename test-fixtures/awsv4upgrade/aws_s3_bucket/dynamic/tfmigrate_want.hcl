@@ -0,0 +1,6 @@
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_lifecycle_configuration.example[0] tfedit-test",
+    "import aws_s3_bucket_versioning.example[0] tfedit-test",
+  ]
+}
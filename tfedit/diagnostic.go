@@ -0,0 +1,34 @@
+package tfedit
+
+import "fmt"
+
+// Severity is a level of a diagnostic.
+type Severity string
+
+const (
+	// SeverityError means the file or the input could not be processed.
+	SeverityError Severity = "error"
+	// SeverityWarning means the result was generated, but it needs to be
+	// reviewed by a human.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found while processing an input, which is
+// reported without aborting the whole operation.
+type Diagnostic struct {
+	// Severity is a level of the diagnostic.
+	Severity Severity
+	// Filename is a name of the file which caused the diagnostic.
+	// It's empty if the diagnostic is not specific to a file.
+	Filename string
+	// Summary is a human-readable description of the diagnostic.
+	Summary string
+}
+
+// String returns a human-readable representation of the diagnostic.
+func (d Diagnostic) String() string {
+	if d.Filename == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Summary)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Filename, d.Summary)
+}
@@ -0,0 +1,34 @@
+// Package tfedit provides a Go API for embedding tfedit in other programs.
+//
+// It exposes the same features as the tfedit command without cobra and
+// without touching the filesystem:
+//
+//   - FilterFiles and FilterFS apply a built-in filter by name to Terraform
+//     configuration files held in memory or in an fs.FS, and return the
+//     changed files with diagnostics.
+//   - AnalyzePlan generates a state migration from a Terraform JSON plan with
+//     custom resolvers and dictionaries.
+//
+// # Compatibility
+//
+// This package is the only supported entry point for library use. Within a
+// major version, exported identifiers in this package are not removed and
+// their signatures are not changed in a backward-incompatible way. New fields
+// may be added to the options and result structs, so use keyed struct
+// literals. A zero value of an option means the same default as the
+// corresponding command line flag.
+//
+// Types from the migration and migration/schema packages which appear in
+// this API, such as migration.Resolver and schema.Dictionary, are covered by
+// the same guarantee. Other exported identifiers in those packages and in
+// the cmd, filter, tfeditor and tfwrite packages are implementation details
+// of the tfedit command and may change in any release. Accordingly,
+// migration.StateAction doesn't refer to the tfwrite package. Built-in
+// actions are rendered as Terraform configuration blocks through an
+// unexported interface, so a custom action returned by a resolver is
+// rendered only with migration.GenerateModeMigration, and
+// migration.GenerateModeConfig returns an error for it.
+//
+// The contents of generated files may change between releases as filters and
+// resolvers are improved, and are not covered by the guarantee.
+package tfedit
@@ -0,0 +1,208 @@
+package tfedit
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/minamijoyo/hcledit/editor"
+	"github.com/minamijoyo/tfedit/filter"
+	"github.com/minamijoyo/tfedit/filter/awsv4upgrade"
+	"github.com/minamijoyo/tfedit/tfeditor"
+)
+
+// FileSystem is an interface that abstracts file access for filters.
+// Implement it to apply filters to files in tar archives, git objects and so
+// on. See also NewMemoryFileSystem and NewOverlayFileSystem.
+type FileSystem = tfeditor.FileSystem
+
+// NewMemoryFileSystem returns a FileSystem which holds given files in memory.
+// Written files can be retrieved with the Files method.
+func NewMemoryFileSystem(files FileSet) *tfeditor.MemoryFileSystem {
+	return tfeditor.NewMemoryFileSystem(files)
+}
+
+// NewOverlayFileSystem returns a FileSystem which reads files from a given
+// fs.FS and holds written files in memory without modifying the fs.FS.
+// Written files can be retrieved with the Files method.
+func NewOverlayFileSystem(fsys fs.FS) *tfeditor.MemoryFileSystem {
+	return tfeditor.NewOverlayFileSystem(fsys)
+}
+
+// FileSet is a set of Terraform configuration files held in memory.
+// The key is a file name, which is used for diagnostics and results, and the
+// value is the contents of the file.
+type FileSet map[string][]byte
+
+// Names returns a sorted list of file names in the set.
+func (s FileSet) Names() []string {
+	ret := make([]string, 0, len(s))
+	for name := range s {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// FilterOptions is a set of options for applying a filter.
+type FilterOptions struct {
+	// Filter is a name of a built-in filter. (e.g. awsv4upgrade)
+	// It's required.
+	Filter string
+}
+
+// FilterResult is a result of applying a filter.
+type FilterResult struct {
+	// Changed is a set of files whose contents were changed by the filter.
+	// Files which were not changed or could not be processed are not included.
+	Changed FileSet
+	// Diagnostics is a list of problems found in files, such as parse errors.
+	// Files with errors are skipped and other files are still processed.
+	// Warnings which need to be reviewed by a human, such as arguments which
+	// cannot be split, are also reported without a file name.
+	Diagnostics []Diagnostic
+}
+
+// FilterFiles applies a built-in filter to given files held in memory.
+// It returns an error only if the options are invalid. Problems in each file
+// are reported as diagnostics.
+// The given files are not modified.
+func FilterFiles(files FileSet, o *FilterOptions) (*FilterResult, error) {
+	if o == nil || o.Filter == "" {
+		return nil, fmt.Errorf("failed to filter files: a filter name is required")
+	}
+
+	f, warnings, err := newFilter(o.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &FilterResult{
+		Changed:     FileSet{},
+		Diagnostics: []Diagnostic{},
+	}
+
+	// Some filters need to know all files in a module, such as existing split
+	// resources and names used in other files, so we use a single instance of
+	// the filter for all files and prepare it before filtering. Files which
+	// cannot be parsed are ignored in Prepare and reported below.
+	if fsf, ok := f.(tfeditor.FileSetFilter); ok {
+		if err := fsf.Prepare(files); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range files.Names() {
+		if fsf, ok := f.(tfeditor.FileSetFilter); ok {
+			fsf.SetFilename(name)
+		}
+
+		output, err := editor.NewEditOperator(f).Apply(files[name], name)
+		if err != nil {
+			ret.Diagnostics = append(ret.Diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Filename: name,
+				Summary:  err.Error(),
+			})
+			continue
+		}
+
+		if !bytes.Equal(files[name], output) {
+			ret.Changed[name] = output
+		}
+	}
+
+	for _, w := range warnings() {
+		ret.Diagnostics = append(ret.Diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Summary:  w,
+		})
+	}
+
+	return ret, nil
+}
+
+// newFilter returns a new built-in filter by name, and a function which
+// returns warnings recorded while filtering.
+func newFilter(name string) (editor.Filter, func() []string, error) {
+	if name == "awsv4upgrade" {
+		recorder := awsv4upgrade.NewSplitRecorder()
+		return awsv4upgrade.NewAllFilterWithRecorder(recorder), recorder.Warnings, nil
+	}
+
+	f, err := filter.NewFilterByType(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() []string { return nil }, nil
+}
+
+// FilterFileSystem applies a built-in filter to given files in a given
+// FileSystem and writes the changed files back to it.
+// It's transactional in the sense that nothing is written unless all files
+// are filtered successfully, so it's suitable for updating all files in a
+// module at once.
+func FilterFileSystem(fsys FileSystem, filenames []string, o *FilterOptions) error {
+	if o == nil || o.Filter == "" {
+		return fmt.Errorf("failed to filter files: a filter name is required")
+	}
+
+	f, err := filter.NewFilterByType(o.Filter)
+	if err != nil {
+		return err
+	}
+
+	c := tfeditor.NewClient(&tfeditor.Option{FileSystem: fsys})
+	return c.EditFiles(filenames, f)
+}
+
+// FilterFS applies a built-in filter to Terraform configuration files (*.tf)
+// in a given file system recursively.
+// Hidden directories such as .terraform are skipped.
+// The file names in the result are slash-separated paths in the file system,
+// so the caller is responsible for writing the changed files back if needed.
+func FilterFS(fsys fs.FS, o *FilterOptions) (*FilterResult, error) {
+	files, err := readConfigFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterFiles(files, o)
+}
+
+// readConfigFiles reads Terraform configuration files in a given file system.
+func readConfigFiles(fsys fs.FS) (FileSet, error) {
+	files := FileSet{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if p != "." && strings.HasPrefix(path.Base(p), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if path.Ext(p) != ".tf" {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		files[p] = b
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files: %s", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,245 @@
+package tfedit
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterFiles(t *testing.T) {
+	cases := []struct {
+		desc  string
+		files FileSet
+		o     *FilterOptions
+		ok    bool
+		want  *FilterResult
+	}{
+		{
+			desc: "simple",
+			files: FileSet{
+				"main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`),
+				"unchanged.tf": []byte(`
+resource "aws_s3_bucket" "log" {
+  bucket = "tfedit-log"
+}
+`),
+			},
+			o:  &FilterOptions{Filter: "awsv4upgrade"},
+			ok: true,
+			want: &FilterResult{
+				Changed: FileSet{
+					"main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`),
+				},
+				Diagnostics: []Diagnostic{},
+			},
+		},
+		{
+			desc: "parse error",
+			files: FileSet{
+				"invalid.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+`),
+				"main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`),
+			},
+			o:  &FilterOptions{Filter: "awsv4upgrade"},
+			ok: true,
+			want: &FilterResult{
+				Changed: FileSet{
+					"main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`),
+				},
+				Diagnostics: []Diagnostic{
+					{
+						Severity: SeverityError,
+						Filename: "invalid.tf",
+						Summary:  "failed to parse input: invalid.tf:2,36-37: Unclosed configuration block; There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+					},
+				},
+			},
+		},
+		{
+			desc: "existing split in another file",
+			files: FileSet{
+				"main.tf": []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`),
+				"acl.tf": []byte(`
+resource "aws_s3_bucket_acl" "example" {
+  bucket = aws_s3_bucket.example.id
+  acl    = "private"
+}
+`),
+			},
+			o:  &FilterOptions{Filter: "awsv4upgrade"},
+			ok: true,
+			want: &FilterResult{
+				Changed: FileSet{},
+				Diagnostics: []Diagnostic{
+					{
+						Severity: SeverityWarning,
+						Summary:  "aws_s3_bucket.example was not split to aws_s3_bucket_acl.example because it already exists. Please merge the arguments left in aws_s3_bucket.example by yourself.",
+					},
+				},
+			},
+		},
+		{
+			desc: "name collision in another file",
+			files: FileSet{
+				"main.tf": []byte(`
+resource "aws_s3_bucket" "this" {
+  bucket = "tfedit-test"
+  policy = "{}"
+}
+`),
+				"policy.tf": []byte(`
+resource "aws_s3_bucket_policy" "this" {
+  bucket = aws_s3_bucket.other.id
+  policy = "{}"
+}
+`),
+			},
+			o:  &FilterOptions{Filter: "awsv4upgrade"},
+			ok: true,
+			want: &FilterResult{
+				Changed: FileSet{
+					"main.tf": []byte(`
+resource "aws_s3_bucket" "this" {
+  bucket = "tfedit-test"
+}
+
+resource "aws_s3_bucket_policy" "this_2" {
+  bucket = aws_s3_bucket.this.id
+  policy = "{}"
+}
+`),
+				},
+				Diagnostics: []Diagnostic{
+					{
+						Severity: SeverityWarning,
+						Summary:  "aws_s3_bucket.this was split to aws_s3_bucket_policy.this_2 because the name is already used.",
+					},
+				},
+			},
+		},
+		{
+			desc:  "unknown filter",
+			files: FileSet{},
+			o:     &FilterOptions{Filter: "foo"},
+			ok:    false,
+			want:  nil,
+		},
+		{
+			desc:  "no filter",
+			files: FileSet{},
+			o:     nil,
+			ok:    false,
+			want:  nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := FilterFiles(tc.files, tc.o)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error, got: %#v", got)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("got:\n%#v\nwant:\n%#v\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestFilterFS(t *testing.T) {
+	src := []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`)
+	fsys := fstest.MapFS{
+		"main.tf":                          {Data: src},
+		"README.md":                        {Data: []byte("# README")},
+		"modules/bucket/main.tf":           {Data: src},
+		".terraform/modules/foo/main.tf":   {Data: src},
+		"modules/bucket/.hidden/ignore.tf": {Data: src},
+	}
+
+	got, err := FilterFS(fsys, &FilterOptions{Filter: "awsv4upgrade"})
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	want := []string{"main.tf", "modules/bucket/main.tf"}
+	if diff := cmp.Diff(got.Changed.Names(), want); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", got.Changed.Names(), want, diff)
+	}
+	if len(got.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics = %#v", got.Diagnostics)
+	}
+}
+
+func TestFilterFileSystem(t *testing.T) {
+	src := []byte(`
+resource "aws_s3_bucket" "example" {
+  bucket = "tfedit-test"
+  acl    = "private"
+}
+`)
+	base := fstest.MapFS{
+		"main.tf":    {Data: src},
+		"invalid.tf": {Data: []byte(`resource "aws_s3_bucket" "example" {`)},
+	}
+
+	fsys := NewOverlayFileSystem(base)
+	if err := FilterFileSystem(fsys, []string{"main.tf", "invalid.tf"}, &FilterOptions{Filter: "awsv4upgrade"}); err == nil {
+		t.Fatalf("expected to return an error, but no error")
+	}
+	if len(fsys.Names()) != 0 {
+		t.Errorf("expected nothing to be written, but got = %#v", fsys.Names())
+	}
+
+	if err := FilterFileSystem(fsys, []string{"main.tf"}, &FilterOptions{Filter: "awsv4upgrade"}); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	want := []string{"main.tf"}
+	if diff := cmp.Diff(fsys.Names(), want); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", fsys.Names(), want, diff)
+	}
+}
@@ -0,0 +1,84 @@
+package tfedit
+
+import (
+	"github.com/minamijoyo/tfedit/migration"
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+// PlanOptions is a set of options for analyzing a plan.
+type PlanOptions struct {
+	// Dir is set to a dir attribute in a migration file.
+	Dir string
+	// Dictionary is a dictionary for provider schema.
+	// If nil, the default built-in dictionary is used. To extend the built-in
+	// one, start from migration.NewDefaultDictionary().
+	Dictionary *schema.Dictionary
+	// Resolvers is a list of additional resolvers applied in order after the
+	// built-in ones to the conflicts which remain unresolved.
+	Resolvers []migration.Resolver
+	// Sensitive is a policy for import IDs derived from sensitive values.
+	// If empty, migration.SensitiveModeError is used.
+	Sensitive migration.SensitiveMode
+	// Mode is a format of the output.
+	// If empty, migration.GenerateModeMigration is used.
+	Mode migration.GenerateMode
+	// GroupComments adds comments which group actions by the original
+	// resource, such as an aws_s3_bucket split into multiple resources.
+	GroupComments bool
+}
+
+// PlanResult is a result of analyzing a plan.
+type PlanResult struct {
+	// Migration is the contents of a generated file in the given mode.
+	// It's empty if no action is required.
+	Migration []byte
+	// Actions is a list of state migration actions in the generated file.
+	Actions []migration.StateAction
+	// Diagnostics is a list of warnings which need to be reviewed, such as
+	// drift which results in a non-empty plan after migration.
+	// They are also written as comments in the generated file.
+	Diagnostics []Diagnostic
+}
+
+// AnalyzePlan analyzes a given Terraform JSON plan and generates a state
+// migration so that the plan results in no changes.
+// It returns an error if the plan cannot be migrated, such as an unsupported
+// format version or an import ID which cannot be derived.
+func AnalyzePlan(planJSON []byte, o *PlanOptions) (*PlanResult, error) {
+	if o == nil {
+		o = &PlanOptions{}
+	}
+
+	option := &migration.GenerateOption{
+		Dir:           o.Dir,
+		Dictionary:    o.Dictionary,
+		Sensitive:     o.Sensitive,
+		Mode:          o.Mode,
+		GroupComments: o.GroupComments,
+		Resolvers:     o.Resolvers,
+	}
+
+	m, err := migration.AnalyzePlan(planJSON, option)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := migration.RenderMigration(m, o.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &PlanResult{
+		Migration:   output,
+		Actions:     m.Actions,
+		Diagnostics: []Diagnostic{},
+	}
+	for _, w := range m.Warnings {
+		ret.Diagnostics = append(ret.Diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Summary:  w,
+		})
+	}
+
+	return ret, nil
+}
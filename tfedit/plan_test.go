@@ -0,0 +1,139 @@
+package tfedit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/minamijoyo/tfedit/migration"
+	"github.com/minamijoyo/tfedit/migration/schema"
+)
+
+// mockResolver is a Resolver implementation which returns given actions.
+type mockResolver struct {
+	actions []migration.StateAction
+}
+
+var _ migration.Resolver = (*mockResolver)(nil)
+
+// Resolve returns a given subject as it is with predefined actions.
+func (r *mockResolver) Resolve(s *migration.Subject) (*migration.Subject, []migration.StateAction, error) {
+	return s, r.actions, nil
+}
+
+func TestAnalyzePlan(t *testing.T) {
+	cases := []struct {
+		desc     string
+		planFile string
+		o        *PlanOptions
+		ok       bool
+		want     string
+		diags    []Diagnostic
+	}{
+		{
+			desc:     "default",
+			planFile: "../migration/test-fixtures/import_simple.tfplan.json",
+			o:        nil,
+			ok:       true,
+			want: `migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+			diags: []Diagnostic{},
+		},
+		{
+			desc:     "custom resolvers and dictionary",
+			planFile: "../migration/test-fixtures/import_simple.tfplan.json",
+			o: &PlanOptions{
+				Dir: "tfmigrate",
+				Dictionary: func() *schema.Dictionary {
+					d := migration.NewDefaultDictionary()
+					d.RegisterImportIDFunc("aws_s3_bucket_acl", func(r schema.Resource) (string, error) {
+						return r["bucket"].(string), nil
+					})
+					return d
+				}(),
+				Resolvers: []migration.Resolver{
+					&mockResolver{
+						actions: []migration.StateAction{
+							migration.NewStateMvAction("aws_s3_bucket.foo", "aws_s3_bucket.bar"),
+						},
+					},
+				},
+				Mode: migration.GenerateModeConfig,
+			},
+			ok: true,
+			want: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test"
+}
+
+moved {
+  from = aws_s3_bucket.foo
+  to   = aws_s3_bucket.bar
+}
+`,
+			diags: []Diagnostic{},
+		},
+		{
+			desc:     "warnings",
+			planFile: "../migration/test-fixtures/newer_fields.tfplan.json",
+			o:        &PlanOptions{},
+			ok:       true,
+			want: `# WARNING: The check for check.health has a status of fail in the plan.
+# WARNING: The import of aws_s3_bucket_acl.example will produce a non-empty plan because acl of aws_s3_bucket.example was changed outside of Terraform. The changed value is also referred to by other planned changes.
+migration "state" "fromplan" {
+  actions = [
+    "import aws_s3_bucket_acl.example tfedit-test,private",
+  ]
+}
+`,
+			diags: []Diagnostic{
+				{
+					Severity: SeverityWarning,
+					Summary:  "The check for check.health has a status of fail in the plan.",
+				},
+				{
+					Severity: SeverityWarning,
+					Summary:  "The import of aws_s3_bucket_acl.example will produce a non-empty plan because acl of aws_s3_bucket.example was changed outside of Terraform. The changed value is also referred to by other planned changes.",
+				},
+			},
+		},
+		{
+			desc:     "invalid",
+			planFile: "../migration/test-fixtures/invalid.tfplan.json",
+			o:        nil,
+			ok:       false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			planJSON, err := os.ReadFile(tc.planFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %s", err)
+			}
+
+			got, err := AnalyzePlan(planJSON, tc.o)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error, got: %#v", got)
+				}
+				return
+			}
+
+			if diff := cmp.Diff(string(got.Migration), tc.want); diff != "" {
+				t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", string(got.Migration), tc.want, diff)
+			}
+			if diff := cmp.Diff(got.Diagnostics, tc.diags); diff != "" {
+				t.Errorf("got = %#v, but want = %#v, diff:\n%s", got.Diagnostics, tc.diags, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package tfeditor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix is a suffix of backup files of original files.
+const BackupSuffix = ".tfedit.bak"
+
+// DefaultBackupManifest is a default path to a manifest of backups, which
+// records files updated in the last run.
+const DefaultBackupManifest = ".tfedit.bak.json"
+
+// backupManifest is a list of files updated in the last run.
+type backupManifest struct {
+	Files []backupEntry `json:"files"`
+}
+
+// backupEntry is a file updated in the last run.
+type backupEntry struct {
+	// An absolute path to the updated file.
+	Path string `json:"path"`
+	// An absolute path to the backup file.
+	// It's empty if the file did not exist before the run.
+	Backup string `json:"backup,omitempty"`
+}
+
+// writeBackups writes a copy of given original files and a manifest which
+// records them. The manifest of the previous run is overwritten.
+func writeBackups(manifest string, originals []*osOriginalFile) error {
+	m := backupManifest{
+		Files: []backupEntry{},
+	}
+
+	for _, o := range originals {
+		path, err := filepath.Abs(o.name)
+		if err != nil {
+			return fmt.Errorf("failed to write backup: %s", err)
+		}
+
+		entry := backupEntry{Path: path}
+		if o.data != nil {
+			entry.Backup = path + BackupSuffix
+			if err := os.WriteFile(entry.Backup, o.data, o.perm); err != nil {
+				return fmt.Errorf("failed to write backup: %s", err)
+			}
+		}
+		m.Files = append(m.Files, entry)
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write backup manifest: %s", err)
+	}
+
+	tmp, err := writeTempFile(manifest, append(b, '\n'), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write backup manifest: %s", err)
+	}
+	if err := os.Rename(tmp, manifest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write backup manifest: %s", err)
+	}
+
+	return nil
+}
+
+// Restore rolls back files updated in the last run recorded in a given
+// manifest from their backups. Files created in the run are removed.
+// After restoring, the backups and the manifest are removed.
+// It returns a list of restored files.
+func Restore(manifest string) ([]string, error) {
+	b, err := os.ReadFile(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %s", err)
+	}
+
+	var m backupManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse backup manifest: %s", err)
+	}
+
+	// Read all backups before updating any file, so that we don't leave a
+	// partially restored state due to a missing backup.
+	files := map[string][]byte{}
+	removed := []string{}
+	for _, e := range m.Files {
+		if e.Backup == "" {
+			removed = append(removed, e.Path)
+			continue
+		}
+		data, err := os.ReadFile(e.Backup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %s", err)
+		}
+		files[e.Path] = data
+	}
+
+	if err := (&OSFileSystem{}).WriteFiles(files); err != nil {
+		return nil, err
+	}
+	for _, path := range removed {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove file: %s", err)
+		}
+	}
+
+	restored := []string{}
+	for _, e := range m.Files {
+		restored = append(restored, e.Path)
+		if e.Backup != "" {
+			if err := os.Remove(e.Backup); err != nil {
+				return nil, fmt.Errorf("failed to remove backup: %s", err)
+			}
+		}
+	}
+
+	if err := os.Remove(manifest); err != nil {
+		return nil, fmt.Errorf("failed to remove backup manifest: %s", err)
+	}
+
+	return restored, nil
+}
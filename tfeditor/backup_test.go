@@ -0,0 +1,75 @@
+package tfeditor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.tf")
+	created := filepath.Join(dir, "created.tf")
+	untouched := filepath.Join(dir, "untouched.tf")
+	manifest := filepath.Join(dir, DefaultBackupManifest)
+	for name, data := range map[string]string{existing: "foo", untouched: "qux"} {
+		if err := os.WriteFile(name, []byte(data), 0600); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+
+	fs := NewOSFileSystemWithBackup(manifest).(BatchWriter)
+	err := fs.WriteFiles(map[string][]byte{
+		existing: []byte("bar"),
+		created:  []byte("baz"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	backup, err := os.ReadFile(existing + BackupSuffix)
+	if err != nil {
+		t.Fatalf("failed to read backup: %s", err)
+	}
+	if string(backup) != "foo" {
+		t.Errorf("got backup = %s, but want = foo", string(backup))
+	}
+
+	restored, err := Restore(manifest)
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	sort.Strings(restored)
+	want := []string{created, existing}
+	if diff := cmp.Diff(restored, want); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", restored, want, diff)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %s", err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	wantNames := []string{"existing.tf", "untouched.tf"}
+	if diff := cmp.Diff(names, wantNames); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", names, wantNames, diff)
+	}
+
+	got, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+	if string(got) != "foo" {
+		t.Errorf("got = %s, but want = foo", string(got))
+	}
+
+	if _, err := Restore(manifest); err == nil {
+		t.Errorf("expected to return an error for a missing manifest, but no error")
+	}
+}
@@ -0,0 +1,162 @@
+package tfeditor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/minamijoyo/hcledit/editor"
+)
+
+// Client is an interface for applying filters to files via a FileSystem.
+// It's a variant of the editor.Client in hcledit whose file access can be
+// replaced.
+type Client interface {
+	// Edit reads a HCL file and applies a given filter.
+	// If filename is `-`, reads the input from stdin.
+	// If update is true, the output is written to the input file, else to stdout.
+	Edit(filename string, update bool, filter editor.Filter) error
+
+	// EditFiles reads HCL files and applies a given filter to each of them.
+	// It's transactional in the sense that changed files are written only
+	// after all files are filtered successfully. If any file fails, nothing
+	// is written.
+	EditFiles(filenames []string, filter editor.Filter) error
+}
+
+// Option is a set of options for Client.
+type Option struct {
+	// InStream is the stdin stream.
+	InStream io.Reader
+	// OutStream is the stdout stream.
+	OutStream io.Writer
+	// ErrStream is the stderr stream.
+	ErrStream io.Writer
+	// FileSystem is used for reading and writing files.
+	// If nil, the OS filesystem is used.
+	FileSystem FileSystem
+}
+
+// client implements the Client interface.
+type client struct {
+	o  *Option
+	fs FileSystem
+}
+
+var _ Client = (*client)(nil)
+
+// NewClient creates a new instance of Client.
+func NewClient(o *Option) Client {
+	fs := o.FileSystem
+	if fs == nil {
+		fs = NewOSFileSystem()
+	}
+
+	return &client{
+		o:  o,
+		fs: fs,
+	}
+}
+
+// Edit reads a HCL file and applies a given filter.
+// If filename is `-`, reads the input from stdin.
+// If update is true, the output is written to the input file, else to stdout.
+func (c *client) Edit(filename string, update bool, filter editor.Filter) error {
+	if filename == "-" {
+		if f, ok := filter.(FileSetFilter); ok {
+			f.SetFilename(filename)
+		}
+		return editor.EditStream(c.o.InStream, c.o.OutStream, filename, filter)
+	}
+
+	input, err := c.fs.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := prepare(filter, map[string][]byte{filename: input}); err != nil {
+		return err
+	}
+
+	output, err := apply(filename, input, filter)
+	if err != nil {
+		return err
+	}
+
+	if !update {
+		if _, err := c.o.OutStream.Write(output); err != nil {
+			return fmt.Errorf("failed to write output: %s", err)
+		}
+		return nil
+	}
+
+	// Skip updating the file if its contents has no change.
+	if bytes.Equal(input, output) {
+		return nil
+	}
+
+	return c.write(map[string][]byte{filename: output})
+}
+
+// EditFiles reads HCL files and applies a given filter to each of them.
+// It's transactional in the sense that changed files are written only after
+// all files are filtered successfully. If any file fails, nothing is written.
+// If the FileSystem implements BatchWriter, the changed files are written at
+// once, otherwise a failure of writing itself may result in a partial update.
+func (c *client) EditFiles(filenames []string, filter editor.Filter) error {
+	inputs := map[string][]byte{}
+	for _, filename := range filenames {
+		input, err := c.fs.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		inputs[filename] = input
+	}
+
+	if err := prepare(filter, inputs); err != nil {
+		return err
+	}
+
+	outputs := map[string][]byte{}
+	for _, filename := range filenames {
+		input := inputs[filename]
+		output, err := apply(filename, input, filter)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(input, output) {
+			continue
+		}
+		outputs[filename] = output
+	}
+
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	return c.write(outputs)
+}
+
+// write writes given contents indexed by file names.
+func (c *client) write(outputs map[string][]byte) error {
+	return writeFiles(c.fs, outputs)
+}
+
+// prepare calls the Prepare method of a given filter with given files if the
+// filter implements the FileSetFilter.
+func prepare(filter editor.Filter, files map[string][]byte) error {
+	f, ok := filter.(FileSetFilter)
+	if !ok {
+		return nil
+	}
+	return f.Prepare(files)
+}
+
+// apply applies a given filter to given contents of a file.
+func apply(filename string, input []byte, filter editor.Filter) ([]byte, error) {
+	if f, ok := filter.(FileSetFilter); ok {
+		f.SetFilename(filename)
+	}
+
+	return editor.NewEditOperator(filter).Apply(input, filename)
+}
@@ -0,0 +1,216 @@
+package tfeditor
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/minamijoyo/hcledit/editor"
+)
+
+func TestClientEdit(t *testing.T) {
+	src := `resource "foo" "bar" {
+  baz = "old"
+}
+`
+	updated := `resource "foo" "bar" {
+  baz = "new"
+}
+`
+
+	cases := []struct {
+		desc     string
+		filename string
+		update   bool
+		stdin    string
+		ok       bool
+		stdout   string
+		files    map[string][]byte
+	}{
+		{
+			desc:     "stdin",
+			filename: "-",
+			update:   false,
+			stdin:    src,
+			ok:       true,
+			stdout:   updated,
+			files:    map[string][]byte{"main.tf": []byte(src)},
+		},
+		{
+			desc:     "file to stdout",
+			filename: "main.tf",
+			update:   false,
+			ok:       true,
+			stdout:   updated,
+			files:    map[string][]byte{"main.tf": []byte(src)},
+		},
+		{
+			desc:     "update file",
+			filename: "main.tf",
+			update:   true,
+			ok:       true,
+			stdout:   "",
+			files:    map[string][]byte{"main.tf": []byte(updated)},
+		},
+		{
+			desc:     "not found",
+			filename: "not_found.tf",
+			update:   true,
+			ok:       false,
+			stdout:   "",
+			files:    map[string][]byte{"main.tf": []byte(src)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			fs := NewMemoryFileSystem(map[string][]byte{"main.tf": []byte(src)})
+			stdout := new(bytes.Buffer)
+			c := NewClient(&Option{
+				InStream:   bytes.NewBufferString(tc.stdin),
+				OutStream:  stdout,
+				ErrStream:  new(bytes.Buffer),
+				FileSystem: fs,
+			})
+
+			err := c.Edit(tc.filename, tc.update, editor.NewAttributeSetFilter("resource.foo.bar.baz", `"new"`))
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error")
+			}
+
+			if stdout.String() != tc.stdout {
+				t.Errorf("got stdout:\n%s\nwant:\n%s", stdout.String(), tc.stdout)
+			}
+
+			if diff := cmp.Diff(fs.Files(), tc.files); diff != "" {
+				t.Errorf("got = %#v, but want = %#v, diff:\n%s", fs.Files(), tc.files, diff)
+			}
+		})
+	}
+}
+
+func TestClientEditFiles(t *testing.T) {
+	src := `resource "foo" "bar" {
+  baz = "old"
+}
+`
+	updated := `resource "foo" "bar" {
+  baz = "new"
+}
+`
+	unchanged := `resource "foo" "qux" {
+  baz = "old"
+}
+`
+	invalid := `resource "foo" "bar" {
+`
+
+	cases := []struct {
+		desc      string
+		files     map[string][]byte
+		filenames []string
+		ok        bool
+		want      map[string][]byte
+	}{
+		{
+			desc: "all success",
+			files: map[string][]byte{
+				"main.tf":      []byte(src),
+				"unchanged.tf": []byte(unchanged),
+			},
+			filenames: []string{"main.tf", "unchanged.tf"},
+			ok:        true,
+			want: map[string][]byte{
+				"main.tf":      []byte(updated),
+				"unchanged.tf": []byte(unchanged),
+			},
+		},
+		{
+			desc: "nothing is written if any file fails",
+			files: map[string][]byte{
+				"main.tf":    []byte(src),
+				"invalid.tf": []byte(invalid),
+			},
+			filenames: []string{"main.tf", "invalid.tf"},
+			ok:        false,
+			want: map[string][]byte{
+				"main.tf":    []byte(src),
+				"invalid.tf": []byte(invalid),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			fs := NewMemoryFileSystem(tc.files)
+			c := NewClient(&Option{FileSystem: fs})
+
+			err := c.EditFiles(tc.filenames, editor.NewAttributeSetFilter("resource.foo.bar.baz", `"new"`))
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error")
+			}
+
+			if diff := cmp.Diff(fs.Files(), tc.want); diff != "" {
+				t.Errorf("got = %#v, but want = %#v, diff:\n%s", fs.Files(), tc.want, diff)
+			}
+		})
+	}
+}
+
+// testFileSetFilter is a FileSetFilter for testing, which records calls.
+type testFileSetFilter struct {
+	filter    editor.Filter
+	prepared  []string
+	filenames []string
+}
+
+func (f *testFileSetFilter) Filter(inFile *hclwrite.File) (*hclwrite.File, error) {
+	return f.filter.Filter(inFile)
+}
+
+func (f *testFileSetFilter) Prepare(files map[string][]byte) error {
+	for filename := range files {
+		f.prepared = append(f.prepared, filename)
+	}
+	sort.Strings(f.prepared)
+	return nil
+}
+
+func (f *testFileSetFilter) SetFilename(filename string) {
+	f.filenames = append(f.filenames, filename)
+}
+
+func TestClientEditFilesWithFileSetFilter(t *testing.T) {
+	src := `resource "foo" "bar" {
+  baz = "old"
+}
+`
+	fs := NewMemoryFileSystem(map[string][]byte{
+		"a.tf": []byte(src),
+		"b.tf": []byte(src),
+	})
+	c := NewClient(&Option{FileSystem: fs})
+	filter := &testFileSetFilter{filter: editor.NewAttributeSetFilter("resource.foo.bar.baz", `"new"`)}
+
+	if err := c.EditFiles([]string{"a.tf", "b.tf"}, filter); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	want := []string{"a.tf", "b.tf"}
+	if diff := cmp.Diff(filter.prepared, want); diff != "" {
+		t.Errorf("got prepared = %#v, but want = %#v, diff:\n%s", filter.prepared, want, diff)
+	}
+	if diff := cmp.Diff(filter.filenames, want); diff != "" {
+		t.Errorf("got filenames = %#v, but want = %#v, diff:\n%s", filter.filenames, want, diff)
+	}
+}
@@ -0,0 +1,385 @@
+package tfeditor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileSystem is an interface that abstracts file access for filters, so that
+// we can apply filters to files not only on the OS filesystem, but also in
+// memory, tar archives or git objects.
+type FileSystem interface {
+	// ReadFile reads a file with a given name and returns its contents.
+	ReadFile(name string) ([]byte, error)
+	// WriteFile writes given contents to a file with a given name.
+	// If the file already exists, its permissions are preserved.
+	WriteFile(name string, data []byte) error
+}
+
+// BatchWriter is an optional interface for FileSystem which writes multiple
+// files at once. If a FileSystem implements it, the Client uses it for
+// writing all changed files in a run, so that the implementation can make
+// the writes atomic.
+type BatchWriter interface {
+	// WriteFiles writes given contents indexed by file names.
+	WriteFiles(files map[string][]byte) error
+}
+
+// writeFiles writes given contents indexed by file names to a given
+// FileSystem. If the FileSystem implements BatchWriter, they are written at
+// once, otherwise they are written one by one in order of names.
+func writeFiles(fsys FileSystem, files map[string][]byte) error {
+	if w, ok := fsys.(BatchWriter); ok {
+		return w.WriteFiles(files)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := fsys.WriteFile(name, files[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OSFileSystem is a FileSystem implementation for the OS filesystem.
+// Writes are atomic across files in a batch. Each file is written to a
+// temporary file in the same directory first, and all of them are renamed
+// only after all temporary files are written successfully.
+type OSFileSystem struct {
+	// A path to a backup manifest. If not empty, a copy of original files is
+	// kept before updating them. See also Restore.
+	backupManifest string
+}
+
+var _ FileSystem = (*OSFileSystem)(nil)
+var _ BatchWriter = (*OSFileSystem)(nil)
+
+// NewOSFileSystem returns a new instance of OSFileSystem.
+func NewOSFileSystem() FileSystem {
+	return &OSFileSystem{}
+}
+
+// NewOSFileSystemWithBackup returns a new instance of OSFileSystem which
+// keeps a copy of original files with a BackupSuffix before updating them.
+// The backups of the last batch are recorded in a given manifest file, which
+// can be rolled back with Restore.
+func NewOSFileSystemWithBackup(manifest string) FileSystem {
+	return &OSFileSystem{
+		backupManifest: manifest,
+	}
+}
+
+// ReadFile reads a file with a given name and returns its contents.
+func (s *OSFileSystem) ReadFile(name string) ([]byte, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %s", err)
+	}
+	return b, nil
+}
+
+// WriteFile writes given contents to a file with a given name.
+// If the file already exists, its permissions are preserved.
+func (s *OSFileSystem) WriteFile(name string, data []byte) error {
+	return s.WriteFiles(map[string][]byte{name: data})
+}
+
+// WriteFiles writes given contents indexed by file names atomically.
+// If any file fails, files already renamed are rolled back and temporary
+// files are removed, so that files are either all updated or not at all.
+// If the file already exists, its permissions are preserved.
+func (s *OSFileSystem) WriteFiles(files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	originals := make([]*osOriginalFile, 0, len(names))
+	for _, name := range names {
+		o, err := readOSOriginalFile(name)
+		if err != nil {
+			return err
+		}
+		originals = append(originals, o)
+	}
+
+	temps := map[string]string{}
+	cleanup := func() {
+		for _, tmp := range temps {
+			_ = os.Remove(tmp)
+		}
+	}
+	for _, o := range originals {
+		tmp, err := writeTempFile(o.name, files[o.name], o.perm)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		temps[o.name] = tmp
+	}
+
+	if s.backupManifest != "" {
+		if err := writeBackups(s.backupManifest, originals); err != nil {
+			cleanup()
+			return err
+		}
+	}
+
+	for i, o := range originals {
+		if err := os.Rename(temps[o.name], o.name); err != nil {
+			cleanup()
+			// Roll back files already renamed as much as possible.
+			for _, renamed := range originals[:i] {
+				_ = renamed.restore()
+			}
+			return fmt.Errorf("failed to write file: %s", err)
+		}
+		delete(temps, o.name)
+	}
+
+	return nil
+}
+
+// osOriginalFile is a snapshot of a file before updating.
+type osOriginalFile struct {
+	// A file name.
+	name string
+	// Contents of the file. It's nil if the file does not exist.
+	data []byte
+	// Permissions of the file.
+	perm os.FileMode
+}
+
+// readOSOriginalFile reads a snapshot of a given file.
+func readOSOriginalFile(name string) (*osOriginalFile, error) {
+	o := &osOriginalFile{
+		name: name,
+		perm: os.FileMode(0644),
+	}
+
+	fi, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %s", err)
+	}
+
+	o.perm = fi.Mode().Perm()
+	o.data, err = os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %s", err)
+	}
+	return o, nil
+}
+
+// restore restores a file from the snapshot.
+func (o *osOriginalFile) restore() error {
+	if o.data == nil {
+		return os.Remove(o.name)
+	}
+	return os.WriteFile(o.name, o.data, o.perm)
+}
+
+// writeTempFile writes given contents to a temporary file in the same
+// directory as a given file, so that it can be renamed atomically.
+// It returns a path to the temporary file.
+func writeTempFile(name string, data []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tfedit-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write file: %s", err)
+	}
+	tmp := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write file: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write file: %s", err)
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write file: %s", err)
+	}
+
+	return tmp, nil
+}
+
+// BufferedFileSystem is a FileSystem implementation which holds written files
+// in memory until Flush is called, and reads the other files from a base
+// FileSystem. It allows us to write files generated after filtering, such as
+// a migration file, in the same batch as the filtered files.
+type BufferedFileSystem struct {
+	// A FileSystem to which buffered files are flushed.
+	base FileSystem
+	// A buffer of written files.
+	buf *MemoryFileSystem
+}
+
+var _ FileSystem = (*BufferedFileSystem)(nil)
+var _ BatchWriter = (*BufferedFileSystem)(nil)
+
+// NewBufferedFileSystem returns a new instance of BufferedFileSystem which
+// flushes written files to a given FileSystem.
+func NewBufferedFileSystem(base FileSystem) *BufferedFileSystem {
+	return &BufferedFileSystem{
+		base: base,
+		buf:  NewMemoryFileSystem(nil),
+	}
+}
+
+// ReadFile reads a file with a given name and returns its contents.
+// If the file has been written but not flushed yet, the buffered contents are
+// returned.
+func (s *BufferedFileSystem) ReadFile(name string) ([]byte, error) {
+	if data, ok := s.buf.Files()[path.Clean(name)]; ok {
+		return data, nil
+	}
+	return s.base.ReadFile(name)
+}
+
+// WriteFile writes given contents to a file with a given name in the buffer.
+func (s *BufferedFileSystem) WriteFile(name string, data []byte) error {
+	return s.buf.WriteFile(name, data)
+}
+
+// WriteFiles writes given contents indexed by file names in the buffer.
+func (s *BufferedFileSystem) WriteFiles(files map[string][]byte) error {
+	return s.buf.WriteFiles(files)
+}
+
+// Flush writes all buffered files to the base FileSystem and clears the
+// buffer. If the base FileSystem implements BatchWriter, they are written at
+// once.
+func (s *BufferedFileSystem) Flush() error {
+	files := s.buf.Files()
+	if len(files) == 0 {
+		return nil
+	}
+
+	if err := writeFiles(s.base, files); err != nil {
+		return err
+	}
+
+	s.buf = NewMemoryFileSystem(nil)
+	return nil
+}
+
+// MemoryFileSystem is a FileSystem implementation which holds files in
+// memory. If a base fs.FS is given, files not written yet are read from it,
+// which allows us to apply filters to read-only sources such as tar archives
+// or git objects without modifying them.
+type MemoryFileSystem struct {
+	// A read-only file system for files not written yet. It may be nil.
+	base fs.FS
+	// A map of contents indexed by cleaned file names.
+	files map[string][]byte
+	// A mutex for files.
+	mu sync.Mutex
+}
+
+var _ FileSystem = (*MemoryFileSystem)(nil)
+var _ BatchWriter = (*MemoryFileSystem)(nil)
+
+// NewMemoryFileSystem returns a new instance of MemoryFileSystem with given
+// files indexed by name. The given map is copied.
+func NewMemoryFileSystem(files map[string][]byte) *MemoryFileSystem {
+	s := &MemoryFileSystem{
+		files: make(map[string][]byte, len(files)),
+	}
+	for name, data := range files {
+		s.files[path.Clean(name)] = data
+	}
+	return s
+}
+
+// NewOverlayFileSystem returns a new instance of MemoryFileSystem which reads
+// files from a given fs.FS and holds written files in memory.
+// File names must be valid paths for the fs.FS. (e.g. dir/main.tf)
+func NewOverlayFileSystem(base fs.FS) *MemoryFileSystem {
+	s := NewMemoryFileSystem(nil)
+	s.base = base
+	return s
+}
+
+// ReadFile reads a file with a given name and returns its contents.
+func (s *MemoryFileSystem) ReadFile(name string) ([]byte, error) {
+	s.mu.Lock()
+	data, ok := s.files[path.Clean(name)]
+	s.mu.Unlock()
+	if ok {
+		return append([]byte{}, data...), nil
+	}
+
+	if s.base != nil {
+		b, err := fs.ReadFile(s.base, path.Clean(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %s", err)
+		}
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("failed to read file: %s", &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist})
+}
+
+// WriteFile writes given contents to a file with a given name in memory.
+func (s *MemoryFileSystem) WriteFile(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[path.Clean(name)] = append([]byte{}, data...)
+	return nil
+}
+
+// WriteFiles writes given contents indexed by file names in memory at once.
+func (s *MemoryFileSystem) WriteFiles(files map[string][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, data := range files {
+		s.files[path.Clean(name)] = append([]byte{}, data...)
+	}
+	return nil
+}
+
+// Files returns a copy of files held in memory indexed by name.
+// For an overlay, it only contains written files.
+func (s *MemoryFileSystem) Files() map[string][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := make(map[string][]byte, len(s.files))
+	for name, data := range s.files {
+		ret[name] = append([]byte{}, data...)
+	}
+	return ret
+}
+
+// Names returns a sorted list of names of files held in memory.
+func (s *MemoryFileSystem) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := make([]string, 0, len(s.files))
+	for name := range s.files {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,204 @@
+package tfeditor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOSFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.tf")
+	if err := os.WriteFile(existing, []byte("foo"), 0600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	fs := NewOSFileSystem()
+	if err := fs.WriteFile(existing, []byte("bar")); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	got, err := fs.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	if string(got) != "bar" {
+		t.Errorf("got = %s, but want = bar", string(got))
+	}
+	fi, err := os.Stat(existing)
+	if err != nil {
+		t.Fatalf("failed to stat file: %s", err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected to preserve permissions, but got = %o", fi.Mode().Perm())
+	}
+
+	if _, err := fs.ReadFile(filepath.Join(dir, "not_found.tf")); err == nil {
+		t.Errorf("expected to return an error, but no error")
+	}
+}
+
+func TestMemoryFileSystem(t *testing.T) {
+	fs := NewMemoryFileSystem(map[string][]byte{
+		"./main.tf": []byte("foo"),
+	})
+
+	got, err := fs.ReadFile("main.tf")
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	if string(got) != "foo" {
+		t.Errorf("got = %s, but want = foo", string(got))
+	}
+
+	if err := fs.WriteFile("dir/../new.tf", []byte("bar")); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	want := map[string][]byte{
+		"main.tf": []byte("foo"),
+		"new.tf":  []byte("bar"),
+	}
+	if diff := cmp.Diff(fs.Files(), want); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", fs.Files(), want, diff)
+	}
+
+	if _, err := fs.ReadFile("not_found.tf"); err == nil {
+		t.Errorf("expected to return an error, but no error")
+	}
+}
+
+func TestOverlayFileSystem(t *testing.T) {
+	base := fstest.MapFS{
+		"dir/main.tf": {Data: []byte("foo")},
+	}
+	fs := NewOverlayFileSystem(base)
+
+	got, err := fs.ReadFile("dir/main.tf")
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	if string(got) != "foo" {
+		t.Errorf("got = %s, but want = foo", string(got))
+	}
+
+	if err := fs.WriteFile("dir/main.tf", []byte("bar")); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	got, err = fs.ReadFile("dir/main.tf")
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	if string(got) != "bar" {
+		t.Errorf("got = %s, but want = bar", string(got))
+	}
+	if string(base["dir/main.tf"].Data) != "foo" {
+		t.Errorf("expected not to modify the base, but got = %s", string(base["dir/main.tf"].Data))
+	}
+
+	want := []string{"dir/main.tf"}
+	if diff := cmp.Diff(fs.Names(), want); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", fs.Names(), want, diff)
+	}
+
+	if _, err := fs.ReadFile("not_found.tf"); err == nil {
+		t.Errorf("expected to return an error, but no error")
+	}
+}
+
+func TestOSFileSystemWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.tf")
+	if err := os.WriteFile(existing, []byte("foo"), 0600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+
+	fs := NewOSFileSystem().(BatchWriter)
+
+	// A file in a directory which does not exist cannot be written, so
+	// nothing should be written.
+	err := fs.WriteFiles(map[string][]byte{
+		existing: []byte("bar"),
+		filepath.Join(dir, "not_found", "new.tf"): []byte("baz"),
+	})
+	if err == nil {
+		t.Fatalf("expected to return an error, but no error")
+	}
+
+	got, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("failed to read file: %s", err)
+	}
+	if string(got) != "foo" {
+		t.Errorf("expected not to be updated, but got = %s", string(got))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected to remove temporary files, but got = %#v", entries)
+	}
+
+	err = fs.WriteFiles(map[string][]byte{
+		existing:                     []byte("bar"),
+		filepath.Join(dir, "new.tf"): []byte("baz"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	for name, want := range map[string]string{"existing.tf": "bar", "new.tf": "baz"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read file: %s", err)
+		}
+		if string(got) != want {
+			t.Errorf("got = %s, but want = %s", string(got), want)
+		}
+	}
+}
+
+func TestBufferedFileSystem(t *testing.T) {
+	base := NewMemoryFileSystem(map[string][]byte{
+		"main.tf": []byte("foo"),
+	})
+	fs := NewBufferedFileSystem(base)
+
+	if err := fs.WriteFiles(map[string][]byte{
+		"main.tf": []byte("bar"),
+		"new.tf":  []byte("baz"),
+	}); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	got, err := fs.ReadFile("main.tf")
+	if err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+	if string(got) != "bar" {
+		t.Errorf("got = %s, but want = bar", string(got))
+	}
+
+	before := map[string][]byte{
+		"main.tf": []byte("foo"),
+	}
+	if diff := cmp.Diff(base.Files(), before); diff != "" {
+		t.Errorf("expected not to be written before flush, but got = %#v, diff:\n%s", base.Files(), diff)
+	}
+
+	if err := fs.Flush(); err != nil {
+		t.Fatalf("unexpected err = %s", err)
+	}
+
+	want := map[string][]byte{
+		"main.tf": []byte("bar"),
+		"new.tf":  []byte("baz"),
+	}
+	if diff := cmp.Diff(base.Files(), want); diff != "" {
+		t.Errorf("got = %#v, but want = %#v, diff:\n%s", base.Files(), want, diff)
+	}
+}
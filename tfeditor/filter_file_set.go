@@ -0,0 +1,21 @@
+package tfeditor
+
+import (
+	"github.com/minamijoyo/hcledit/editor"
+)
+
+// FileSetFilter is an optional interface for editor.Filter which needs to
+// know other files in a run, such as a filter which inserts a shared block
+// only once per module. If a filter implements it, the Client calls Prepare
+// with contents of all files before filtering, and SetFilename before
+// filtering each file.
+type FileSetFilter interface {
+	editor.Filter
+
+	// Prepare is called with contents of all files indexed by name before
+	// filtering. When reading from stdin, it's not called.
+	Prepare(files map[string][]byte) error
+
+	// SetFilename is called with a name of a file before filtering it.
+	SetFilename(filename string)
+}
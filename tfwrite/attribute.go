@@ -1,13 +1,17 @@
 package tfwrite
 
 import (
+	"fmt"
 	"strings"
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/minamijoyo/hcledit/editor"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // Attribute is an attribute of resource.
@@ -69,3 +73,21 @@ func (a *Attribute) RenameReference(from string, to string) {
 	replacement := strings.Split(to, ".")
 	a.raw.Expr().RenameVariablePrefix(search, replacement)
 }
+
+// LiteralValue returns a value of Attribute if it's a literal, which can be
+// evaluated without any variables or functions.
+// It returns an error if the value cannot be known statically.
+func (a *Attribute) LiteralValue() (cty.Value, error) {
+	src := a.ValueAsTokens().Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("failed to parse expression: %s", diags)
+	}
+
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("failed to evaluate expression as a literal: %s", strings.TrimSpace(string(src)))
+	}
+
+	return v, nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestAttributeValueAsString(t *testing.T) {
@@ -49,6 +50,85 @@ foo {
 	}
 }
 
+func TestAttributeLiteralValue(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		name string
+		want cty.Value
+		ok   bool
+	}{
+		{
+			desc: "string",
+			src: `
+foo {
+  bar = "baz"
+}
+`,
+			name: "bar",
+			want: cty.StringVal("baz"),
+			ok:   true,
+		},
+		{
+			desc: "list",
+			src: `
+foo {
+  bar = ["a", 1]
+}
+`,
+			name: "bar",
+			want: cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+			ok:   true,
+		},
+		{
+			desc: "reference",
+			src: `
+foo {
+  bar = var.baz
+}
+`,
+			name: "bar",
+			want: cty.NilVal,
+			ok:   false,
+		},
+		{
+			desc: "template",
+			src: `
+foo {
+  bar = "${var.baz}-qux"
+}
+`,
+			name: "bar",
+			want: cty.NilVal,
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := findFirstTestBlock(t, f)
+			attr := b.GetAttribute(tc.name)
+			got, err := attr.LiteralValue()
+
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error, got: %#v", got)
+				}
+				return
+			}
+
+			if !got.RawEquals(tc.want) {
+				t.Errorf("got = %#v, but want = %#v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestAttributeValueAsTokens(t *testing.T) {
 	cases := []struct {
 		desc string
@@ -1,9 +1,13 @@
 package tfwrite
 
 import (
+	"fmt"
+
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/minamijoyo/hcledit/editor"
 	"github.com/zclconf/go-cty/cty"
@@ -68,6 +72,11 @@ type Block interface {
 	// the given block type or returns an empty list if not found.
 	FindNestedBlocksByType(blockType string) []Block
 
+	// FindNestedDynamicBlocksByLabel returns all dynamic blocks from the body
+	// that generate nested blocks of the given type or returns an empty list if
+	// not found.
+	FindNestedDynamicBlocksByLabel(label string) []*DynamicBlock
+
 	// VerticalFormat formats a body of the block in vertical. Since
 	// VerticalFormat clears tokens internally, If you call VerticalFormat each
 	// time RemoveNestedBlock is called, the subsequent RemoveNestedBlock will not
@@ -235,6 +244,28 @@ func (b *block) FindNestedBlocksByType(blockType string) []Block {
 	return matched
 }
 
+// FindNestedDynamicBlocksByLabel returns all dynamic blocks from the body
+// that generate nested blocks of the given type or returns an empty list if
+// not found.
+func (b *block) FindNestedDynamicBlocksByLabel(label string) []*DynamicBlock {
+	var matched []*DynamicBlock
+
+	for _, block := range b.raw.Body().Blocks() {
+		if block.Type() != "dynamic" {
+			continue
+		}
+
+		labels := block.Labels()
+		if len(labels) != 1 || labels[0] != label {
+			continue
+		}
+
+		matched = append(matched, NewDynamicBlock(block))
+	}
+
+	return matched
+}
+
 // VerticalFormat formats a body of the block in vertical.  Since
 // VerticalFormat clears tokens internally, If you call VerticalFormat each
 // time RemoveNestedBlock is called, the subsequent RemoveNestedBlock will not
@@ -295,3 +326,14 @@ func (b *block) RenameReference(from string, to string) {
 		block.RenameReference(from, to)
 	}
 }
+
+// setAttributeAddress sets an attribute for a given name to a given address
+// as a traversal, such as `aws_s3_bucket.example["foo"]` or `module.foo`.
+func setAttributeAddress(b *block, name string, address string) error {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to parse address %s: %s", address, diags)
+	}
+	b.raw.Body().SetAttributeTraversal(name, traversal)
+	return nil
+}
@@ -626,6 +626,65 @@ foo2 {
 	}
 }
 
+func TestBlockFindNestedDynamicBlocksByLabel(t *testing.T) {
+	cases := []struct {
+		desc  string
+		src   string
+		label string
+		want  []string
+		ok    bool
+	}{
+		{
+			desc: "simple",
+			src: `
+foo {
+  nested {}
+  dynamic "nested" {
+    for_each = var.foo
+    content {}
+  }
+  dynamic "other" {
+    for_each = var.bar
+    content {}
+  }
+  dynamic "nested" {
+    for_each = var.baz
+    content {}
+  }
+}
+`,
+			label: "nested",
+			want:  []string{"var.foo", "var.baz"},
+			ok:    true,
+		},
+		{
+			desc: "not found",
+			src: `
+foo {
+  nested {}
+}
+`,
+			label: "nested",
+			want:  []string{},
+			ok:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := findFirstTestBlock(t, f)
+			got := []string{}
+			for _, dynamicBlock := range b.FindNestedDynamicBlocksByLabel(tc.label) {
+				got = append(got, dynamicBlock.ForEach().References()...)
+			}
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
 func TestBlockVerticalFormat(t *testing.T) {
 	cases := []struct {
 		desc string
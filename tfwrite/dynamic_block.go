@@ -0,0 +1,78 @@
+package tfwrite
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// DynamicBlock represents a dynamic block, which generates nested blocks of
+// a type given as a label for each element of a collection.
+// It implements the Block interface.
+type DynamicBlock struct {
+	*block
+}
+
+var _ Block = (*DynamicBlock)(nil)
+
+// NewDynamicBlock creates a new instance of DynamicBlock.
+func NewDynamicBlock(block *hclwrite.Block) *DynamicBlock {
+	b := newBlock(block)
+	return &DynamicBlock{block: b}
+}
+
+// Label returns a type of nested blocks generated by the dynamic block.
+func (b *DynamicBlock) Label() string {
+	return b.SchemaType()
+}
+
+// SetLabel updates a type of nested blocks generated by the dynamic block.
+// If an iterator argument is not set, the name of the iterator is the same as
+// the label, so references to the iterator in the content block are also
+// renamed.
+func (b *DynamicBlock) SetLabel(label string) {
+	if b.GetAttribute("iterator") == nil {
+		if content := b.Content(); content != nil {
+			content.RenameReference(b.Label(), label)
+		}
+	}
+	b.raw.SetLabels([]string{label})
+}
+
+// Iterator returns a name of the iterator variable, which can be referenced
+// in the content block.
+// If an iterator argument is not set, it defaults to the label.
+func (b *DynamicBlock) Iterator() string {
+	attr := b.GetAttribute("iterator")
+	if attr == nil {
+		return b.Label()
+	}
+	refs := attr.References()
+	if len(refs) != 1 {
+		return b.Label()
+	}
+	return refs[0]
+}
+
+// ForEach returns a for_each argument.
+// It returns nil if not found.
+func (b *DynamicBlock) ForEach() *Attribute {
+	return b.GetAttribute("for_each")
+}
+
+// Content returns a content block, which is a template for each generated
+// nested block.
+// It returns nil if not found.
+func (b *DynamicBlock) Content() *NestedBlock {
+	blocks := b.FindNestedBlocksByType("content")
+	if len(blocks) == 0 {
+		return nil
+	}
+	return NewNestedBlock(blocks[0].Raw())
+}
+
+// NewEmptyDynamicBlock creates a new DynamicBlock with an empty body, which
+// generates nested blocks of a given type.
+// Note that the for_each argument and the content block are not set.
+func NewEmptyDynamicBlock(label string) *DynamicBlock {
+	block := hclwrite.NewBlock("dynamic", []string{label})
+	return NewDynamicBlock(block)
+}
@@ -0,0 +1,261 @@
+package tfwrite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestDynamicBlockSetLabel(t *testing.T) {
+	cases := []struct {
+		desc  string
+		src   string
+		label string
+		want  string
+		ok    bool
+	}{
+		{
+			desc: "default iterator",
+			src: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+    content {
+      baz = bar.value.baz
+      dynamic "qux" {
+        for_each = bar.value.qux
+        content {
+          quux = qux.value
+        }
+      }
+    }
+  }
+}
+`,
+			label: "new_bar",
+			want: `
+foo {
+  dynamic "new_bar" {
+    for_each = var.bar
+    content {
+      baz = new_bar.value.baz
+      dynamic "qux" {
+        for_each = new_bar.value.qux
+        content {
+          quux = qux.value
+        }
+      }
+    }
+  }
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "explicit iterator",
+			src: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+    iterator = it
+    content {
+      baz = it.value.baz
+    }
+  }
+}
+`,
+			label: "new_bar",
+			want: `
+foo {
+  dynamic "new_bar" {
+    for_each = var.bar
+    iterator = it
+    content {
+      baz = it.value.baz
+    }
+  }
+}
+`,
+			ok: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := findFirstTestBlock(t, f)
+			dynamicBlocks := b.FindNestedDynamicBlocksByLabel("bar")
+			dynamicBlocks[0].SetLabel(tc.label)
+			got := printTestFile(t, f)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestDynamicBlockIterator(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "default iterator",
+			src: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+    content {}
+  }
+}
+`,
+			want: "bar",
+			ok:   true,
+		},
+		{
+			desc: "explicit iterator",
+			src: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+    iterator = it
+    content {}
+  }
+}
+`,
+			want: "it",
+			ok:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := findFirstTestBlock(t, f)
+			dynamicBlocks := b.FindNestedDynamicBlocksByLabel("bar")
+			got := dynamicBlocks[0].Iterator()
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDynamicBlockContent(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "simple",
+			src: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+    content {
+      baz = bar.value
+    }
+  }
+}
+`,
+			want: `[bar.value]`,
+			ok:   true,
+		},
+		{
+			desc: "not found",
+			src: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+  }
+}
+`,
+			want: "",
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := findFirstTestBlock(t, f)
+			dynamicBlocks := b.FindNestedDynamicBlocksByLabel("bar")
+			content := dynamicBlocks[0].Content()
+
+			if tc.ok && content == nil {
+				t.Fatalf("failed to get content")
+			}
+
+			if !tc.ok {
+				if content != nil {
+					t.Fatalf("expected to return nil, but got = %#v", content)
+				}
+				return
+			}
+
+			got := content.References()
+			if diff := cmp.Diff(got, []string{"bar.value"}); diff != "" {
+				t.Fatalf("got:\n%#v\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestNewEmptyDynamicBlock(t *testing.T) {
+	cases := []struct {
+		desc  string
+		src   string
+		label string
+		want  string
+		ok    bool
+	}{
+		{
+			desc: "simple",
+			src: `
+foo {}
+`,
+			label: "bar",
+			want: `
+foo {
+  dynamic "bar" {
+    for_each = var.bar
+
+    content {
+    }
+  }
+}
+`,
+			ok: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := findFirstTestBlock(t, f)
+			dynamicBlock := NewEmptyDynamicBlock(tc.label)
+			b.AppendNestedBlock(dynamicBlock)
+			dynamicBlock.SetAttributeRaw("for_each", mustParseExpressionAsTokens(t, "var.bar"))
+			dynamicBlock.AppendNestedBlock(NewEmptyNestedBlock("content"))
+			got := printTestFile(t, f)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+// mustParseExpressionAsTokens is a test helper for parsing an expression as tokens.
+func mustParseExpressionAsTokens(t *testing.T, src string) hclwrite.Tokens {
+	t.Helper()
+	tokens, err := ParseExpressionAsTokens(src)
+	if err != nil {
+		t.Fatalf("failed to parse expression: %s", err)
+	}
+	return tokens
+}
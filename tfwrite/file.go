@@ -44,6 +44,10 @@ func parseBlock(block *hclwrite.Block) Block {
 		return NewTerraform(block)
 	case "moved":
 		return NewMoved(block)
+	case "import":
+		return NewImport(block)
+	case "removed":
+		return NewRemoved(block)
 	default:
 		return newBlock(block) // unknown
 	}
@@ -87,3 +91,80 @@ func (f *File) AppendBlock(block Block) {
 	body.AppendNewline()
 	body.AppendBlock(block.Raw())
 }
+
+// InsertBlockAfter inserts a given block after a given position block in the
+// file. If the position block is not found, it's appended to the end.
+func (f *File) InsertBlockAfter(block Block, pos Block) {
+	f.insertBlock(block, pos, true)
+}
+
+// InsertBlockBefore inserts a given block before a given position block in
+// the file. If the position block is not found, it's appended to the end.
+func (f *File) InsertBlockBefore(block Block, pos Block) {
+	f.insertBlock(block, pos, false)
+}
+
+// insertBlock inserts a given block after or before a given position block.
+// Since the hclwrite doesn't provide a way to insert a block in the middle of
+// a body, we rebuild the body with the tokens between blocks, such as blank
+// lines and detached comments, preserved as they are.
+// Top-level attributes cannot be rebuilt, but they are invalid in Terraform,
+// so the block is appended to the end in this case.
+func (f *File) insertBlock(block Block, pos Block, after bool) {
+	body := f.raw.Body()
+	if pos == nil || len(body.Attributes()) > 0 {
+		f.AppendBlock(block)
+		return
+	}
+
+	blocks := body.Blocks()
+	found := false
+	// Index blocks by their first token to find them in the tokens of the body.
+	starts := map[*hclwrite.Token]*hclwrite.Block{}
+	for _, b := range blocks {
+		if b == pos.Raw() {
+			found = true
+		}
+		if tokens := b.BuildTokens(nil); len(tokens) > 0 {
+			starts[tokens[0]] = b
+		}
+	}
+	if !found {
+		f.AppendBlock(block)
+		return
+	}
+
+	tokens := body.BuildTokens(nil)
+	for _, b := range blocks {
+		body.RemoveBlock(b)
+	}
+	body.Clear()
+
+	unstructured := hclwrite.Tokens{}
+	for i := 0; i < len(tokens); {
+		b, ok := starts[tokens[i]]
+		if !ok {
+			unstructured = append(unstructured, tokens[i])
+			i++
+			continue
+		}
+
+		if len(unstructured) > 0 {
+			body.AppendUnstructuredTokens(unstructured)
+			unstructured = hclwrite.Tokens{}
+		}
+		if b == pos.Raw() && !after {
+			body.AppendBlock(block.Raw())
+			body.AppendNewline()
+		}
+		body.AppendBlock(b)
+		if b == pos.Raw() && after {
+			body.AppendNewline()
+			body.AppendBlock(block.Raw())
+		}
+		i += len(b.BuildTokens(nil))
+	}
+	if len(unstructured) > 0 {
+		body.AppendUnstructuredTokens(unstructured)
+	}
+}
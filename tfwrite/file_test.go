@@ -201,3 +201,150 @@ resource "foo_test" "example2" {
 		})
 	}
 }
+
+func TestFileInsertBlock(t *testing.T) {
+	cases := []struct {
+		desc    string
+		src     string
+		posName string
+		after   bool
+		want    string
+		ok      bool
+	}{
+		{
+			desc: "after",
+			src: `
+resource "foo_test" "example1" {}
+
+resource "foo_test" "example2" {}
+`,
+			posName: "example1",
+			after:   true,
+			want: `
+resource "foo_test" "example1" {}
+
+resource "foo_test" "new" {
+}
+
+resource "foo_test" "example2" {}
+`,
+			ok: true,
+		},
+		{
+			desc: "after the last block",
+			src: `
+resource "foo_test" "example1" {}
+
+resource "foo_test" "example2" {}
+`,
+			posName: "example2",
+			after:   true,
+			want: `
+resource "foo_test" "example1" {}
+
+resource "foo_test" "example2" {}
+
+resource "foo_test" "new" {
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "before",
+			src: `
+resource "foo_test" "example1" {}
+
+# comment for example2
+resource "foo_test" "example2" {}
+`,
+			posName: "example2",
+			after:   false,
+			want: `
+resource "foo_test" "example1" {}
+
+resource "foo_test" "new" {
+}
+
+# comment for example2
+resource "foo_test" "example2" {}
+`,
+			ok: true,
+		},
+		{
+			desc: "detached comments",
+			src: `
+# header
+
+resource "foo_test" "example1" {}
+
+# detached
+
+resource "foo_test" "example2" {}
+# footer
+`,
+			posName: "example1",
+			after:   true,
+			want: `
+# header
+
+resource "foo_test" "example1" {}
+
+resource "foo_test" "new" {
+}
+
+# detached
+
+resource "foo_test" "example2" {}
+# footer
+`,
+			ok: true,
+		},
+		{
+			desc: "not found",
+			src: `
+resource "foo_test" "example1" {}
+`,
+			posName: "example3",
+			after:   true,
+			want: `
+resource "foo_test" "example1" {}
+
+resource "foo_test" "new" {
+}
+`,
+			ok: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			var pos Block = NewEmptyResource("foo_test", tc.posName)
+			for _, b := range f.FindBlocksByType("resource", "foo_test") {
+				if b.(*Resource).Name() == tc.posName {
+					pos = b
+				}
+			}
+
+			r := NewEmptyResource("foo_test", "new")
+			if tc.after {
+				f.InsertBlockAfter(r, pos)
+			} else {
+				f.InsertBlockBefore(r, pos)
+			}
+
+			got := printTestFile(t, f)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Fatalf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.want, diff)
+			}
+
+			// The blocks of the body should also be updated.
+			gotBlocks := f.Blocks()
+			wantBlocks := parseTestFile(t, tc.want).Blocks()
+			opts := cmpopts.IgnoreUnexported(Resource{})
+			if diff := cmp.Diff(gotBlocks, wantBlocks, opts); diff != "" {
+				t.Fatalf("got blocks:\n%v\nwant blocks:\n%v\ndiff:\n%s", gotBlocks, wantBlocks, diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package tfwrite
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 )
@@ -68,3 +72,72 @@ func SplitTokensAsList(tokens hclwrite.Tokens) []hclwrite.Tokens {
 
 	return ret
 }
+
+// ParseExpressionAsTokens parses a given source as an expression and returns
+// its tokens. It's useful for building a complex expression which cannot be
+// easily built with hclwrite, such as a function call.
+func ParseExpressionAsTokens(src string) (hclwrite.Tokens, error) {
+	f, diags := hclwrite.ParseConfig([]byte("expr = "+src+"\n"), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse expression: %s", diags)
+	}
+
+	attr := f.Body().GetAttribute("expr")
+	if attr == nil || len(f.Body().Attributes()) != 1 || len(f.Body().Blocks()) != 0 {
+		return nil, fmt.Errorf("failed to parse expression: %s", src)
+	}
+
+	return attr.Expr().BuildTokens(nil), nil
+}
+
+// ReplaceReferenceAsTokens replaces all variable references starting with a
+// given prefix in given tokens with a replacement, and returns new tokens.
+// The prefix is specified as a dot-delimited address. (e.g. `logging.value`)
+// Unlike the RenameReference, the replacement can be any expression.
+// `logging.value.target_bucket` => `one(var.logging).target_bucket`
+func ReplaceReferenceAsTokens(tokens hclwrite.Tokens, prefix string, replacement hclwrite.Tokens) hclwrite.Tokens {
+	// At time of this writing, there is no way for this in hclwrite,
+	// so this is a naive implementation.
+	search := strings.Split(prefix, ".")
+	ret := hclwrite.Tokens{}
+	for i := 0; i < len(tokens); i++ {
+		// A reference doesn't follow a dot, otherwise it's an attribute access.
+		// (e.g. `var.logging.value`)
+		if (i == 0 || tokens[i-1].Type != hclsyntax.TokenDot) && matchTraversalTokens(tokens[i:], search) {
+			r := replacement.BuildTokens(nil)
+			if len(r) > 0 {
+				// Keep the original spacing.
+				first := *r[0]
+				first.SpacesBefore = tokens[i].SpacesBefore
+				r[0] = &first
+			}
+			ret = append(ret, r...)
+			i += len(search)*2 - 2 // Skip the matched names and dots.
+			continue
+		}
+		ret = append(ret, tokens[i])
+	}
+
+	return ret
+}
+
+// matchTraversalTokens returns true if given tokens start with a traversal of
+// given names, such as `logging.value`. A longer traversal, such as
+// `logging.value.target_bucket`, is also matched.
+func matchTraversalTokens(tokens hclwrite.Tokens, names []string) bool {
+	if len(tokens) < len(names)*2-1 {
+		return false
+	}
+
+	for i, name := range names {
+		t := tokens[i*2]
+		if t.Type != hclsyntax.TokenIdent || string(t.Bytes) != name {
+			return false
+		}
+		if i < len(names)-1 && tokens[i*2+1].Type != hclsyntax.TokenDot {
+			return false
+		}
+	}
+
+	return true
+}
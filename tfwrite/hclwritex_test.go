@@ -118,3 +118,117 @@ foo {
 		})
 	}
 }
+
+func TestParseExpressionAsTokens(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "simple",
+			src:  `length(var.foo) > 0 ? 1 : 0`,
+			want: ` length(var.foo) > 0 ? 1 : 0`,
+			ok:   true,
+		},
+		{
+			desc: "syntax error",
+			src:  `length(`,
+			want: "",
+			ok:   false,
+		},
+		{
+			desc: "not an expression",
+			src:  "1\nbar = 2",
+			want: "",
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tokens, err := ParseExpressionAsTokens(tc.src)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err: %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error, got: %s", tokens.Bytes())
+				}
+				return
+			}
+
+			got := string(tokens.Bytes())
+			if got != tc.want {
+				t.Errorf("got = %q, but want = %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReplaceReferenceAsTokens(t *testing.T) {
+	cases := []struct {
+		desc        string
+		src         string
+		prefix      string
+		replacement string
+		want        string
+	}{
+		{
+			desc:        "simple",
+			src:         `foo = logging.value.target_bucket`,
+			prefix:      "logging.value",
+			replacement: `one(var.logging)`,
+			want:        `foo = one(var.logging).target_bucket`,
+		},
+		{
+			desc:        "exact match",
+			src:         `foo = logging.value`,
+			prefix:      "logging.value",
+			replacement: `one(var.logging)`,
+			want:        `foo = one(var.logging)`,
+		},
+		{
+			desc:        "in template and function",
+			src:         `foo = "${lower(logging.value.prefix)}/${logging.value.name}"`,
+			prefix:      "logging.value",
+			replacement: `one(var.logging)`,
+			want:        `foo = "${lower(one(var.logging).prefix)}/${one(var.logging).name}"`,
+		},
+		{
+			desc:        "attribute access",
+			src:         `foo = var.logging.value`,
+			prefix:      "logging.value",
+			replacement: `one(var.logging)`,
+			want:        `foo = var.logging.value`,
+		},
+		{
+			desc:        "partial match",
+			src:         `foo = logging.key`,
+			prefix:      "logging.value",
+			replacement: `one(var.logging)`,
+			want:        `foo = logging.key`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, diags := hclwrite.ParseConfig([]byte(tc.src), "", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+			replacement, err := ParseExpressionAsTokens(tc.replacement)
+			if err != nil {
+				t.Fatalf("unexpected err: %s", err)
+			}
+
+			tokens := ReplaceReferenceAsTokens(f.BuildTokens(nil), tc.prefix, replacement)
+			got := string(hclwrite.Format(tokens.Bytes()))
+			if got != tc.want {
+				t.Errorf("got = %q, but want = %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package tfwrite
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Import represents an import block.
+// It implements the Block interface.
+type Import struct {
+	*block
+}
+
+var _ Block = (*Import)(nil)
+
+// NewImport creates a new instance of Import.
+func NewImport(block *hclwrite.Block) *Import {
+	b := newBlock(block)
+	return &Import{block: b}
+}
+
+// NewEmptyImport creates a new Import with an empty body.
+func NewEmptyImport() *Import {
+	block := hclwrite.NewBlock("import", []string{})
+	return NewImport(block)
+}
+
+// SetTo sets a to argument to a given resource address.
+func (i *Import) SetTo(address string) error {
+	return setAttributeAddress(i.block, "to", address)
+}
+
+// SetID sets an id argument to a given import ID.
+func (i *Import) SetID(id string) {
+	i.SetAttributeValue("id", cty.StringVal(id))
+}
+
+// SetForEach sets a for_each argument to a given collection value.
+// It's typically a map or a tuple of import IDs indexed by instance keys.
+// Note that for_each in an import block requires Terraform v1.7+.
+func (i *Import) SetForEach(value cty.Value) {
+	i.SetAttributeValue("for_each", value)
+}
+
+// SetToEachKey sets a to argument to a given resource address indexed by
+// each.key, such as `aws_s3_bucket_acl.example[each.key]`.
+func (i *Import) SetToEachKey(address string) error {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to parse address %s: %s", address, diags)
+	}
+
+	tokens := hclwrite.TokensForTraversal(traversal)
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")})
+	tokens = append(tokens, hclwrite.TokensForTraversal(eachTraversal("key"))...)
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+	i.SetAttributeRaw("to", tokens)
+	return nil
+}
+
+// SetIDEachValue sets an id argument to each.value.
+func (i *Import) SetIDEachValue() {
+	i.raw.Body().SetAttributeTraversal("id", eachTraversal("value"))
+}
+
+// eachTraversal returns a traversal for a given attribute of the each object.
+func eachTraversal(name string) hcl.Traversal {
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: "each"},
+		hcl.TraverseAttr{Name: name},
+	}
+}
@@ -0,0 +1,179 @@
+package tfwrite
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestImportType(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "simple",
+			src: `
+import {}
+`,
+			want: "import",
+			ok:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := NewImport(findFirstTestBlock(t, f).Raw())
+
+			got := b.Type()
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportSetToID(t *testing.T) {
+	cases := []struct {
+		desc string
+		to   string
+		id   string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "simple",
+			to:   "aws_s3_bucket_acl.example",
+			id:   "tfedit-test,private",
+			want: `import {
+  to = aws_s3_bucket_acl.example
+  id = "tfedit-test,private"
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "for_each",
+			to:   `aws_s3_bucket_acl.example["foo"]`,
+			id:   "${foo}",
+			want: `import {
+  to = aws_s3_bucket_acl.example["foo"]
+  id = "$${foo}"
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "invalid address",
+			to:   `aws_s3_bucket_acl.example[`,
+			id:   "foo",
+			want: "",
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := NewEmptyFile()
+			b := NewEmptyImport()
+			err := b.SetTo(tc.to)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error")
+				}
+				return
+			}
+
+			b.SetID(tc.id)
+			f.Raw().Body().AppendBlock(b.Raw())
+			got := printTestFile(t, f)
+			if got != tc.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportSetForEach(t *testing.T) {
+	cases := []struct {
+		desc    string
+		forEach cty.Value
+		to      string
+		want    string
+		ok      bool
+	}{
+		{
+			desc: "map",
+			forEach: cty.MapVal(map[string]cty.Value{
+				"foo": cty.StringVal("tfedit-foo,private"),
+				"bar": cty.StringVal("tfedit-bar,private"),
+			}),
+			to: "aws_s3_bucket_acl.example",
+			want: `import {
+  for_each = {
+    bar = "tfedit-bar,private"
+    foo = "tfedit-foo,private"
+  }
+  to = aws_s3_bucket_acl.example[each.key]
+  id = each.value
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "tuple",
+			forEach: cty.TupleVal([]cty.Value{
+				cty.StringVal("tfedit-0,private"),
+				cty.StringVal("tfedit-1,private"),
+			}),
+			to: "module.foo.aws_s3_bucket_acl.example",
+			want: `import {
+  for_each = ["tfedit-0,private", "tfedit-1,private"]
+  to       = module.foo.aws_s3_bucket_acl.example[each.key]
+  id       = each.value
+}
+`,
+			ok: true,
+		},
+		{
+			desc:    "invalid address",
+			forEach: cty.EmptyTupleVal,
+			to:      `aws_s3_bucket_acl.example[`,
+			want:    "",
+			ok:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := NewEmptyFile()
+			b := NewEmptyImport()
+			b.SetForEach(tc.forEach)
+			err := b.SetToEachKey(tc.to)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error")
+				}
+				return
+			}
+
+			b.SetIDEachValue()
+			f.Raw().Body().AppendBlock(b.Raw())
+			got := printTestFile(t, f)
+			if got != tc.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
@@ -23,3 +23,13 @@ func NewEmptyMoved() *Moved {
 	block := hclwrite.NewBlock("moved", []string{})
 	return NewMoved(block)
 }
+
+// SetFrom sets a from argument to a given resource or module address.
+func (m *Moved) SetFrom(address string) error {
+	return setAttributeAddress(m.block, "from", address)
+}
+
+// SetTo sets a to argument to a given resource or module address.
+func (m *Moved) SetTo(address string) error {
+	return setAttributeAddress(m.block, "to", address)
+}
@@ -62,3 +62,68 @@ moved {}
 		})
 	}
 }
+
+func TestMovedSetFromTo(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		from string
+		to   string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "simple",
+			src: `
+moved {
+}
+`,
+			from: "aws_s3_bucket.foo",
+			to:   `module.bar["baz"].aws_s3_bucket.foo[0]`,
+			want: `
+moved {
+  from = aws_s3_bucket.foo
+  to   = module.bar["baz"].aws_s3_bucket.foo[0]
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "invalid address",
+			src: `
+moved {
+}
+`,
+			from: "aws_s3_bucket.foo",
+			to:   "aws_s3_bucket.",
+			want: "",
+			ok:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := NewMoved(findFirstTestBlock(t, f).Raw())
+			err := b.SetFrom(tc.from)
+			if err == nil {
+				err = b.SetTo(tc.to)
+			}
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected to return an error, but no error")
+				}
+				return
+			}
+
+			got := printTestFile(t, f)
+			if got != tc.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package tfwrite
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Removed represents a removed block.
+// It implements the Block interface.
+type Removed struct {
+	*block
+}
+
+var _ Block = (*Removed)(nil)
+
+// NewRemoved creates a new instance of Removed.
+func NewRemoved(block *hclwrite.Block) *Removed {
+	b := newBlock(block)
+	return &Removed{block: b}
+}
+
+// NewEmptyRemoved creates a new Removed with an empty body.
+func NewEmptyRemoved() *Removed {
+	block := hclwrite.NewBlock("removed", []string{})
+	return NewRemoved(block)
+}
+
+// SetFrom sets a from argument to a given resource or module address.
+func (r *Removed) SetFrom(address string) error {
+	return setAttributeAddress(r.block, "from", address)
+}
+
+// SetDestroy sets a destroy argument in a lifecycle block.
+// If false, the object is removed from state without being destroyed.
+func (r *Removed) SetDestroy(destroy bool) {
+	var lifecycle Block
+	if blocks := r.FindNestedBlocksByType("lifecycle"); len(blocks) > 0 {
+		lifecycle = blocks[0]
+	} else {
+		lifecycle = NewEmptyNestedBlock("lifecycle")
+		r.AppendNestedBlock(lifecycle)
+	}
+	lifecycle.SetAttributeValue("destroy", cty.BoolVal(destroy))
+}
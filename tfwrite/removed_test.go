@@ -0,0 +1,109 @@
+package tfwrite
+
+import (
+	"testing"
+)
+
+func TestRemovedType(t *testing.T) {
+	cases := []struct {
+		desc string
+		src  string
+		want string
+		ok   bool
+	}{
+		{
+			desc: "simple",
+			src: `
+removed {}
+`,
+			want: "removed",
+			ok:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := NewRemoved(findFirstTestBlock(t, f).Raw())
+
+			got := b.Type()
+			if got != tc.want {
+				t.Errorf("got = %s, but want = %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRemovedSetFromDestroy(t *testing.T) {
+	cases := []struct {
+		desc    string
+		src     string
+		from    string
+		destroy bool
+		want    string
+		ok      bool
+	}{
+		{
+			desc: "simple",
+			src: `
+removed {
+}
+`,
+			from:    "aws_s3_bucket.example",
+			destroy: false,
+			want: `
+removed {
+  from = aws_s3_bucket.example
+
+  lifecycle {
+    destroy = false
+  }
+}
+`,
+			ok: true,
+		},
+		{
+			desc: "existing lifecycle",
+			src: `
+removed {
+  from = module.foo
+  lifecycle {
+    destroy = true
+  }
+}
+`,
+			from:    "module.foo",
+			destroy: false,
+			want: `
+removed {
+  from = module.foo
+  lifecycle {
+    destroy = false
+  }
+}
+`,
+			ok: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := parseTestFile(t, tc.src)
+			b := NewRemoved(findFirstTestBlock(t, f).Raw())
+			err := b.SetFrom(tc.from)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err = %s", err)
+			}
+
+			if !tc.ok && err == nil {
+				t.Fatalf("expected to return an error, but no error")
+			}
+
+			b.SetDestroy(tc.destroy)
+			got := printTestFile(t, f)
+			if got != tc.want {
+				t.Fatalf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}